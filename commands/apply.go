@@ -0,0 +1,350 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+// environmentLabel is the label which marks the machines of an environment,
+// when the spec names one.
+const environmentLabel = "environment"
+
+// environmentSpec declares the machines of an environment.
+type environmentSpec struct {
+	Environment string        `json:"environment"`
+	Machines    []machineSpec `json:"machines"`
+}
+
+// machineSpec declares a machine.  Options are create flags, without the
+// leading dashes, which override the profile of the config file.
+type machineSpec struct {
+	Name    string                 `json:"name"`
+	Driver  string                 `json:"driver"`
+	Profile string                 `json:"profile"`
+	Labels  map[string]string      `json:"labels"`
+	Options map[string]interface{} `json:"options"`
+}
+
+// plannedMachine is a machine of the spec along with the options it is
+// created with.
+type plannedMachine struct {
+	Name        string
+	Driver      string
+	Options     drivers.DriverOptions
+	HostOptions *libmachine.HostOptions
+	Changes     []string
+}
+
+// environmentPlan is what needs to change for the machines to match a spec.
+type environmentPlan struct {
+	Create []plannedMachine
+	Drift  []plannedMachine
+	Extra  []string
+}
+
+func (p *environmentPlan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Drift) == 0 && len(p.Extra) == 0
+}
+
+func cmdApply(c *cli.Context) {
+	path := c.String("file")
+	if path == "" {
+		cli.ShowCommandHelp(c, "apply")
+		log.Fatal("You must specify a spec file")
+	}
+
+	spec, err := readEnvironmentSpec(path)
+	if err != nil {
+		log.Fatalf("Error reading %s: %s", path, err)
+	}
+
+	prune := c.Bool("prune")
+	if prune && spec.Environment == "" {
+		log.Fatal("Only the machines of an environment can be pruned: name one in the spec")
+	}
+
+	certInfo := getCertPathInfo(c)
+	provider := getDefaultProvider(c)
+
+	hosts, err := provider.List()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	plan, err := planEnvironment(spec, hosts, getCLIConfig(c), certInfo)
+	libmachine.CloseHosts(hosts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printEnvironmentPlan(plan, prune)
+
+	if c.Bool("plan") {
+		return
+	}
+
+	if len(plan.Create) > 0 {
+		if err := setupCertificates(
+			certInfo.CaCertPath,
+			certInfo.CaKeyPath,
+			certInfo.ClientCertPath,
+			certInfo.ClientKeyPath); err != nil {
+			log.Fatalf("Error generating certificates: %s", err)
+		}
+	}
+
+	ctx, cancel := newCommandContext(c)
+	defer cancel()
+
+	isError := false
+
+	// Machines are created one at a time, in the order of the spec, so
+	// that e.g. a swarm master can be declared before its agents.
+	for _, m := range plan.Create {
+		log.Infof("Creating %s...", m.Name)
+		host, err := provider.CreateContext(ctx, m.Name, m.Driver, m.HostOptions, m.Options)
+		if host != nil {
+			host.Close()
+		}
+		if err != nil {
+			log.Errorf("Error creating machine %s: %s", m.Name, err)
+			isError = true
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	if prune && ctx.Err() == nil {
+		for _, name := range plan.Extra {
+			if err := provider.RemoveContext(ctx, name, false); err != nil {
+				log.Errorf("Error removing machine %s: %s", name, err)
+				isError = true
+			} else {
+				log.Infof("Successfully removed %s", name)
+			}
+		}
+	}
+
+	if len(plan.Drift) > 0 {
+		log.Warn("The machines which drifted from the spec were not changed. Recreate them to apply the spec.")
+	}
+
+	if isError {
+		log.Fatal("There was an error applying the spec.")
+	}
+}
+
+// readEnvironmentSpec reads a spec from a YAML or JSON file, or from
+// stdin if path is "-".
+func readEnvironmentSpec(path string) (*environmentSpec, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &environmentSpec{}
+	if err := utils.DecodeYAML(data, spec); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i := range spec.Machines {
+		m := &spec.Machines[i]
+
+		if m.Name == "" {
+			return nil, fmt.Errorf("Machine %d has no name", i+1)
+		}
+		if seen[m.Name] {
+			return nil, fmt.Errorf("Machine %s is declared more than once", m.Name)
+		}
+		seen[m.Name] = true
+
+		for key, value := range m.Labels {
+			if _, _, err := libmachine.ParseLabel(key + "=" + value); err != nil {
+				return nil, fmt.Errorf("Machine %s: %s", m.Name, err)
+			}
+		}
+
+		if spec.Environment != "" {
+			if m.Labels == nil {
+				m.Labels = make(map[string]string)
+			}
+			if value, ok := m.Labels[environmentLabel]; ok && value != spec.Environment {
+				return nil, fmt.Errorf("Machine %s: the %s label is set by the spec", m.Name, environmentLabel)
+			}
+			m.Labels[environmentLabel] = spec.Environment
+		}
+	}
+
+	return spec, nil
+}
+
+// planEnvironment compares the machines of the spec with the existing
+// hosts.  When the spec names an environment, the hosts labelled with it
+// which are not in the spec are extra; without one, no host is.  The
+// machines which are not given a driver get the one of their profile, or
+// none.
+func planEnvironment(spec *environmentSpec, hosts []*libmachine.Host, config *cliConfig, certInfo libmachine.CertPathInfo) (*environmentPlan, error) {
+	plan := &environmentPlan{}
+
+	existing := make(map[string]*libmachine.Host)
+	for _, h := range hosts {
+		existing[h.Name] = h
+	}
+
+	declared := make(map[string]bool)
+	for _, m := range spec.Machines {
+		declared[m.Name] = true
+
+		if err := config.checkProfile(m.Profile); err != nil {
+			return nil, fmt.Errorf("Machine %s: %s", m.Name, err)
+		}
+
+		driver := m.Driver
+		if driver == "" {
+			driver = config.getDriver(m.Profile)
+		}
+		if driver == "" {
+			driver = "none"
+		}
+
+		opts, err := getCreateOptions(driver, m.Options, config.getDefaults(m.Profile, driver))
+		if err != nil {
+			return nil, fmt.Errorf("Machine %s: %s", m.Name, err)
+		}
+
+		if err := validateSwarmDiscovery(opts.String("swarm-discovery")); err != nil {
+			return nil, fmt.Errorf("Machine %s: %s", m.Name, err)
+		}
+
+		planned := plannedMachine{
+			Name:        m.Name,
+			Driver:      driver,
+			Options:     opts,
+			HostOptions: getHostOptions(opts, certInfo, m.Labels),
+		}
+
+		h, ok := existing[m.Name]
+		if !ok {
+			plan.Create = append(plan.Create, planned)
+			continue
+		}
+
+		if planned.Changes = diffHost(h, driver, planned.HostOptions); len(planned.Changes) > 0 {
+			plan.Drift = append(plan.Drift, planned)
+		}
+	}
+
+	for _, h := range hosts {
+		if declared[h.Name] || spec.Environment == "" {
+			continue
+		}
+		if h.HostOptions == nil || h.HostOptions.Labels[environmentLabel] != spec.Environment {
+			continue
+		}
+		plan.Extra = append(plan.Extra, h.Name)
+	}
+
+	return plan, nil
+}
+
+// diffHost returns how the host differs from the options it is declared
+// with, by create flag.  The options of drivers are only used to create
+// machines, so they are not compared.
+func diffHost(h *libmachine.Host, driverName string, desired *libmachine.HostOptions) []string {
+	changes := []string{}
+	check := func(option string, current, want interface{}) {
+		c, w := formatHostOption(current), formatHostOption(want)
+		if c != w {
+			changes = append(changes, fmt.Sprintf("%s: %s => %s", option, c, w))
+		}
+	}
+
+	current := &libmachine.HostOptions{}
+	if h.HostOptions != nil {
+		current = h.HostOptions
+	}
+	engineOptions, swarmOptions := *desired.EngineOptions, *desired.SwarmOptions
+	if current.EngineOptions != nil {
+		engineOptions = *current.EngineOptions
+	}
+	if current.SwarmOptions != nil {
+		swarmOptions = *current.SwarmOptions
+	}
+
+	check("driver", h.DriverName, driverName)
+	check("label", libmachine.FormatLabels(current.Labels), libmachine.FormatLabels(desired.Labels))
+
+	check("engine-opt", engineOptions.ArbitraryFlags, desired.EngineOptions.ArbitraryFlags)
+	check("engine-env", engineOptions.Env, desired.EngineOptions.Env)
+	check("engine-insecure-registry", engineOptions.InsecureRegistry, desired.EngineOptions.InsecureRegistry)
+	check("engine-label", engineOptions.Labels, desired.EngineOptions.Labels)
+	check("engine-registry-mirror", engineOptions.RegistryMirror, desired.EngineOptions.RegistryMirror)
+	check("engine-storage-driver", engineOptions.StorageDriver, desired.EngineOptions.StorageDriver)
+	check("engine-install-url", engineOptions.InstallURL, desired.EngineOptions.InstallURL)
+
+	check("swarm", swarmOptions.IsSwarm, desired.SwarmOptions.IsSwarm)
+	if desired.SwarmOptions.IsSwarm {
+		check("swarm-master", swarmOptions.Master, desired.SwarmOptions.Master)
+		check("swarm-discovery", swarmOptions.Discovery, desired.SwarmOptions.Discovery)
+		check("swarm-image", swarmOptions.Image, desired.SwarmOptions.Image)
+		check("swarm-strategy", swarmOptions.Strategy, desired.SwarmOptions.Strategy)
+		check("swarm-host", swarmOptions.Host, desired.SwarmOptions.Host)
+		check("swarm-addr", swarmOptions.Address, desired.SwarmOptions.Address)
+		check("swarm-opt", swarmOptions.ArbitraryFlags, desired.SwarmOptions.ArbitraryFlags)
+	}
+
+	return changes
+}
+
+// formatHostOption formats an option for comparison, so that e.g. unset
+// and empty lists are the same.
+func formatHostOption(v interface{}) string {
+	if b, ok := v.(bool); ok {
+		return strconv.FormatBool(b)
+	}
+	return fmt.Sprintf("%q", v)
+}
+
+func printEnvironmentPlan(plan *environmentPlan, prune bool) {
+	if plan.Empty() {
+		fmt.Println("The machines match the spec.")
+		return
+	}
+
+	for _, m := range plan.Create {
+		fmt.Printf("+ %s (%s)\n", m.Name, m.Driver)
+	}
+
+	for _, m := range plan.Drift {
+		fmt.Printf("~ %s\n", m.Name)
+		for _, change := range m.Changes {
+			fmt.Printf("    %s\n", change)
+		}
+	}
+
+	for _, name := range plan.Extra {
+		if prune {
+			fmt.Printf("- %s\n", name)
+		} else {
+			fmt.Printf("? %s is not in the spec (use --prune to remove it)\n", name)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+const testEnvironmentSpec = `
+environment: staging
+machines:
+  - name: master
+    labels:
+      role: master
+    options:
+      swarm: true
+      swarm-master: true
+      swarm-discovery: token://1234
+      engine-label: [a=1, b=2]
+  - name: agent
+`
+
+func writeTestSpec(t *testing.T, spec string) string {
+	dir, err := ioutil.TempDir("", "machine-apply-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "machines.yml")
+	if err := ioutil.WriteFile(path, []byte(spec), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestReadEnvironmentSpec(t *testing.T) {
+	path := writeTestSpec(t, testEnvironmentSpec)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	spec, err := readEnvironmentSpec(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "staging", spec.Environment)
+	assert.Len(t, spec.Machines, 2)
+	assert.Equal(t, map[string]string{"role": "master", "environment": "staging"}, spec.Machines[0].Labels)
+	assert.Equal(t, map[string]string{"environment": "staging"}, spec.Machines[1].Labels)
+}
+
+func TestReadEnvironmentSpecDuplicateName(t *testing.T) {
+	path := writeTestSpec(t, "machines:\n  - name: dev\n  - name: dev\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	_, err := readEnvironmentSpec(path)
+	assert.EqualError(t, err, "Machine dev is declared more than once")
+}
+
+func TestPlanEnvironment(t *testing.T) {
+	path := writeTestSpec(t, testEnvironmentSpec)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	spec, err := readEnvironmentSpec(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := []*libmachine.Host{
+		{
+			Name:       "master",
+			DriverName: "none",
+			HostOptions: &libmachine.HostOptions{
+				EngineOptions: &engine.EngineOptions{
+					Labels:     []string{"a=1"},
+					InstallURL: "https://get.docker.com",
+				},
+				SwarmOptions: &swarm.SwarmOptions{
+					IsSwarm:   true,
+					Master:    true,
+					Discovery: "token://1234",
+					Image:     "swarm:latest",
+					Strategy:  "spread",
+					Host:      "tcp://0.0.0.0:3376",
+				},
+				Labels: map[string]string{"role": "master", "environment": "staging"},
+			},
+		},
+		{
+			Name:        "old-agent",
+			DriverName:  "none",
+			HostOptions: &libmachine.HostOptions{Labels: map[string]string{"environment": "staging"}},
+		},
+		{
+			Name:        "unrelated",
+			DriverName:  "none",
+			HostOptions: &libmachine.HostOptions{},
+		},
+	}
+
+	plan, err := planEnvironment(spec, hosts, &cliConfig{}, libmachine.CertPathInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, plan.Create, 1)
+	assert.Equal(t, "agent", plan.Create[0].Name)
+	assert.Equal(t, "none", plan.Create[0].Driver)
+	assert.Equal(t, map[string]string{"environment": "staging"}, plan.Create[0].HostOptions.Labels)
+
+	assert.Len(t, plan.Drift, 1)
+	assert.Equal(t, "master", plan.Drift[0].Name)
+	assert.Equal(t, []string{`engine-label: ["a=1"] => ["a=1" "b=2"]`}, plan.Drift[0].Changes)
+
+	assert.Equal(t, []string{"old-agent"}, plan.Extra)
+
+	// without an environment, the machines which are not in the spec are
+	// none of its business
+	spec.Environment = ""
+	plan, err = planEnvironment(spec, hosts, &cliConfig{}, libmachine.CertPathInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, plan.Extra)
+}
+
+func TestPlanEnvironmentProfile(t *testing.T) {
+	spec := &environmentSpec{
+		Machines: []machineSpec{
+			{Name: "dev", Profile: "local", Options: map[string]interface{}{"engine-storage-driver": "aufs"}},
+		},
+	}
+	config := &cliConfig{
+		Profiles: map[string]map[string]interface{}{
+			"local": {
+				"driver":                "none",
+				"url":                   "tcp://1.2.3.4:2376",
+				"engine-storage-driver": "overlay",
+			},
+		},
+	}
+
+	plan, err := planEnvironment(spec, nil, config, libmachine.CertPathInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, plan.Create, 1)
+	assert.Equal(t, "none", plan.Create[0].Driver)
+	assert.Equal(t, "tcp://1.2.3.4:2376", plan.Create[0].Options.String("url"))
+	assert.Equal(t, "aufs", plan.Create[0].HostOptions.EngineOptions.StorageDriver)
+
+	spec.Machines[0].Profile = "prod"
+	_, err = planEnvironment(spec, nil, config, libmachine.CertPathInfo{})
+	assert.EqualError(t, err, "Machine dev: Profile prod is not in the config file")
+}
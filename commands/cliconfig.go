@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+// cliConfigFile is the name of the config file of the CLI in the storage
+// path.
+const cliConfigFile = "config.yml"
+
+// cliConfig sets default values for the create flags, by flag name.  The
+// values of a profile are used when it is selected with --profile, and the
+// values of a driver when a machine is created with it.  Profiles come
+// first, then drivers, then the defaults.  A profile, or the defaults, may
+// also choose the driver.
+type cliConfig struct {
+	Defaults map[string]interface{}            `json:"defaults"`
+	Drivers  map[string]map[string]interface{} `json:"drivers"`
+	Profiles map[string]map[string]interface{} `json:"profiles"`
+}
+
+// loadCLIConfig reads the config file at path.  A missing file is an empty
+// config.
+func loadCLIConfig(path string) (*cliConfig, error) {
+	config := &cliConfig{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, err
+	}
+
+	if err := utils.DecodeYAML(data, config); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	if len(config.Profiles) > 0 {
+		if fi, err := os.Stat(path); err == nil && fi.Mode().Perm()&0077 != 0 {
+			log.Warnf("%s holds profiles, which may have credentials, but can be read by other users. Consider running `chmod 600 %s`.", path, path)
+		}
+	}
+
+	return config, nil
+}
+
+// getCLIConfig returns the config file of the CLI, next to the machine
+// store.  The config file of a remote store is kept locally.
+func getCLIConfig(c *cli.Context) *cliConfig {
+	config, err := loadCLIConfig(getCLIConfigPath(c))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return config
+}
+
+func getCLIConfigPath(c *cli.Context) string {
+	return filepath.Join(getLocalStorePath(c), cliConfigFile)
+}
+
+// checkProfile returns an error if the profile is set, but not in the
+// config.
+func (config *cliConfig) checkProfile(profile string) error {
+	if profile == "" {
+		return nil
+	}
+	if _, ok := config.Profiles[profile]; !ok {
+		return fmt.Errorf("Profile %s is not in the config file", profile)
+	}
+	return nil
+}
+
+// getDriver returns the driver the profile, or else the defaults, choose.
+func (config *cliConfig) getDriver(profile string) string {
+	for _, values := range []map[string]interface{}{config.Profiles[profile], config.Defaults} {
+		if driver, ok := values["driver"].(string); ok && driver != "" {
+			return driver
+		}
+	}
+	return ""
+}
+
+// getDefaults returns the default values of the create flags of a machine
+// created with the driver and the profile.
+func (config *cliConfig) getDefaults(profile, driver string) map[string]interface{} {
+	defaults := make(map[string]interface{})
+	for _, values := range []map[string]interface{}{config.Defaults, config.Drivers[driver], config.Profiles[profile]} {
+		for name, value := range values {
+			defaults[name] = value
+		}
+	}
+	return defaults
+}
+
+// getSource returns where the default value of a create flag comes from,
+// or an empty string if the config does not set it.
+func (config *cliConfig) getSource(profile, driver, name string) string {
+	if _, ok := config.Profiles[profile][name]; ok {
+		return fmt.Sprintf("profile %s", profile)
+	}
+	if _, ok := config.Drivers[driver][name]; ok {
+		return fmt.Sprintf("config (%s)", driver)
+	}
+	if _, ok := config.Defaults[name]; ok {
+		return "config"
+	}
+	return ""
+}
+
+// getLocalStorePath returns the storage path, or the default one if the
+// store is remote.
+func getLocalStorePath(c *cli.Context) string {
+	rootPath := c.GlobalString("storage-path")
+	if rootPath == "" || libmachine.IsRemoteStorePath(rootPath) {
+		rootPath = utils.GetBaseDir()
+	}
+	return rootPath
+}
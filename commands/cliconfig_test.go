@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCLIConfig = `
+defaults:
+  engine-storage-driver: overlay
+  virtualbox-memory: 1024
+drivers:
+  virtualbox:
+    virtualbox-memory: 2048
+profiles:
+  prod:
+    driver: amazonec2
+    amazonec2-region: eu-west-1
+    virtualbox-memory: 4096
+`
+
+func TestLoadCLIConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-config-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, cliConfigFile)
+
+	config, err := loadCLIConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "", config.getDriver(""))
+
+	if err := ioutil.WriteFile(path, []byte(testCLIConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = loadCLIConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, config.checkProfile(""))
+	assert.NoError(t, config.checkProfile("prod"))
+	assert.EqualError(t, config.checkProfile("dev"), "Profile dev is not in the config file")
+
+	assert.Equal(t, "amazonec2", config.getDriver("prod"))
+	assert.Equal(t, "", config.getDriver(""))
+
+	assert.Equal(t, float64(1024), config.getDefaults("", "none")["virtualbox-memory"])
+	assert.Equal(t, float64(2048), config.getDefaults("", "virtualbox")["virtualbox-memory"])
+	assert.Equal(t, float64(4096), config.getDefaults("prod", "virtualbox")["virtualbox-memory"])
+	assert.Equal(t, "overlay", config.getDefaults("prod", "virtualbox")["engine-storage-driver"])
+
+	assert.Equal(t, "profile prod", config.getSource("prod", "virtualbox", "virtualbox-memory"))
+	assert.Equal(t, "config (virtualbox)", config.getSource("", "virtualbox", "virtualbox-memory"))
+	assert.Equal(t, "config", config.getSource("prod", "virtualbox", "engine-storage-driver"))
+	assert.Equal(t, "", config.getSource("prod", "virtualbox", "swarm"))
+}
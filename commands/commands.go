@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"sort"
@@ -33,7 +34,10 @@ import (
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
+	"github.com/docker/machine/state"
 	"github.com/docker/machine/utils"
+	"golang.org/x/net/context"
 )
 
 var (
@@ -46,6 +50,7 @@ type machineConfig struct {
 	machineName    string
 	machineDir     string
 	machineUrl     string
+	machineState   state.State
 	clientKeyPath  string
 	serverCertPath string
 	clientCertPath string
@@ -70,6 +75,12 @@ func sortHostListItemsByName(items []libmachine.HostListItem) {
 	}
 }
 
+// getAppName returns the name of the program, which the commands with
+// subcommands prefix to the name of their app.
+func getAppName(c *cli.Context) string {
+	return strings.Fields(c.App.Name)[0]
+}
+
 func confirmInput(msg string) bool {
 	fmt.Printf("%s (y/n): ", msg)
 	var resp string
@@ -89,7 +100,12 @@ func confirmInput(msg string) bool {
 }
 
 func newProvider(store libmachine.Store) (*libmachine.Provider, error) {
-	return libmachine.New(store)
+	provider, err := libmachine.New(store)
+	if err != nil {
+		return nil, err
+	}
+	provider.SetProgressSink(progress.LogSink)
+	return provider, nil
 }
 
 func getMachineDir(rootPath string) string {
@@ -97,11 +113,91 @@ func getMachineDir(rootPath string) string {
 }
 
 func getDefaultStore(rootPath, caCertPath, privateKeyPath string) (libmachine.Store, error) {
-	return libmachine.NewFilestore(
+	// libmachine only knows about the root it is given, so fall back to
+	// the default location here when no storage path was set
+	if rootPath == "" {
+		rootPath = utils.GetBaseDir()
+	}
+
+	if libmachine.IsRemoteStorePath(rootPath) {
+		return getRemoteStore(rootPath, caCertPath, privateKeyPath)
+	}
+
+	store := libmachine.NewFilestore(
 		rootPath,
 		caCertPath,
 		privateKeyPath,
-	), nil
+	)
+
+	secretKey, err := getSecretKey(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the key of store %s: %s", rootPath, err)
+	}
+	store.SetSecretKey(secretKey)
+
+	return store, nil
+}
+
+// getSecretKey returns the key the credentials in the store at rootPath
+// are encrypted with.  It is derived from MACHINE_STORE_PASSPHRASE if set;
+// otherwise the key file of the store is used, which is created when
+// missing.
+func getSecretKey(rootPath string) (*libmachine.SecretKey, error) {
+	secretKey, err := libmachine.LoadSecretKey(rootPath, os.Getenv("MACHINE_STORE_PASSPHRASE"))
+	if err != nil {
+		return nil, err
+	}
+
+	if secretKey == nil {
+		log.Debugf("Creating store key: %s", filepath.Join(rootPath, libmachine.SecretKeyFile))
+		return libmachine.GenerateSecretKey(rootPath)
+	}
+
+	return secretKey, nil
+}
+
+// getRemoteStore returns a store backed by the store server at storeURL.
+// The client certificate used to authenticate with the server is expected
+// next to the CA certificate, as is the case with the default layout.  The
+// key of the store has to be copied from the server as well, as it is not
+// created for a remote store.
+func getRemoteStore(storeURL, caCertPath, privateKeyPath string) (libmachine.Store, error) {
+	certDir := filepath.Dir(caCertPath)
+	tlsConfig, err := utils.NewClientTLSConfig(
+		caCertPath,
+		filepath.Join(certDir, "cert.pem"),
+		filepath.Join(certDir, "key.pem"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading TLS credentials for store %s: %s", storeURL, err)
+	}
+
+	// With a passphrase, the key is derived with the salt of the server.
+	rootPath := utils.GetBaseDir()
+	passphrase := os.Getenv("MACHINE_STORE_PASSPHRASE")
+	keyFile := libmachine.SecretKeyFile
+	if passphrase != "" {
+		keyFile = libmachine.SecretSaltFile
+	}
+	if _, err := os.Stat(filepath.Join(rootPath, keyFile)); os.IsNotExist(err) {
+		return nil, fmt.Errorf("The key of store %s was not found: copy %s from the storage path of the server to %s", storeURL, keyFile, rootPath)
+	}
+
+	secretKey, err := libmachine.LoadSecretKey(rootPath, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading the key of store %s: %s", storeURL, err)
+	}
+
+	store := libmachine.NewRemoteStore(
+		storeURL,
+		rootPath,
+		caCertPath,
+		privateKeyPath,
+		tlsConfig,
+	)
+	store.SetSecretKey(secretKey)
+
+	return store, nil
 }
 
 func setupCertificates(caCertPath, caKeyPath, clientCertPath, clientKeyPath string) error {
@@ -157,6 +253,14 @@ func setupCertificates(caCertPath, caKeyPath, clientCertPath, clientKeyPath stri
 	return nil
 }
 
+// selectorFlag selects the machines a command operates on by their labels,
+// in addition to the machines named as arguments.
+var selectorFlag = cli.StringFlag{
+	Name:  "selector, l",
+	Usage: "Select machines by label (e.g. team=web,env!=prod)",
+	Value: "",
+}
+
 var sharedCreateFlags = []cli.Flag{
 	cli.StringFlag{
 		Name: "driver, d",
@@ -201,6 +305,30 @@ var sharedCreateFlags = []cli.Flag{
 		Usage: "Specify environment variables to set in the engine",
 		Value: &cli.StringSlice{},
 	},
+	cli.StringSliceFlag{
+		Name:  "label",
+		Usage: "Set a label on the machine in the form key=value",
+		Value: &cli.StringSlice{},
+	},
+	cli.BoolFlag{
+		Name:  "keep-on-failure",
+		Usage: "Keep the machine if its creation fails instead of removing it, for debugging",
+	},
+	cli.BoolFlag{
+		Name:  "resume",
+		Usage: "Resume the creation of a machine which was kept after it failed or was interrupted",
+	},
+	cli.StringFlag{
+		EnvVar: "MACHINE_PROFILE",
+		Name:   "profile",
+		Usage:  "Use the default flags of a profile of the config file",
+		Value:  "",
+	},
+	cli.StringFlag{
+		Name:  "template",
+		Usage: "Create the machine from a template saved with `template save`; flags which are set override it",
+		Value: "",
+	},
 	cli.BoolFlag{
 		Name:  "swarm",
 		Usage: "Configure Machine with Swarm",
@@ -248,6 +376,26 @@ var Commands = []cli.Command{
 		Usage:  "Print which machine is active",
 		Action: cmdActive,
 	},
+	{
+		Name:   "apply",
+		Usage:  "Create the machines declared in a spec, and report those which differ from it",
+		Action: cmdApply,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "file, f",
+				Usage: "YAML or JSON file declaring the machines (- for stdin)",
+				Value: "",
+			},
+			cli.BoolFlag{
+				Name:  "plan",
+				Usage: "Only show the changes, without making them",
+			},
+			cli.BoolFlag{
+				Name:  "prune",
+				Usage: "Remove the machines of the environment which are not in the spec",
+			},
+		},
+	},
 	{
 		Name:        "config",
 		Usage:       "Print the connection config for machine",
@@ -259,6 +407,26 @@ var Commands = []cli.Command{
 				Usage: "Display the Swarm config instead of the Docker daemon",
 			},
 		},
+		Subcommands: []cli.Command{
+			{
+				Name:   "show-effective",
+				Usage:  "Print the create flags as set by the config file",
+				Action: cmdConfigShowEffective,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "driver, d",
+						Usage: "Driver to show the create flags of",
+						Value: "",
+					},
+					cli.StringFlag{
+						EnvVar: "MACHINE_PROFILE",
+						Name:   "profile",
+						Usage:  "Profile of the config file to show the create flags with",
+						Value:  "",
+					},
+				},
+			},
+		},
 	},
 	{
 		Flags: append(
@@ -269,6 +437,18 @@ var Commands = []cli.Command{
 		Usage:  "Create a machine",
 		Action: cmdCreate,
 	},
+	{
+		Name:        "drivers",
+		Usage:       "List the drivers, with their capabilities and flags",
+		Description: "Argument(s) are zero or more driver names.",
+		Action:      cmdDrivers,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output as JSON",
+			},
+		},
+	},
 	{
 		Name:        "env",
 		Usage:       "Display the commands to set up the environment for the Docker client",
@@ -289,6 +469,68 @@ var Commands = []cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "events",
+		Usage:  "List the events of the machines",
+		Action: cmdEvents,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "Show events since a duration ago (e.g. 2h) or a timestamp (e.g. 2015-06-01T10:00:00Z)",
+				Value: "",
+			},
+			cli.StringFlag{
+				Name:  "machine",
+				Usage: "Show the events of a machine",
+				Value: "",
+			},
+			cli.StringFlag{
+				Name:  "type",
+				Usage: "Show the events of a type (e.g. create, start, stop)",
+				Value: "",
+			},
+			cli.BoolFlag{
+				Name:  "follow, f",
+				Usage: "Keep showing new events",
+			},
+			cli.StringFlag{
+				EnvVar: "MACHINE_EVENTS_WEBHOOK",
+				Name:   "webhook",
+				Usage:  "Post each new event as JSON to a URL (with --follow)",
+				Value:  "",
+			},
+		},
+	},
+	{
+		Name:        "export",
+		Usage:       "Export a machine to a bundle",
+		Description: "Argument is a machine name.",
+		Action:      cmdExport,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "output, o",
+				Usage: "File to write the bundle to (default: <machine-name>.tar.gz)",
+				Value: "",
+			},
+			cli.BoolFlag{
+				Name:  "ca",
+				Usage: "Include the CA certificate and key in the bundle",
+			},
+		},
+	},
+	{
+		Name:        "import",
+		Usage:       "Import a machine from a bundle",
+		Description: "Argument is a bundle created with export.",
+		Action:      cmdImport,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "name",
+				Usage: "Import the machine under a new name",
+				Value: "",
+			},
+		},
+	},
 	{
 		Name:        "inspect",
 		Usage:       "Inspect information about a machine",
@@ -313,6 +555,33 @@ var Commands = []cli.Command{
 		Usage:       "Kill a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      cmdKill,
+		Flags: []cli.Flag{
+			selectorFlag,
+		},
+	},
+	{
+		Name:  "label",
+		Usage: "Manage the labels of a machine",
+		Subcommands: []cli.Command{
+			{
+				Name:        "add",
+				Usage:       "Add labels to a machine",
+				Description: "Arguments are a machine name and one or more labels in the form key=value.",
+				Action:      cmdLabelAdd,
+			},
+			{
+				Name:        "ls",
+				Usage:       "List the labels of a machine",
+				Description: "Argument is a machine name.",
+				Action:      cmdLabelLs,
+			},
+			{
+				Name:        "rm",
+				Usage:       "Remove labels from a machine",
+				Description: "Arguments are a machine name and one or more label keys.",
+				Action:      cmdLabelRm,
+			},
+		},
 	},
 	{
 		Flags: []cli.Flag{
@@ -330,6 +599,30 @@ var Commands = []cli.Command{
 		Usage:  "List machines",
 		Action: cmdLs,
 	},
+	{
+		Name:   "migrate",
+		Usage:  "Migrate the configs of the machines to the current config version",
+		Action: cmdMigrate,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show how the configs would change without migrating them",
+			},
+		},
+	},
+	{
+		Name:        "pause",
+		Usage:       "Pause a machine, keeping its memory",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      cmdPause,
+		Flags: []cli.Flag{
+			selectorFlag,
+			cli.BoolFlag{
+				Name:  "suspend, s",
+				Usage: "Save the memory of the machine to disk and stop it",
+			},
+		},
+	},
 	{
 		Name:        "regenerate-certs",
 		Usage:       "Regenerate TLS Certificates for a machine",
@@ -340,6 +633,47 @@ var Commands = []cli.Command{
 				Name:  "force, f",
 				Usage: "Force rebuild and do not prompt",
 			},
+			selectorFlag,
+		},
+	},
+	{
+		Name:        "rename",
+		Usage:       "Rename a machine",
+		Description: "Arguments are the current and the new name of the machine.",
+		Action:      cmdRename,
+	},
+	{
+		Name:        "resize",
+		Usage:       "Change the CPUs, memory, disk size or size of a machine",
+		Description: "Argument is a machine name.",
+		Action:      cmdResize,
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "cpus",
+				Usage: "Number of CPUs (-1 to use the number of CPUs available)",
+			},
+			cli.IntFlag{
+				Name:  "memory",
+				Usage: "Size of memory in MB",
+			},
+			cli.IntFlag{
+				Name:  "disk-size",
+				Usage: "Size of disk in MB; disks can only grow",
+			},
+			cli.StringFlag{
+				Name:  "size",
+				Usage: "Size, or instance type, for the providers which offer a set of sizes",
+				Value: "",
+			},
+		},
+	},
+	{
+		Name:        "resume",
+		Usage:       "Resume a paused or suspended machine",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      cmdResume,
+		Flags: []cli.Flag{
+			selectorFlag,
 		},
 	},
 	{
@@ -347,6 +681,9 @@ var Commands = []cli.Command{
 		Usage:       "Restart a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      cmdRestart,
+		Flags: []cli.Flag{
+			selectorFlag,
+		},
 	},
 	{
 		Flags: []cli.Flag{
@@ -354,12 +691,69 @@ var Commands = []cli.Command{
 				Name:  "force, f",
 				Usage: "Remove local configuration even if machine cannot be removed",
 			},
+			selectorFlag,
 		},
 		Name:        "rm",
 		Usage:       "Remove a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      cmdRm,
 	},
+	{
+		Name:        "serve",
+		Usage:       "Serve the machine API to remote clients",
+		Description: "Clients authenticate with a certificate signed by the machine CA.",
+		Action:      cmdServe,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "addr",
+				Usage: "Address to listen on",
+				Value: "0.0.0.0:8444",
+			},
+			cli.StringSliceFlag{
+				Name:  "hostname",
+				Usage: "Hostname or IP clients use to reach the server (added to the server certificate)",
+				Value: &cli.StringSlice{},
+			},
+		},
+	},
+	{
+		Name:  "store",
+		Usage: "Manage the machine store",
+		Subcommands: []cli.Command{
+			{
+				Name:        "serve",
+				Usage:       "Serve the machine store to remote clients",
+				Description: "Clients use the store by passing its URL (e.g. https://host:8443) as the storage path.",
+				Action:      cmdStoreServe,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "addr",
+						Usage: "Address to listen on",
+						Value: "0.0.0.0:8443",
+					},
+					cli.StringSliceFlag{
+						Name:  "hostname",
+						Usage: "Hostname or IP clients use to reach the store (added to the server certificate)",
+						Value: &cli.StringSlice{},
+					},
+				},
+			},
+			{
+				Name:        "rekey",
+				Usage:       "Encrypt the credentials in the store with a new key",
+				Description: "A new key file is created, unless a new passphrase is given.",
+				Action:      cmdStoreRekey,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						EnvVar: "MACHINE_STORE_NEW_PASSPHRASE",
+						Name:   "new-passphrase",
+						Usage:  "Derive the new key from a passphrase",
+						Value:  "",
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:        "ssh",
 		Usage:       "Log into or run a command on a machine with SSH.",
@@ -378,11 +772,56 @@ var Commands = []cli.Command{
 			},
 		},
 	},
+	{
+		Name:        "ssh-proxy",
+		Usage:       "Relay standard input and output to the SSH port of a machine",
+		Description: "Argument is a machine name.  Used as the ProxyCommand of ssh to reach the machines of a machine server.",
+		Action:      cmdSshProxy,
+	},
+	{
+		Name:  "snapshot",
+		Usage: "Manage the snapshots of a machine",
+		Subcommands: []cli.Command{
+			{
+				Name:        "create",
+				Usage:       "Take a snapshot of a machine",
+				Description: "Arguments are a machine name and a snapshot name.",
+				Action:      cmdSnapshotCreate,
+			},
+			{
+				Name:        "ls",
+				Usage:       "List the snapshots of a machine",
+				Description: "Argument is a machine name.",
+				Action:      cmdSnapshotLs,
+			},
+			{
+				Name:        "restore",
+				Usage:       "Restore a machine to a snapshot and start it",
+				Description: "Arguments are a machine name and a snapshot name.",
+				Action:      cmdSnapshotRestore,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "force, f",
+						Usage: "Restore without asking for confirmation",
+					},
+				},
+			},
+			{
+				Name:        "rm",
+				Usage:       "Remove snapshots of a machine",
+				Description: "Arguments are a machine name and one or more snapshot names.",
+				Action:      cmdSnapshotRm,
+			},
+		},
+	},
 	{
 		Name:        "start",
 		Usage:       "Start a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      cmdStart,
+		Flags: []cli.Flag{
+			selectorFlag,
+		},
 	},
 	{
 		Name:        "status",
@@ -395,12 +834,41 @@ var Commands = []cli.Command{
 		Usage:       "Stop a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      cmdStop,
+		Flags: []cli.Flag{
+			selectorFlag,
+		},
+	},
+	{
+		Name:  "template",
+		Usage: "Manage the templates machines are created from",
+		Subcommands: []cli.Command{
+			{
+				Name:   "ls",
+				Usage:  "List the templates",
+				Action: cmdTemplateLs,
+			},
+			{
+				Name:        "rm",
+				Usage:       "Remove templates",
+				Description: "Argument(s) are one or more template names.",
+				Action:      cmdTemplateRm,
+			},
+			{
+				Name:        "save",
+				Usage:       "Save the driver and options of a machine as a template",
+				Description: "Arguments are a machine name and a template name.",
+				Action:      cmdTemplateSave,
+			},
+		},
 	},
 	{
 		Name:        "upgrade",
 		Usage:       "Upgrade a machine to the latest version of Docker",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      cmdUpgrade,
+		Flags: []cli.Flag{
+			selectorFlag,
+		},
 	},
 	{
 		Name:        "url",
@@ -412,29 +880,63 @@ var Commands = []cli.Command{
 
 // machineCommand maps the command name to the corresponding machine command.
 // We run commands concurrently and communicate back an error if there was one.
-func machineCommand(actionName string, host *libmachine.Host, errorChan chan<- error) {
-	commands := map[string](func() error){
-		"configureAuth": host.ConfigureAuth,
-		"start":         host.Start,
-		"stop":          host.Stop,
-		"restart":       host.Restart,
-		"kill":          host.Kill,
-		"upgrade":       host.Upgrade,
-		"ip":            host.PrintIP,
+func machineCommand(ctx context.Context, provider *libmachine.Provider, actionName string, host *libmachine.Host, errorChan chan<- error) {
+	commands := map[string](func(*libmachine.Host) error){
+		"configureAuth": (*libmachine.Host).ConfigureAuth,
+		"start":         func(h *libmachine.Host) error { return h.StartContext(ctx) },
+		"stop":          func(h *libmachine.Host) error { return h.StopContext(ctx) },
+		"restart":       func(h *libmachine.Host) error { return h.RestartContext(ctx) },
+		"kill":          func(h *libmachine.Host) error { return h.KillContext(ctx) },
+		"pause":         func(h *libmachine.Host) error { return h.PauseContext(ctx) },
+		"suspend":       func(h *libmachine.Host) error { return h.SuspendContext(ctx) },
+		"resume":        func(h *libmachine.Host) error { return h.ResumeContext(ctx) },
+		"upgrade":       (*libmachine.Host).Upgrade,
+		"ip":            (*libmachine.Host).PrintIP,
 	}
 
 	log.Debugf("command=%s machine=%s", actionName, host.Name)
 
-	if err := commands[actionName](); err != nil {
-		errorChan <- err
-		return
+	// The lock is released before the result is reported, as the next
+	// action on the machine may start right away.
+	errorChan <- runLockedAction(provider, host, actionName, commands[actionName])
+}
+
+func runLockedAction(provider *libmachine.Provider, host *libmachine.Host, actionName string, action func(*libmachine.Host) error) error {
+	// Printing the IP does not change the machine, so it does not need
+	// to wait for other operations on it.
+	if actionName == "ip" {
+		return action(host)
+	}
+
+	lock, err := libmachine.LockHost(host.StorePath, actionName)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	// The machine may have been changed since it was loaded, so the
+	// action runs on its config as it is now that it is locked.
+	locked, err := provider.Get(host.Name)
+	if err != nil {
+		return err
+	}
+	defer locked.Close()
+
+	err = action(locked)
+
+	// The actions only update the host config in the local store path,
+	// so hand it back to a remote store before the machine is unlocked.
+	if _, ok := provider.Store().(*libmachine.RemoteStore); ok {
+		if err := provider.Save(locked); err != nil {
+			log.Errorf("Error saving machine %s: %s", locked.Name, err)
+		}
 	}
 
-	errorChan <- nil
+	return err
 }
 
 // runActionForeachMachine will run the command across multiple machines
-func runActionForeachMachine(actionName string, machines []*libmachine.Host) {
+func runActionForeachMachine(ctx context.Context, provider *libmachine.Provider, actionName string, machines []*libmachine.Host) {
 	var (
 		numConcurrentActions = 0
 		serialMachines       = []*libmachine.Host{}
@@ -451,7 +953,7 @@ func runActionForeachMachine(actionName string, machines []*libmachine.Host) {
 			serialMachines = append(serialMachines, machine)
 		default:
 			numConcurrentActions++
-			go machineCommand(actionName, machine, errorChan)
+			go machineCommand(ctx, provider, actionName, machine, errorChan)
 		}
 	}
 
@@ -460,7 +962,7 @@ func runActionForeachMachine(actionName string, machines []*libmachine.Host) {
 	// these run one at a time.
 	for _, machine := range serialMachines {
 		serialChan := make(chan error)
-		go machineCommand(actionName, machine, serialChan)
+		go machineCommand(ctx, provider, actionName, machine, serialChan)
 		if err := <-serialChan; err != nil {
 			log.Errorln(err)
 		}
@@ -486,17 +988,66 @@ func runActionWithContext(actionName string, c *cli.Context) error {
 	}
 
 	if len(machines) == 0 {
+		if c.String("selector") != "" {
+			log.Info("No machines match the selector")
+			return nil
+		}
 		log.Fatal(ErrNoMachineSpecified)
 	}
 
-	runActionForeachMachine(actionName, machines)
+	ctx, cancel := newCommandContext(c)
+	defer cancel()
+	defer libmachine.CloseHosts(machines)
+
+	runActionForeachMachine(ctx, getDefaultProvider(c), actionName, machines)
 
 	return nil
 }
 
+// newCommandContext returns the context for the operations of a command.
+// It is done once the global --timeout has passed, or when the user
+// presses Ctrl-C; a second Ctrl-C exits right away.
+func newCommandContext(c *cli.Context) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout := c.GlobalDuration("timeout"); timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
+	go func() {
+		select {
+		case <-interrupts:
+		case <-ctx.Done():
+			signal.Stop(interrupts)
+			return
+		}
+
+		log.Info("Cancelling... Press Ctrl-C again to exit right away.")
+		cancel()
+
+		<-interrupts
+		os.Exit(130)
+	}()
+
+	return ctx, cancel
+}
+
 func getHosts(c *cli.Context) ([]*libmachine.Host, error) {
 	machines := []*libmachine.Host{}
+	seen := make(map[string]bool)
 	for _, n := range c.Args() {
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+
 		machine, err := loadMachine(n, c)
 		if err != nil {
 			return nil, err
@@ -505,9 +1056,50 @@ func getHosts(c *cli.Context) ([]*libmachine.Host, error) {
 		machines = append(machines, machine)
 	}
 
+	selected, err := getSelectedHosts(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, machine := range selected {
+		if !seen[machine.Name] {
+			seen[machine.Name] = true
+			machines = append(machines, machine)
+		}
+	}
+
 	return machines, nil
 }
 
+// getSelectedHosts returns the machines whose labels match the --selector
+// flag, if it is set.
+func getSelectedHosts(c *cli.Context) ([]*libmachine.Host, error) {
+	selected := []*libmachine.Host{}
+
+	if c.String("selector") == "" {
+		return selected, nil
+	}
+
+	selector, err := libmachine.ParseLabelSelector(c.String("selector"))
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, err := getDefaultProvider(c).List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range hosts {
+		if selector.Matches(h) {
+			selected = append(selected, h)
+		} else {
+			h.Close()
+		}
+	}
+
+	return selected, nil
+}
+
 func loadMachine(name string, c *cli.Context) (*libmachine.Host, error) {
 	certInfo := getCertPathInfo(c)
 	defaultStore, err := getDefaultStore(
@@ -596,10 +1188,13 @@ func getMachineConfig(c *cli.Context) (*machineConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer m.Close()
+
+	machineState := offerResume(c, m)
 
-	machineDir := filepath.Join(utils.GetMachineDir(), m.Name)
+	machineDir := m.StorePath
 	caCert := filepath.Join(machineDir, "ca.pem")
-	caKey := filepath.Join(utils.GetMachineCertDir(), "ca-key.pem")
+	caKey := certInfo.CaKeyPath
 	clientCert := filepath.Join(machineDir, "cert.pem")
 	clientKey := filepath.Join(machineDir, "key.pem")
 	serverCert := filepath.Join(machineDir, "server.pem")
@@ -616,6 +1211,7 @@ func getMachineConfig(c *cli.Context) (*machineConfig, error) {
 		machineName:    name,
 		machineDir:     machineDir,
 		machineUrl:     machineUrl,
+		machineState:   machineState,
 		clientKeyPath:  clientKey,
 		clientCertPath: clientCert,
 		serverCertPath: serverCert,
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
 	"github.com/docker/machine/drivers/fakedriver"
 	_ "github.com/docker/machine/drivers/none"
 	"github.com/docker/machine/libmachine"
@@ -13,6 +16,7 @@ import (
 	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/docker/machine/state"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -83,6 +87,7 @@ func getDefaultTestHost() (*libmachine.Host, error) {
 		Address:   "",
 	}
 	authOptions := &auth.AuthOptions{
+		StorePath:      filepath.Join(hostTestStorePath, "machines", hostTestName),
 		CaCertPath:     hostTestCaCert,
 		PrivateKeyPath: hostTestPrivateKey,
 	}
@@ -124,70 +129,89 @@ func (d DriverOptionsMock) Bool(key string) bool {
 	return d.Data[key].(bool)
 }
 
+func init() {
+	drivers.Register("fakedriver", &drivers.RegisteredDriver{
+		New: func(machineName string, storePath string, caCert string, privateKey string) (drivers.Driver, error) {
+			return &fakedriver.FakeDriver{}, nil
+		},
+		GetCreateFlags: func() []cli.Flag {
+			return nil
+		},
+	})
+}
+
+// saveFakeHost saves a machine of the fake driver in the state "st" to
+// the store of the provider.
+func saveFakeHost(t *testing.T, provider *libmachine.Provider, name string, st state.State) *libmachine.Host {
+	host := &libmachine.Host{
+		Name:       name,
+		DriverName: "fakedriver",
+		Driver: &fakedriver.FakeDriver{
+			MockState: st,
+		},
+		StorePath: filepath.Join(provider.Store().GetPath(), "machines", name),
+		HostOptions: &libmachine.HostOptions{
+			EngineOptions: &engine.EngineOptions{},
+			SwarmOptions:  &swarm.SwarmOptions{},
+			AuthOptions: &auth.AuthOptions{
+				CaCertPath:     hostTestCaCert,
+				PrivateKeyPath: hostTestPrivateKey,
+			},
+		},
+	}
+
+	if err := provider.Save(host); err != nil {
+		t.Fatal(err)
+	}
+
+	return host
+}
+
+func getFakeHostState(t *testing.T, provider *libmachine.Provider, name string) state.State {
+	host, err := provider.Get(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+
+	st, err := host.Driver.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return st
+}
+
 func TestRunActionForeachMachine(t *testing.T) {
 	storePath, err := ioutil.TempDir("", ".docker")
 	if err != nil {
 		t.Fatal("Error creating tmp dir:", err)
 	}
+	defer os.RemoveAll(storePath)
+
+	provider, err := libmachine.New(libmachine.NewFilestore(storePath, hostTestCaCert, hostTestPrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Assume a bunch of machines in randomly started or
 	// stopped states.
 	machines := []*libmachine.Host{
-		{
-			Name:       "foo",
-			DriverName: "fakedriver",
-			Driver: &fakedriver.FakeDriver{
-				MockState: state.Running,
-			},
-			StorePath: storePath,
-		},
-		{
-			Name:       "bar",
-			DriverName: "fakedriver",
-			Driver: &fakedriver.FakeDriver{
-				MockState: state.Stopped,
-			},
-			StorePath: storePath,
-		},
-		{
-			Name: "baz",
-			// Ssh, don't tell anyone but this
-			// driver only _thinks_ it's named
-			// virtualbox...  (to test serial actions)
-			// It's actually FakeDriver!
-			DriverName: "virtualbox",
-			Driver: &fakedriver.FakeDriver{
-				MockState: state.Stopped,
-			},
-			StorePath: storePath,
-		},
-		{
-			Name:       "spam",
-			DriverName: "virtualbox",
-			Driver: &fakedriver.FakeDriver{
-				MockState: state.Running,
-			},
-			StorePath: storePath,
-		},
-		{
-			Name:       "eggs",
-			DriverName: "fakedriver",
-			Driver: &fakedriver.FakeDriver{
-				MockState: state.Stopped,
-			},
-			StorePath: storePath,
-		},
-		{
-			Name:       "ham",
-			DriverName: "fakedriver",
-			Driver: &fakedriver.FakeDriver{
-				MockState: state.Running,
-			},
-			StorePath: storePath,
-		},
+		saveFakeHost(t, provider, "foo", state.Running),
+		saveFakeHost(t, provider, "bar", state.Stopped),
+		saveFakeHost(t, provider, "baz", state.Stopped),
+		saveFakeHost(t, provider, "spam", state.Running),
+		saveFakeHost(t, provider, "eggs", state.Stopped),
+		saveFakeHost(t, provider, "ham", state.Running),
 	}
 
-	runActionForeachMachine("start", machines)
+	// Ssh, don't tell anyone but these machines only _think_
+	// their driver is virtualbox...  (to test serial actions)
+	// They're actually FakeDriver!
+	machines[2].DriverName = "virtualbox"
+	machines[3].DriverName = "virtualbox"
+
+	runActionForeachMachine(context.Background(), provider, "start", machines)
 
 	expected := map[string]state.State{
 		"foo":  state.Running,
@@ -199,9 +223,9 @@ func TestRunActionForeachMachine(t *testing.T) {
 	}
 
 	for _, machine := range machines {
-		state, _ := machine.Driver.GetState()
+		state := getFakeHostState(t, provider, machine.Name)
 		if expected[machine.Name] != state {
-			t.Fatalf("Expected machine %s to have state %s, got state %s", machine.Name, state, expected[machine.Name])
+			t.Fatalf("Expected machine %s to have state %s, got state %s", machine.Name, expected[machine.Name], state)
 		}
 	}
 
@@ -215,12 +239,68 @@ func TestRunActionForeachMachine(t *testing.T) {
 		"ham":  state.Stopped,
 	}
 
-	runActionForeachMachine("stop", machines)
+	runActionForeachMachine(context.Background(), provider, "stop", machines)
 
 	for _, machine := range machines {
-		state, _ := machine.Driver.GetState()
+		state := getFakeHostState(t, provider, machine.Name)
 		if expected[machine.Name] != state {
-			t.Fatalf("Expected machine %s to have state %s, got state %s", machine.Name, state, expected[machine.Name])
+			t.Fatalf("Expected machine %s to have state %s, got state %s", machine.Name, expected[machine.Name], state)
 		}
 	}
 }
+
+func TestRunLockedActionReloadsHost(t *testing.T) {
+	storePath, err := ioutil.TempDir("", ".docker")
+	if err != nil {
+		t.Fatal("Error creating tmp dir:", err)
+	}
+	defer os.RemoveAll(storePath)
+
+	provider, err := libmachine.New(libmachine.NewFilestore(storePath, hostTestCaCert, hostTestPrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := saveFakeHost(t, provider, "foo", state.Stopped)
+
+	// A second writer changes the config of the machine while the first
+	// one still has to take the lock.
+	lock, err := libmachine.LockHost(stale.StorePath, "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := provider.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.HostOptions.Labels = map[string]string{"env": "test"}
+	if err := provider.Save(other); err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+
+	if _, ok := runLockedAction(provider, stale, "start", (*libmachine.Host).Start).(libmachine.ErrHostLocked); !ok {
+		t.Fatal("Expected the action to fail while the machine is locked")
+	}
+
+	lock.Unlock()
+
+	if err := runLockedAction(provider, stale, "start", (*libmachine.Host).Start); err != nil {
+		t.Fatal(err)
+	}
+
+	host, err := provider.Get("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+
+	if host.HostOptions.Labels["env"] != "test" {
+		t.Fatalf("Expected the label of the second writer to be kept, got labels %v", host.HostOptions.Labels)
+	}
+
+	if st, _ := host.Driver.GetState(); st != state.Running {
+		t.Fatalf("Expected machine to have state %s, got state %s", state.Running, st)
+	}
+}
@@ -3,9 +3,13 @@ package commands
 import (
 	"fmt"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
 	"github.com/docker/machine/log"
 	"github.com/docker/machine/utils"
 )
@@ -19,6 +23,10 @@ func cmdConfig(c *cli.Context) {
 		log.Fatal(err)
 	}
 
+	if cfg.machineUrl == "" {
+		log.Fatal(notRunningHint(getAppName(c), cfg.machineName, cfg.machineState))
+	}
+
 	dockerHost, err := getHost(c).Driver.GetURL()
 	if err != nil {
 		log.Fatal(err)
@@ -77,3 +85,76 @@ func cmdConfig(c *cli.Context) {
 	fmt.Printf("--tlsverify --tlscacert=%q --tlscert=%q --tlskey=%q -H=%s",
 		cfg.caCertPath, cfg.clientCertPath, cfg.clientKeyPath, dockerHost)
 }
+
+// cmdConfigShowEffective prints the values the create flags take with the
+// config file, unless they are set explicitly, and where they come from.
+func cmdConfigShowEffective(c *cli.Context) {
+	config := getCLIConfig(c)
+
+	profile := c.String("profile")
+	if err := config.checkProfile(profile); err != nil {
+		log.Fatal(err)
+	}
+
+	driver, driverSource := c.String("driver"), "flag"
+	if driver == "" {
+		driver, driverSource = config.getDriver(profile), config.getSource(profile, "", "driver")
+	}
+	if driver == "" {
+		driver, driverSource = "none", "default"
+	}
+
+	opts, err := getCreateOptions(driver, nil, config.getDefaults(profile, driver))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	driverFlags, err := drivers.GetCreateFlagsForDriver(driver)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	secretFlags, err := drivers.GetSecretCreateFlags(driver)
+	if err != nil {
+		log.Debugf("Error finding the credentials of driver %s: %s", driver, err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "FLAG\tVALUE\tSOURCE")
+	fmt.Fprintf(w, "driver\t%s\t%s\n", driver, driverSource)
+
+	for _, f := range append(driverFlags, sharedCreateFlags...) {
+		name := drivers.FlagName(f)
+		if reservedCreateOptions[name] {
+			continue
+		}
+
+		var value string
+		switch f.(type) {
+		case cli.StringSliceFlag:
+			value = strings.Join(opts.StringSlice(name), ",")
+		case cli.BoolFlag, cli.BoolTFlag:
+			value = strconv.FormatBool(opts.Bool(name))
+		case cli.IntFlag:
+			value = strconv.Itoa(opts.Int(name))
+		default:
+			value = opts.String(name)
+		}
+
+		if secretFlags[name] {
+			log.RegisterSecret(value)
+		}
+
+		source := config.getSource(profile, driver, name)
+		switch {
+		case drivers.FlagEnvVarSet(f):
+			source = "env"
+		case source == "":
+			source = "default"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, log.Redact(value), source)
+	}
+
+	w.Flush()
+}
@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"flag"
 	"fmt"
-	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/docker/machine/log"
 
@@ -13,16 +15,48 @@ import (
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/swarm"
-	"github.com/docker/machine/utils"
+	"golang.org/x/net/context"
 )
 
 func cmdCreate(c *cli.Context) {
 	var (
 		err error
 	)
-	driver := c.String("driver")
 	name := c.Args().First()
 
+	if api := getAPIClient(c); api != nil {
+		if name == "" {
+			cli.ShowCommandHelp(c, "create")
+			log.Fatal("You must specify a machine name")
+		}
+		cmdCreateRemote(c, api, name)
+		return
+	}
+
+	config := getCLIConfig(c)
+	profile := c.String("profile")
+	if err := config.checkProfile(profile); err != nil {
+		log.Fatal(err)
+	}
+
+	var t *libmachine.Template
+	if templateName := c.String("template"); templateName != "" {
+		t, err = getTemplateStore(c).Get(templateName)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	driver := c.String("driver")
+	if !c.IsSet("driver") && !c.IsSet("d") {
+		switch {
+		case t != nil:
+			driver = t.DriverName
+		case config.getDriver(profile) != "":
+			driver = config.getDriver(profile)
+		}
+	}
+
 	// TODO: Not really a fan of "none" as the default driver...
 	if driver != "none" {
 		c.App.Commands, err = trimDriverFlags(driver, c.App.Commands)
@@ -36,7 +70,24 @@ func cmdCreate(c *cli.Context) {
 		log.Fatal("You must specify a machine name")
 	}
 
-	if err := validateSwarmDiscovery(c.String("swarm-discovery")); err != nil {
+	labels, err := libmachine.ParseLabels(c.StringSlice("label"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var values map[string]interface{}
+	if t != nil {
+		values = getTemplateValues(t, driver)
+		labels = getTemplateLabels(t, labels)
+	}
+
+	fallback, err := getCreateOptions(driver, values, config.getDefaults(profile, driver))
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := createOptions{c: c, fallback: fallback}
+
+	if err := validateSwarmDiscovery(opts.String("swarm-discovery")); err != nil {
 		log.Fatalf("Error parsing swarm discovery: %s", err)
 	}
 
@@ -64,41 +115,212 @@ func cmdCreate(c *cli.Context) {
 		log.Fatal(err)
 	}
 
-	hostOptions := &libmachine.HostOptions{
+	ctx, cancel := newCommandContext(c)
+	defer cancel()
+
+	if c.Bool("resume") {
+		resumeCreate(ctx, c, provider, name)
+		return
+	}
+
+	hostOptions := getHostOptions(opts, certInfo, labels)
+
+	provider.SetKeepOnFailure(c.Bool("keep-on-failure"))
+
+	_, err = provider.CreateContext(ctx, name, driver, hostOptions, opts)
+	if err != nil {
+		log.Errorf("Error creating machine: %s", err)
+
+		createErr, ok := err.(libmachine.ErrCreateFailed)
+		switch {
+		case !ok:
+		case createErr.Kept:
+			log.Fatalf("The machine was kept for debugging. Run `%s create --resume %s` to resume its creation, or `%s rm %s` to remove it.", c.App.Name, name, c.App.Name, name)
+		case createErr.RollbackErr == nil:
+			log.Fatal("The machine was removed.")
+		}
+		log.Fatal("You will want to check the provider to make sure the machine and associated resources were properly removed.")
+	}
+
+	info := fmt.Sprintf("%s env %s", c.App.Name, name)
+	log.Infof("To see how to connect Docker to this machine, run: %s", info)
+}
+
+// reservedCreateOptions are the create flags which choose how a machine is
+// created, rather than what it is like, so they can not be given values.
+var reservedCreateOptions = map[string]bool{
+	"driver":          true,
+	"label":           true,
+	"keep-on-failure": true,
+	"resume":          true,
+	"template":        true,
+	"profile":         true,
+}
+
+// createOptions are the create flags of a machine: the flags which were
+// set explicitly, or else the fallback.
+type createOptions struct {
+	c        *cli.Context
+	fallback drivers.DriverOptions
+}
+
+func (o createOptions) String(key string) string {
+	if o.c.IsSet(key) {
+		return o.c.String(key)
+	}
+	return o.fallback.String(key)
+}
+
+func (o createOptions) StringSlice(key string) []string {
+	if o.c.IsSet(key) {
+		return o.c.StringSlice(key)
+	}
+	return o.fallback.StringSlice(key)
+}
+
+func (o createOptions) Int(key string) int {
+	if o.c.IsSet(key) {
+		return o.c.Int(key)
+	}
+	return o.fallback.Int(key)
+}
+
+func (o createOptions) Bool(key string) bool {
+	if o.c.IsSet(key) {
+		return o.c.Bool(key)
+	}
+	return o.fallback.Bool(key)
+}
+
+// getCreateOptions returns the create flags of a machine created with the
+// driver, as they are when not set explicitly.  A flag takes its value from
+// values, or else from its environment variable, defaults, or its default
+// value, in that order.  Defaults for flags the driver does not have are
+// ignored, unlike values.
+func getCreateOptions(driver string, values, defaults map[string]interface{}) (drivers.DriverOptions, error) {
+	driverFlags, err := drivers.GetCreateFlagsForDriver(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	set := flag.NewFlagSet(driver, flag.ContinueOnError)
+	flags := make(map[string]cli.Flag)
+	for _, f := range append(driverFlags, sharedCreateFlags...) {
+		flags[drivers.FlagName(f)] = f
+
+		// The default value of a slice flag is shared, and setting the
+		// flag would change it.
+		if sf, ok := f.(cli.StringSliceFlag); ok {
+			value := cli.StringSlice{}
+			if sf.Value != nil {
+				value = append(value, *sf.Value...)
+			}
+			sf.Value = &value
+			f = sf
+		}
+		f.Apply(set)
+	}
+
+	for name, value := range defaults {
+		f, ok := flags[name]
+		if !ok || reservedCreateOptions[name] || drivers.FlagEnvVarSet(f) {
+			continue
+		}
+		if err := setCreateOption(set, name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, value := range values {
+		if _, ok := flags[name]; !ok || reservedCreateOptions[name] {
+			return nil, fmt.Errorf("Unknown option %q for driver %s", name, driver)
+		}
+		if err := setCreateOption(set, name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return cli.NewContext(nil, set, nil), nil
+}
+
+// setCreateOption sets a flag to a value, or the values of a slice flag to
+// a list.
+func setCreateOption(set *flag.FlagSet, name string, value interface{}) error {
+	fl := set.Lookup(name)
+
+	values := []interface{}{value}
+	if slice, ok := fl.Value.(*cli.StringSlice); ok {
+		*slice = cli.StringSlice{}
+		if list, ok := value.([]interface{}); ok {
+			values = list
+		}
+	} else if _, ok := value.([]interface{}); ok {
+		return fmt.Errorf("Option %q takes a single value", name)
+	}
+
+	for _, v := range values {
+		if err := set.Set(name, formatOptionValue(v)); err != nil {
+			return fmt.Errorf("Invalid value for option %q: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+func formatOptionValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// getHostOptions returns the options of a machine created with the shared
+// create flags in opts.
+func getHostOptions(opts drivers.DriverOptions, certInfo libmachine.CertPathInfo, labels map[string]string) *libmachine.HostOptions {
+	return &libmachine.HostOptions{
 		AuthOptions: &auth.AuthOptions{
 			CaCertPath:     certInfo.CaCertPath,
 			PrivateKeyPath: certInfo.CaKeyPath,
 			ClientCertPath: certInfo.ClientCertPath,
 			ClientKeyPath:  certInfo.ClientKeyPath,
-			ServerCertPath: filepath.Join(utils.GetMachineDir(), name, "server.pem"),
-			ServerKeyPath:  filepath.Join(utils.GetMachineDir(), name, "server-key.pem"),
 		},
 		EngineOptions: &engine.EngineOptions{
-			ArbitraryFlags:   c.StringSlice("engine-opt"),
-			Env:              c.StringSlice("engine-env"),
-			InsecureRegistry: c.StringSlice("engine-insecure-registry"),
-			Labels:           c.StringSlice("engine-label"),
-			RegistryMirror:   c.StringSlice("engine-registry-mirror"),
-			StorageDriver:    c.String("engine-storage-driver"),
+			ArbitraryFlags:   opts.StringSlice("engine-opt"),
+			Env:              opts.StringSlice("engine-env"),
+			InsecureRegistry: opts.StringSlice("engine-insecure-registry"),
+			Labels:           opts.StringSlice("engine-label"),
+			RegistryMirror:   opts.StringSlice("engine-registry-mirror"),
+			StorageDriver:    opts.String("engine-storage-driver"),
 			TlsVerify:        true,
-			InstallURL:       c.String("engine-install-url"),
+			InstallURL:       opts.String("engine-install-url"),
 		},
 		SwarmOptions: &swarm.SwarmOptions{
-			IsSwarm:        c.Bool("swarm"),
-			Image:          c.String("swarm-image"),
-			Master:         c.Bool("swarm-master"),
-			Discovery:      c.String("swarm-discovery"),
-			Address:        c.String("swarm-addr"),
-			Host:           c.String("swarm-host"),
-			Strategy:       c.String("swarm-strategy"),
-			ArbitraryFlags: c.StringSlice("swarm-opt"),
+			IsSwarm:        opts.Bool("swarm"),
+			Image:          opts.String("swarm-image"),
+			Master:         opts.Bool("swarm-master"),
+			Discovery:      opts.String("swarm-discovery"),
+			Address:        opts.String("swarm-addr"),
+			Host:           opts.String("swarm-host"),
+			Strategy:       opts.String("swarm-strategy"),
+			ArbitraryFlags: opts.StringSlice("swarm-opt"),
 		},
+		Labels: labels,
 	}
+}
 
-	_, err = provider.Create(name, driver, hostOptions, c)
+// resumeCreate continues the creation of a machine which was kept after
+// it failed, or was interrupted.
+func resumeCreate(ctx context.Context, c *cli.Context, provider *libmachine.Provider, name string) {
+	host, err := provider.ResumeCreateContext(ctx, name)
 	if err != nil {
-		log.Errorf("Error creating machine: %s", err)
-		log.Fatal("You will want to check the provider to make sure the machine and associated resources were properly removed.")
+		if host == nil || host.CreateState == nil {
+			log.Fatalf("Error resuming the creation of machine: %s", err)
+		}
+		log.Errorf("Error resuming the creation of machine: %s", err)
+		log.Fatalf("The machine was kept. Run `%s create --resume %s` to try again, or `%s rm %s` to remove it.", c.App.Name, name, c.App.Name, name)
 	}
 
 	info := fmt.Sprintf("%s env %s", c.App.Name, name)
@@ -124,6 +346,58 @@ func trimDriverFlags(driver string, cmds []cli.Command) ([]cli.Command, error) {
 	return filteredCmds, nil
 }
 
+// AddPluginCreateFlags makes the create flags of a driver plugin known to
+// the create command.  Unlike the compiled in drivers, the flags of a plugin
+// can only be discovered at runtime, so this needs to run before the create
+// command parses its arguments.
+func AddPluginCreateFlags(c *cli.Context) error {
+	args := c.Args()
+	if !args.Present() || args.First() != "create" {
+		return nil
+	}
+
+	driverName := getDriverNameFromArgs(args.Tail())
+	if driverName == "" {
+		return nil
+	}
+
+	for _, name := range drivers.GetDriverNames() {
+		if name == driverName {
+			return nil
+		}
+	}
+
+	pluginFlags, err := drivers.GetCreateFlagsForDriver(driverName)
+	if err != nil {
+		// let cmdCreate report the unknown driver
+		return nil
+	}
+
+	for i, cmd := range c.App.Commands {
+		if cmd.HasName("create") {
+			c.App.Commands[i].Flags = append(pluginFlags, cmd.Flags...)
+		}
+	}
+
+	return nil
+}
+
+// getDriverNameFromArgs returns the value of the --driver flag in the
+// unparsed arguments of the create command.
+func getDriverNameFromArgs(args []string) string {
+	for i, arg := range args {
+		for _, flag := range []string{"-d", "-driver", "--driver"} {
+			if arg == flag && i+1 < len(args) {
+				return args[i+1]
+			}
+			if strings.HasPrefix(arg, flag+"=") {
+				return strings.TrimPrefix(arg, flag+"=")
+			}
+		}
+	}
+	return ""
+}
+
 func validateSwarmDiscovery(discovery string) error {
 	if discovery == "" {
 		return nil
@@ -1,8 +1,12 @@
 package commands
 
 import (
+	"flag"
+	"os"
 	"testing"
 
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,3 +24,76 @@ func TestValidateSwarmDiscoveryAcceptsValidFormat(t *testing.T) {
 	err := validateSwarmDiscovery("token://deadbeefcafe")
 	assert.NoError(t, err)
 }
+
+func TestGetDriverNameFromArgs(t *testing.T) {
+	assert.Equal(t, "virtualbox", getDriverNameFromArgs([]string{"-d", "virtualbox", "dev"}))
+	assert.Equal(t, "virtualbox", getDriverNameFromArgs([]string{"--driver", "virtualbox", "dev"}))
+	assert.Equal(t, "myhypervisor", getDriverNameFromArgs([]string{"--driver=myhypervisor", "dev"}))
+	assert.Equal(t, "", getDriverNameFromArgs([]string{"dev"}))
+}
+
+func TestGetCreateOptions(t *testing.T) {
+	os.Setenv("MACHINE_SWARM_IMAGE", "swarm:env")
+	defer os.Unsetenv("MACHINE_SWARM_IMAGE")
+
+	values := map[string]interface{}{
+		"url":        "tcp://1.2.3.4:2376",
+		"engine-env": []interface{}{"A=1", "B=2"},
+	}
+	defaults := map[string]interface{}{
+		"url":                   "tcp://5.6.7.8:2376",
+		"engine-storage-driver": "overlay",
+		"swarm-image":           "swarm:config",
+		"swarm":                 true,
+		"virtualbox-memory":     2048,
+	}
+
+	opts, err := getCreateOptions("none", values, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "tcp://1.2.3.4:2376", opts.String("url"))
+	assert.Equal(t, []string{"A=1", "B=2"}, opts.StringSlice("engine-env"))
+	assert.Equal(t, "overlay", opts.String("engine-storage-driver"))
+	assert.Equal(t, "swarm:env", opts.String("swarm-image"))
+	assert.True(t, opts.Bool("swarm"))
+	assert.Equal(t, "spread", opts.String("swarm-strategy"))
+	assert.Equal(t, []string{}, opts.StringSlice("engine-opt"))
+}
+
+func TestGetCreateOptionsUnknown(t *testing.T) {
+	_, err := getCreateOptions("none", map[string]interface{}{"virtualbox-memory": 2048}, nil)
+	assert.EqualError(t, err, `Unknown option "virtualbox-memory" for driver none`)
+
+	_, err = getCreateOptions("none", map[string]interface{}{"driver": "virtualbox"}, nil)
+	assert.EqualError(t, err, `Unknown option "driver" for driver none`)
+}
+
+func TestCreateOptionsExplicitFlags(t *testing.T) {
+	driverFlags, err := drivers.GetCreateFlagsForDriver("none")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := flag.NewFlagSet("create", flag.ContinueOnError)
+	for _, f := range append(driverFlags, sharedCreateFlags...) {
+		f.Apply(set)
+	}
+	if err := set.Parse([]string{"--engine-storage-driver", "overlay", "dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fallback, err := getCreateOptions("none", map[string]interface{}{
+		"engine-storage-driver": "aufs",
+		"url":                   "tcp://1.2.3.4:2376",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := createOptions{c: cli.NewContext(nil, set, nil), fallback: fallback}
+
+	assert.Equal(t, "overlay", opts.String("engine-storage-driver"))
+	assert.Equal(t, "tcp://1.2.3.4:2376", opts.String("url"))
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/log"
+)
+
+type driverInfo struct {
+	Name         string
+	Description  string
+	Capabilities []string
+	Flags        []driverFlagInfo
+}
+
+type driverFlagInfo struct {
+	Name     string
+	Type     string
+	Default  interface{}
+	EnvVar   string
+	Required bool
+	Usage    string
+}
+
+func cmdDrivers(c *cli.Context) {
+	names := []string(c.Args())
+	if len(names) == 0 {
+		names = drivers.GetDriverNames()
+	}
+
+	infos := []driverInfo{}
+	for _, name := range names {
+		info, err := getDriverInfo(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		infos = append(infos, info)
+	}
+
+	if c.Bool("json") {
+		data, err := json.MarshalIndent(infos, "", "    ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	printDriverInfos(os.Stdout, infos)
+}
+
+func getDriverInfo(name string) (driverInfo, error) {
+	info := driverInfo{Name: name}
+
+	flags, err := drivers.GetCreateFlagsForDriver(name)
+	if err != nil {
+		return info, err
+	}
+
+	if info.Description, err = drivers.GetDriverDescription(name); err != nil {
+		return info, err
+	}
+
+	if info.Capabilities, err = drivers.GetCapabilitiesForDriver(name); err != nil {
+		return info, err
+	}
+
+	requiredFlags, err := drivers.GetRequiredFlagsForDriver(name)
+	if err != nil {
+		return info, err
+	}
+	required := make(map[string]bool)
+	for _, flagName := range requiredFlags {
+		required[flagName] = true
+	}
+
+	info.Flags = []driverFlagInfo{}
+	for _, f := range flags {
+		flagInfo := getDriverFlagInfo(f)
+		flagInfo.Required = required[flagInfo.Name]
+		info.Flags = append(info.Flags, flagInfo)
+	}
+
+	return info, nil
+}
+
+func getDriverFlagInfo(f cli.Flag) driverFlagInfo {
+	info := driverFlagInfo{
+		Name:   drivers.FlagName(f),
+		EnvVar: drivers.FlagEnvVar(f),
+	}
+
+	switch f := f.(type) {
+	case cli.StringFlag:
+		info.Type, info.Default, info.Usage = "string", f.Value, f.Usage
+	case cli.StringSliceFlag:
+		values := []string{}
+		if f.Value != nil {
+			values = append(values, f.Value.Value()...)
+		}
+		info.Type, info.Default, info.Usage = "list", values, f.Usage
+	case cli.IntFlag:
+		info.Type, info.Default, info.Usage = "int", f.Value, f.Usage
+	case cli.BoolFlag:
+		info.Type, info.Default, info.Usage = "bool", false, f.Usage
+	case cli.BoolTFlag:
+		info.Type, info.Default, info.Usage = "bool", true, f.Usage
+	case cli.DurationFlag:
+		info.Type, info.Default, info.Usage = "duration", f.Value.String(), f.Usage
+	case cli.Float64Flag:
+		info.Type, info.Default, info.Usage = "float", f.Value, f.Usage
+	}
+
+	return info
+}
+
+func printDriverInfos(out io.Writer, infos []driverInfo) {
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+
+		fmt.Fprintf(out, "%s: %s\n", info.Name, info.Description)
+
+		capabilities := "none"
+		if len(info.Capabilities) > 0 {
+			capabilities = strings.Join(info.Capabilities, ", ")
+		}
+		fmt.Fprintf(out, "Capabilities: %s\n", capabilities)
+
+		if len(info.Flags) == 0 {
+			continue
+		}
+
+		w := tabwriter.NewWriter(out, 5, 1, 3, ' ', 0)
+		fmt.Fprintln(w, "FLAG\tTYPE\tDEFAULT\tENV\tREQUIRED")
+		for _, f := range info.Flags {
+			required := ""
+			if f.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", f.Name, f.Type, formatFlagDefault(f.Default), f.EnvVar, required)
+		}
+		w.Flush()
+	}
+}
+
+func formatFlagDefault(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, ",")
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
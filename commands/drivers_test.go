@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDriverInfo(t *testing.T) {
+	info, err := getDriverInfo("none")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "none", info.Name)
+	assert.NotEmpty(t, info.Description)
+	assert.Equal(t, []string{}, info.Capabilities)
+	assert.Equal(t, []driverFlagInfo{
+		{
+			Name:     "url",
+			Type:     "string",
+			Default:  "",
+			Required: true,
+			Usage:    "URL of host when no driver is selected",
+		},
+	}, info.Flags)
+}
+
+func TestGetDriverInfoCapabilities(t *testing.T) {
+	info, err := getDriverInfo("virtualbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, info.Capabilities, "rename")
+}
+
+func TestGetDriverInfoUnknown(t *testing.T) {
+	if _, err := getDriverInfo("unknown-driver"); err == nil {
+		t.Fatal("expected an error for an unknown driver")
+	}
+}
+
+func TestGetDriverFlagInfo(t *testing.T) {
+	info := getDriverFlagInfo(cli.IntFlag{
+		Name:   "foo-memory, m",
+		EnvVar: "FOO_MEMORY",
+		Value:  1024,
+	})
+	assert.Equal(t, driverFlagInfo{Name: "foo-memory", Type: "int", Default: 1024, EnvVar: "FOO_MEMORY"}, info)
+
+	info = getDriverFlagInfo(cli.StringSliceFlag{
+		Name:  "foo-tag",
+		Value: &cli.StringSlice{"a", "b"},
+	})
+	assert.Equal(t, "list", info.Type)
+	assert.Equal(t, []string{"a", "b"}, info.Default)
+
+	info = getDriverFlagInfo(cli.BoolTFlag{Name: "foo-enable"})
+	assert.Equal(t, true, info.Default)
+}
+
+func TestPrintDriverInfos(t *testing.T) {
+	out := &bytes.Buffer{}
+	printDriverInfos(out, []driverInfo{
+		{
+			Name:        "foo",
+			Description: "Foo machines",
+			Flags: []driverFlagInfo{
+				{Name: "foo-tag", Type: "list", Default: []string{"a", "b"}, EnvVar: "FOO_TAG", Required: true},
+			},
+		},
+		{
+			Name:         "bar",
+			Description:  "Bar machines",
+			Capabilities: []string{"pause", "snapshot"},
+		},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "foo: Foo machines", lines[0])
+	assert.Equal(t, "Capabilities: none", lines[1])
+	assert.Equal(t, []string{"FLAG", "TYPE", "DEFAULT", "ENV", "REQUIRED"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"foo-tag", "list", "a,b", "FOO_TAG", "yes"}, strings.Fields(lines[3]))
+	assert.Equal(t, "", lines[4])
+	assert.Equal(t, "bar: Bar machines", lines[5])
+	assert.Equal(t, "Capabilities: pause, snapshot", lines[6])
+}
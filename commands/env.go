@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"net/url"
 	"os"
-	"strings"
 	"text/template"
 
 	"github.com/docker/machine/log"
 
 	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine/swarm"
 	"github.com/docker/machine/utils"
 )
 
@@ -93,13 +93,30 @@ func cmdEnv(c *cli.Context) {
 		return
 	}
 
+	if api := getAPIClient(c); api != nil {
+		name := c.Args().First()
+		dockerHost, certPath, err := getRemoteEnv(api, name, c.Bool("swarm"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printEnv(userShell, ShellConfig{
+			DockerCertPath:  certPath,
+			DockerHost:      dockerHost,
+			DockerTLSVerify: "1",
+			UsageHint:       generateUsageHint(c.App.Name+" --host "+c.GlobalString("host"), name, userShell),
+			MachineName:     name,
+		})
+		return
+	}
+
 	cfg, err := getMachineConfig(c)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	if cfg.machineUrl == "" {
-		log.Fatalf("%s is not running. Please start this with %s start %s", cfg.machineName, c.App.Name, cfg.machineName)
+		log.Fatal(notRunningHint(c.App.Name, cfg.machineName, cfg.machineState))
 	}
 
 	dockerHost := cfg.machineUrl
@@ -107,22 +124,10 @@ func cmdEnv(c *cli.Context) {
 		if !cfg.SwarmOptions.Master {
 			log.Fatalf("%s is not a swarm master", cfg.machineName)
 		}
-		u, err := url.Parse(cfg.SwarmOptions.Host)
+		dockerHost, err = swarm.GetMasterURL(cfg.machineUrl, cfg.SwarmOptions)
 		if err != nil {
 			log.Fatal(err)
 		}
-		parts := strings.Split(u.Host, ":")
-		swarmPort := parts[1]
-
-		// get IP of machine to replace in case swarm host is 0.0.0.0
-		mUrl, err := url.Parse(cfg.machineUrl)
-		if err != nil {
-			log.Fatal(err)
-		}
-		mParts := strings.Split(mUrl.Host, ":")
-		machineIp := mParts[0]
-
-		dockerHost = fmt.Sprintf("tcp://%s:%s", machineIp, swarmPort)
 	}
 
 	u, err := url.Parse(cfg.machineUrl)
@@ -151,14 +156,18 @@ func cmdEnv(c *cli.Context) {
 		}
 	}
 
-	shellCfg = ShellConfig{
+	printEnv(userShell, ShellConfig{
 		DockerCertPath:  cfg.machineDir,
 		DockerHost:      dockerHost,
 		DockerTLSVerify: "1",
 		UsageHint:       usageHint,
 		MachineName:     cfg.machineName,
-	}
+	})
+}
 
+// printEnv prints the commands setting the variables of shellCfg in the
+// shell.
+func printEnv(userShell string, shellCfg ShellConfig) {
 	switch userShell {
 	case "fish":
 		shellCfg.Prefix = "set -x "
@@ -178,7 +187,7 @@ func cmdEnv(c *cli.Context) {
 		shellCfg.Delimiter = "=\""
 	}
 
-	tmpl, err := t.Parse(envTmpl)
+	tmpl, err := template.New("envConfig").Parse(envTmpl)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+func cmdEvents(c *cli.Context) {
+	storagePath := c.GlobalString("storage-path")
+	if storagePath == "" {
+		storagePath = utils.GetBaseDir()
+	}
+	// The events of the machines of a remote store are recorded next to
+	// its local cache.
+	if libmachine.IsRemoteStorePath(storagePath) {
+		cachePath, err := libmachine.GetRemoteStoreCachePath(utils.GetBaseDir(), storagePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		storagePath = cachePath
+	}
+
+	since, err := parseEventsSince(c.String("since"), time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	filter := libmachine.EventFilter{
+		Since:   since,
+		Machine: c.String("machine"),
+		Action:  c.String("type"),
+	}
+
+	webhook := c.String("webhook")
+	if webhook != "" && !c.Bool("follow") {
+		log.Fatal("Error: --webhook posts the new events, so it requires --follow")
+	}
+
+	show := func(e libmachine.Event) error {
+		fmt.Println(formatEvent(e))
+		return nil
+	}
+
+	journal := libmachine.NewEventJournal(storagePath)
+
+	if c.Bool("follow") {
+		// only the events recorded while following are posted, so that
+		// the history is not posted again each time
+		post := func(e libmachine.Event) error {
+			show(e)
+			if webhook != "" {
+				if err := libmachine.PostEvent(webhook, e); err != nil {
+					log.Errorf("Error posting event: %s", err)
+				}
+			}
+			return nil
+		}
+		if err := journal.Follow(filter, nil, show, post); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	events, err := journal.Read(filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, e := range events {
+		show(e)
+	}
+}
+
+// parseEventsSince parses either a duration before now, e.g. "2h", or a
+// timestamp, e.g. "2015-06-01T10:00:00Z".
+func parseEventsSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(since); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error: --since expects a duration (e.g. 2h) or a timestamp (e.g. 2015-06-01T10:00:00Z); received %q", since)
+	}
+	return t, nil
+}
+
+func formatEvent(e libmachine.Event) string {
+	fields := []string{
+		e.Time.Local().Format(time.RFC3339),
+		e.Machine,
+		e.Action,
+		e.Result,
+		e.User,
+	}
+	if e.Error != "" {
+		fields = append(fields, fmt.Sprintf("(%s)", e.Error))
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEventsSince(t *testing.T) {
+	now := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseEventsSince("2h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), since)
+
+	since, err = parseEventsSince("2015-06-01T10:30:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2015, 6, 1, 10, 30, 0, 0, time.UTC), since)
+
+	since, err = parseEventsSince("", now)
+	assert.NoError(t, err)
+	assert.True(t, since.IsZero())
+
+	_, err = parseEventsSince("yesterday", now)
+	assert.Error(t, err)
+}
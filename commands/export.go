@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"bytes"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+func cmdExport(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelp(c, "export")
+		log.Fatal(ErrExpectedOneMachine)
+	}
+
+	name := c.Args().First()
+	output := c.String("output")
+	if output == "" {
+		output = name + ".tar.gz"
+	}
+
+	certInfo := getCertPathInfo(c)
+	defaultStore, err := getDefaultStore(
+		c.GlobalString("storage-path"),
+		certInfo.CaCertPath,
+		certInfo.CaKeyPath,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var bundle bytes.Buffer
+	if err := libmachine.ExportHost(defaultStore, name, &bundle, c.Bool("ca")); err != nil {
+		log.Fatalf("Error exporting machine %s: %s", name, err)
+	}
+
+	if err := utils.WriteFileAtomic(output, bundle.Bytes(), 0600); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("Exported %s to %s", name, output)
+	log.Warn("The bundle contains the credentials of the machine in plaintext; keep it safe.")
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+)
+
+func cmdImport(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelp(c, "import")
+		log.Fatal("You must specify a bundle to import")
+	}
+
+	certInfo := getCertPathInfo(c)
+	defaultStore, err := getDefaultStore(
+		c.GlobalString("storage-path"),
+		certInfo.CaCertPath,
+		certInfo.CaKeyPath,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(c.Args().First())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	host, err := libmachine.ImportHost(defaultStore, f, c.String("name"))
+	if err != nil {
+		log.Fatalf("Error importing %s: %s", f.Name(), err)
+	}
+
+	log.Infof("Imported machine %s", host.Name)
+	if host.HostOptions.AuthOptions.CaCertPath == certInfo.CaCertPath {
+		log.Infof("The bundle does not include its CA; run 'docker-machine regenerate-certs %s' to use the CA of this store.", host.Name)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"text/template"
 
+	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/log"
 
 	"github.com/codegangsta/cli"
@@ -31,7 +32,7 @@ func cmdInspect(c *cli.Context) {
 			log.Fatalf("Template parsing error: %v\n", err)
 		}
 
-		jsonHost, err := json.Marshal(getHost(c))
+		jsonHost, err := json.Marshal(libmachine.RedactSecrets(getHost(c)))
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -45,7 +46,7 @@ func cmdInspect(c *cli.Context) {
 		}
 		os.Stdout.Write([]byte{'\n'})
 	} else {
-		prettyJSON, err := json.MarshalIndent(getHost(c), "", "    ")
+		prettyJSON, err := json.MarshalIndent(libmachine.RedactSecrets(getHost(c)), "", "    ")
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+)
+
+func cmdLabelAdd(c *cli.Context) {
+	if len(c.Args()) < 2 {
+		cli.ShowCommandHelp(c, "add")
+		log.Fatal("You must specify a machine name and one or more labels")
+	}
+
+	name := c.Args().First()
+
+	labels, err := libmachine.ParseLabels(c.Args().Tail())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := getDefaultProvider(c).UpdateLabels(name, labels, nil); err != nil {
+		log.Fatalf("Error updating the labels of machine %s: %s", name, err)
+	}
+}
+
+func cmdLabelRm(c *cli.Context) {
+	if len(c.Args()) < 2 {
+		cli.ShowCommandHelp(c, "rm")
+		log.Fatal("You must specify a machine name and one or more label keys")
+	}
+
+	name := c.Args().First()
+
+	if err := getDefaultProvider(c).UpdateLabels(name, nil, c.Args().Tail()); err != nil {
+		log.Fatalf("Error updating the labels of machine %s: %s", name, err)
+	}
+}
+
+func cmdLabelLs(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelp(c, "ls")
+		log.Fatal("You must specify a machine name")
+	}
+
+	host, err := getDefaultProvider(c).Get(c.Args().First())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, label := range libmachine.FormatLabels(host.HostOptions.Labels) {
+		fmt.Println(label)
+	}
+}
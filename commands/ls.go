@@ -19,6 +19,7 @@ type FilterOptions struct {
 	DriverName []string
 	State      []string
 	Name       []string
+	Label      []libmachine.LabelSelector
 }
 
 func cmdLs(c *cli.Context) {
@@ -28,11 +29,31 @@ func cmdLs(c *cli.Context) {
 		log.Fatal(err)
 	}
 
+	if api := getAPIClient(c); api != nil {
+		machines, err := api.ListMachines()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		machines = filterMachineInfos(machines, filters)
+
+		if quiet {
+			for _, m := range machines {
+				fmt.Println(m.Name)
+			}
+			return
+		}
+
+		printHostListItems(getRemoteHostListItems(machines), getRemoteSwarmMasters(machines))
+		return
+	}
+
 	provider := getDefaultProvider(c)
 	hostList, err := provider.List()
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer libmachine.CloseHosts(hostList)
 
 	hostList = filterHosts(hostList, filters)
 
@@ -44,25 +65,15 @@ func cmdLs(c *cli.Context) {
 		return
 	}
 
-	swarmMasters := make(map[string]string)
-	swarmInfo := make(map[string]string)
+	printHostListItems(libmachine.GetHostListItems(hostList), getSwarmMasters(hostList))
+}
 
+// printHostListItems prints the table of machines of ls.  swarmMasters
+// are the names of the swarm masters by discovery.
+func printHostListItems(items []libmachine.HostListItem, swarmMasters map[string]string) {
 	w := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
 	fmt.Fprintln(w, "NAME\tACTIVE\tDRIVER\tSTATE\tURL\tSWARM")
 
-	for _, host := range hostList {
-		swarmOptions := host.HostOptions.SwarmOptions
-		if swarmOptions.Master {
-			swarmMasters[swarmOptions.Discovery] = host.Name
-		}
-
-		if swarmOptions.Discovery != "" {
-			swarmInfo[host.Name] = swarmOptions.Discovery
-		}
-	}
-
-	items := libmachine.GetHostListItems(hostList)
-
 	sortHostListItemsByName(items)
 
 	for _, item := range items {
@@ -104,6 +115,12 @@ func parseFilters(filters []string) (FilterOptions, error) {
 			options.State = append(options.State, value)
 		case "name":
 			options.Name = append(options.Name, value)
+		case "label":
+			selector, err := libmachine.ParseLabelSelector(value)
+			if err != nil {
+				return options, err
+			}
+			options.Label = append(options.Label, selector)
 		default:
 			return options, fmt.Errorf("Unsupported filter key '%s'", key)
 		}
@@ -115,7 +132,8 @@ func filterHosts(hosts []*libmachine.Host, filters FilterOptions) []*libmachine.
 	if len(filters.SwarmName) == 0 &&
 		len(filters.DriverName) == 0 &&
 		len(filters.State) == 0 &&
-		len(filters.Name) == 0 {
+		len(filters.Name) == 0 &&
+		len(filters.Label) == 0 {
 		return hosts
 	}
 
@@ -146,8 +164,9 @@ func filterHost(host *libmachine.Host, filters FilterOptions, swarmMasters map[s
 	driverMatches := matchesDriverName(host, filters.DriverName)
 	stateMatches := matchesState(host, filters.State)
 	nameMatches := matchesName(host, filters.Name)
+	labelMatches := matchesLabels(host, filters.Label)
 
-	return swarmMatches && driverMatches && stateMatches && nameMatches
+	return swarmMatches && driverMatches && stateMatches && nameMatches && labelMatches
 }
 
 func matchesSwarmName(host *libmachine.Host, swarmNames []string, swarmMasters map[string]string) bool {
@@ -207,3 +226,14 @@ func matchesName(host *libmachine.Host, names []string) bool {
 	}
 	return false
 }
+
+// matchesLabels returns whether the host matches all the label filters,
+// unlike the other filters, so that they can narrow down a selection.
+func matchesLabels(host *libmachine.Host, selectors []libmachine.LabelSelector) bool {
+	for _, s := range selectors {
+		if !s.Matches(host) {
+			return false
+		}
+	}
+	return true
+}
@@ -273,3 +273,36 @@ func TestFilterHostsDifferentFlagsProduceAND(t *testing.T) {
 
 	assert.EqualValues(t, filterHosts(hosts, opts), expected)
 }
+
+func TestParseFiltersLabel(t *testing.T) {
+	actual, err := parseFilters([]string{"label=team=web"})
+	assert.NoError(t, err)
+	assert.Len(t, actual.Label, 1)
+
+	_, err = parseFilters([]string{"label=!"})
+	assert.Error(t, err)
+}
+
+func TestFilterHostsLabel(t *testing.T) {
+	filters, err := parseFilters([]string{"label=team=web", "label=env!=prod"})
+	assert.NoError(t, err)
+
+	hosts := []*libmachine.Host{
+		{
+			Name:        "web-dev",
+			HostOptions: &libmachine.HostOptions{Labels: map[string]string{"team": "web", "env": "dev"}},
+		},
+		{
+			Name:        "web-prod",
+			HostOptions: &libmachine.HostOptions{Labels: map[string]string{"team": "web", "env": "prod"}},
+		},
+		{
+			Name:        "unlabeled",
+			HostOptions: &libmachine.HostOptions{},
+		},
+	}
+
+	actual := filterHosts(hosts, filters)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, "web-dev", actual[0].Name)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+	"github.com/docker/machine/version"
+)
+
+func cmdMigrate(c *cli.Context) {
+	certInfo := getCertPathInfo(c)
+	defaultStore, err := getDefaultStore(
+		c.GlobalString("storage-path"),
+		certInfo.CaCertPath,
+		certInfo.CaKeyPath,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, ok := defaultStore.(*libmachine.Filestore)
+	if !ok {
+		log.Fatal("Error: Only the machines of local stores can be migrated.")
+	}
+
+	plan, err := store.PlanMigrations()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(plan) == 0 {
+		log.Infof("All machines are at config version %d", version.ConfigVersion)
+		return
+	}
+
+	isError := false
+
+	for _, m := range plan {
+		if m.Err != nil {
+			log.Errorf("Error migrating machine %s: %s", m.Name, m.Err)
+			isError = true
+			continue
+		}
+
+		if c.Bool("dry-run") {
+			fmt.Printf("%s: config version %d => %d\n", m.Name, m.FromVersion, m.ToVersion)
+			fmt.Println(formatMigrationDiff(m))
+			continue
+		}
+
+		// loading a host migrates it
+		host, err := store.Get(m.Name)
+		if err != nil {
+			log.Errorf("Error migrating machine %s: %s", m.Name, err)
+			isError = true
+			continue
+		}
+		host.Close()
+		log.Infof("Migrated %s from config version %d to %d", m.Name, m.FromVersion, m.ToVersion)
+	}
+
+	if isError {
+		log.Fatal("There was an error migrating a machine.")
+	}
+}
+
+// formatMigrationDiff returns the diff of the config of a host before and
+// after its migration, with the credentials of its driver redacted.
+func formatMigrationDiff(m libmachine.HostMigration) string {
+	diff := utils.DiffLines(
+		strings.Split(string(m.Original), "\n"),
+		strings.Split(string(m.Migrated), "\n"),
+	)
+	return log.Redact(strings.Join(diff, "\n"))
+}
@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine"
+)
+
+func TestFormatMigrationDiff(t *testing.T) {
+	m := libmachine.HostMigration{
+		Original: []byte("{\n    \"ConfigVersion\": 0\n}"),
+		Migrated: []byte("{\n    \"ConfigVersion\": 1\n}"),
+	}
+
+	expected := " {\n-    \"ConfigVersion\": 0\n+    \"ConfigVersion\": 1\n }"
+	if diff := formatMigrationDiff(m); diff != expected {
+		t.Fatalf("expected %q; received %q", expected, diff)
+	}
+}
@@ -0,0 +1,17 @@
+package commands
+
+import (
+	"github.com/docker/machine/log"
+
+	"github.com/codegangsta/cli"
+)
+
+func cmdPause(c *cli.Context) {
+	action := "pause"
+	if c.Bool("suspend") {
+		action = "suspend"
+	}
+	if err := runActionWithContext(action, c); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,460 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/client"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
+	"github.com/docker/machine/ssh"
+	"github.com/docker/machine/state"
+	"github.com/docker/machine/utils"
+)
+
+// remoteCommands are the commands which can run against a machine server
+// (see `serve`) given with --host.
+var remoteCommands = map[string]bool{
+	"create":    true,
+	"env":       true,
+	"ls":        true,
+	"scp":       true,
+	"ssh":       true,
+	"ssh-proxy": true,
+}
+
+// CheckRemoteCommand returns an error if the commands run against a machine
+// server, and the command can only run locally.
+func CheckRemoteCommand(c *cli.Context) error {
+	command := c.Args().First()
+	if c.GlobalString("host") == "" || command == "" || strings.HasPrefix(command, "-") {
+		return nil
+	}
+	if command == "help" || command == "h" || remoteCommands[command] {
+		return nil
+	}
+	return fmt.Errorf("The %s command can not be run against a machine server (--host)", command)
+}
+
+// getAPIClient returns a client of the machine server given with --host,
+// or nil if the commands run locally.  The client authenticates with the
+// client certificate, which is signed by the machine CA of the server.
+func getAPIClient(c *cli.Context) *client.Client {
+	serverURL := c.GlobalString("host")
+	if serverURL == "" {
+		return nil
+	}
+
+	certInfo := getCertPathInfo(c)
+	tlsConfig, err := utils.NewClientTLSConfig(
+		certInfo.CaCertPath,
+		certInfo.ClientCertPath,
+		certInfo.ClientKeyPath,
+	)
+	if err != nil {
+		log.Fatalf("Error reading TLS credentials for machine server %s: %s", serverURL, err)
+	}
+
+	api, err := client.New(serverURL, tlsConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := api.Version(); err != nil {
+		log.Fatal(err)
+	}
+
+	return api
+}
+
+// getRemoteMachineDir returns the local directory of a machine of a machine
+// server, where its certificates and SSH key are kept for Docker and SSH.
+func getRemoteMachineDir(api *client.Client, name string) (string, error) {
+	cachePath, err := libmachine.GetRemoteStoreCachePath(utils.GetBaseDir(), api.URL())
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cachePath, "machines", name), nil
+}
+
+// writeRemoteMachineFiles writes the files, by name, which are not empty
+// into the directory of a machine of a machine server.
+func writeRemoteMachineFiles(dir string, files map[string]string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	for name, data := range files {
+		if data == "" {
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getCreateRequest returns the request to create the machine "name" with
+// the create flags: the driver, profile and labels, the values of the
+// template, and the flags which were set explicitly.  The other flags take
+// their values on the server.
+func getCreateRequest(c *cli.Context, name string) (libmachine.CreateRequest, error) {
+	if c.Bool("resume") || c.Bool("keep-on-failure") {
+		return libmachine.CreateRequest{}, errors.New("--resume and --keep-on-failure can not be used with a machine server")
+	}
+
+	labels, err := libmachine.ParseLabels(c.StringSlice("label"))
+	if err != nil {
+		return libmachine.CreateRequest{}, err
+	}
+
+	req := libmachine.CreateRequest{
+		Name:    name,
+		Profile: c.String("profile"),
+		Options: make(map[string]interface{}),
+	}
+	if c.IsSet("driver") || c.IsSet("d") {
+		req.Driver = c.String("driver")
+	}
+
+	if templateName := c.String("template"); templateName != "" {
+		t, err := getTemplateStore(c).Get(templateName)
+		if err != nil {
+			return libmachine.CreateRequest{}, err
+		}
+		if req.Driver == "" {
+			req.Driver = t.DriverName
+		}
+		req.Options = getTemplateValues(t, req.Driver)
+		labels = getTemplateLabels(t, labels)
+	}
+
+	for _, f := range c.Command.Flags {
+		name := drivers.FlagName(f)
+		if reservedCreateOptions[name] || !c.IsSet(name) {
+			continue
+		}
+
+		if _, ok := f.(cli.StringSliceFlag); ok {
+			values := []interface{}{}
+			for _, value := range c.StringSlice(name) {
+				values = append(values, value)
+			}
+			req.Options[name] = values
+		} else {
+			req.Options[name] = c.String(name)
+		}
+	}
+
+	if len(labels) > 0 {
+		req.Labels = labels
+	}
+
+	return req, nil
+}
+
+func cmdCreateRemote(c *cli.Context, api *client.Client, name string) {
+	req, err := getCreateRequest(c, name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	job, err := api.Create(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := newCommandContext(c)
+	defer cancel()
+
+	if _, err := api.Wait(ctx, job, progress.LogSink); err != nil {
+		log.Fatalf("Error creating machine: %s", err)
+	}
+
+	info := fmt.Sprintf("%s --host %s env %s", c.App.Name, c.GlobalString("host"), name)
+	log.Infof("To see how to connect Docker to this machine, run: %s", info)
+}
+
+// getRemoteEnv returns the Docker host of a machine of a machine server,
+// and the local directory its certificates are saved to.
+func getRemoteEnv(api *client.Client, name string, swarmMaster bool) (string, string, error) {
+	env, err := api.GetEnv(name, swarmMaster)
+	if err != nil {
+		return "", "", err
+	}
+
+	bundle, err := api.GetCertBundle(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	certPath, err := getRemoteMachineDir(api, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := writeRemoteMachineFiles(certPath, map[string]string{
+		"ca.pem":   bundle.CA,
+		"cert.pem": bundle.Cert,
+		"key.pem":  bundle.Key,
+	}); err != nil {
+		return "", "", fmt.Errorf("Error saving the certificates of %s: %s", name, err)
+	}
+
+	return env["DOCKER_HOST"], certPath, nil
+}
+
+// filterMachineInfos filters the machines of a machine server as
+// filterHosts does local machines.
+func filterMachineInfos(machines []libmachine.MachineInfo, filters FilterOptions) []libmachine.MachineInfo {
+	filtered := []libmachine.MachineInfo{}
+	swarmMasters := getRemoteSwarmMasters(machines)
+
+	for _, m := range machines {
+		host := &libmachine.Host{
+			Name:       m.Name,
+			DriverName: m.DriverName,
+			HostOptions: &libmachine.HostOptions{
+				Labels:       m.Labels,
+				SwarmOptions: m.SwarmOptions,
+			},
+		}
+
+		if matchesSwarmName(host, filters.SwarmName, swarmMasters) &&
+			matchesDriverName(host, filters.DriverName) &&
+			matchesStrings(m.State, filters.State, false) &&
+			matchesStrings(m.Name, filters.Name, true) &&
+			matchesLabels(host, filters.Label) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// matchesStrings returns whether the value is one of the wanted values, or
+// matches one of them as a regular expression.
+func matchesStrings(value string, wanted []string, regex bool) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, w := range wanted {
+		if !regex {
+			if value == w {
+				return true
+			}
+			continue
+		}
+
+		r, err := regexp.Compile(w)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if r.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func getRemoteSwarmMasters(machines []libmachine.MachineInfo) map[string]string {
+	swarmMasters := make(map[string]string)
+	for _, m := range machines {
+		if m.SwarmOptions != nil && m.SwarmOptions.Master {
+			swarmMasters[m.SwarmOptions.Discovery] = m.Name
+		}
+	}
+	return swarmMasters
+}
+
+// getRemoteHostListItems returns the machines of a machine server as ls
+// lists them.  A machine is active if DOCKER_HOST is its URL.
+func getRemoteHostListItems(machines []libmachine.MachineInfo) []libmachine.HostListItem {
+	dockerHost := os.Getenv("DOCKER_HOST")
+
+	items := []libmachine.HostListItem{}
+	for _, m := range machines {
+		item := libmachine.HostListItem{
+			Name:       m.Name,
+			DriverName: m.DriverName,
+			State:      parseState(m.State),
+			URL:        m.URL,
+		}
+		item.Active = item.State != state.Stopped && m.URL != "" && m.URL == dockerHost
+		if m.SwarmOptions != nil {
+			item.SwarmOptions = *m.SwarmOptions
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func parseState(name string) state.State {
+	for s := state.None; s <= state.Timeout; s++ {
+		if s.String() == name {
+			return s
+		}
+	}
+	return state.None
+}
+
+// getRemoteSSHKey saves the SSH key of a machine of a machine server
+// locally, and returns its path along with the SSH user.
+func getRemoteSSHKey(api *client.Client, name string) (string, string, error) {
+	key, err := api.GetSSHKey(name)
+	if err != nil {
+		return "", "", err
+	}
+
+	dir, err := getRemoteMachineDir(api, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := writeRemoteMachineFiles(dir, map[string]string{"id_rsa": key.Key}); err != nil {
+		return "", "", fmt.Errorf("Error saving the SSH key of %s: %s", name, err)
+	}
+
+	return filepath.Join(dir, "id_rsa"), key.Username, nil
+}
+
+// getSSHProxyCommand returns the ProxyCommand which makes ssh reach the
+// machine "name" through the machine server, with the ssh-proxy command.
+// name may be %h, for ssh to substitute the host it connects to.
+func getSSHProxyCommand(c *cli.Context, name string) (string, error) {
+	self, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return "", err
+	}
+	if self, err = filepath.Abs(self); err != nil {
+		return "", err
+	}
+
+	args := []string{self, "--host", c.GlobalString("host")}
+	for _, flag := range []string{"tls-ca-cert", "tls-client-cert", "tls-client-key"} {
+		if value := c.GlobalString(flag); value != "" {
+			args = append(args, "--"+flag, value)
+		}
+	}
+	args = append(args, "ssh-proxy", name)
+
+	for i, arg := range args {
+		if arg != "%h" {
+			args[i] = shellQuote(arg)
+		}
+	}
+	return strings.Join(args, " "), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// getRemoteSSHClient returns a client logging into a machine of a machine
+// server through the SSH tunnel of the server.
+func getRemoteSSHClient(c *cli.Context, api *client.Client, name string) (ssh.Client, error) {
+	sshBinaryPath, err := exec.LookPath("ssh")
+	if err != nil {
+		return nil, errors.New("Error: You must have a copy of the ssh binary locally to use ssh with a machine server.")
+	}
+
+	keyPath, username, err := getRemoteSSHKey(api, name)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyCommand, err := getSSHProxyCommand(c, name)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ssh.NewExternalClient(sshBinaryPath, username, name, 22, &ssh.Auth{
+		Keys: []string{keyPath},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client.BaseArgs = append([]string{"-o", "ProxyCommand=" + proxyCommand}, client.BaseArgs...)
+	return client, nil
+}
+
+func cmdSshRemote(c *cli.Context, api *client.Client, name, cmd string) {
+	machine, err := api.GetMachine(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch currentState := parseState(machine.State); currentState {
+	case state.Running:
+	case state.Paused, state.Saved:
+		log.Fatalf("Error: Cannot run SSH command: %s", notRunningHint(c.App.Name, name, currentState))
+	default:
+		log.Fatalf("Error: Cannot run SSH command: Host %q is not running", name)
+	}
+
+	client, err := getRemoteSSHClient(c, api, name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if cmd == "" {
+		if err := client.Shell(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	output, err := client.Output(cmd)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(output)
+}
+
+// getRemoteScpCmd returns the scp command copying between machines of a
+// machine server, through its SSH tunnel.
+func getRemoteScpCmd(c *cli.Context, api *client.Client, src, dest string, sshArgs []string) (*exec.Cmd, error) {
+	cmdPath, err := exec.LookPath("scp")
+	if err != nil {
+		return nil, errors.New("Error: You must have a copy of the scp binary locally to use the scp feature.")
+	}
+
+	proxyCommand, err := getSSHProxyCommand(c, "%h")
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"-o", "ProxyCommand=" + proxyCommand}, sshArgs...)
+
+	locations := []string{}
+	for _, arg := range []string{src, dest} {
+		// TODO: What to do about colon in filepath?
+		splitInfo := strings.Split(arg, ":")
+		switch len(splitInfo) {
+		case 1:
+			locations = append(locations, arg)
+		case 2:
+			keyPath, username, err := getRemoteSSHKey(api, splitInfo[0])
+			if err != nil {
+				return nil, fmt.Errorf("Error loading host: %s", err)
+			}
+			args = append(args, "-i", keyPath)
+			locations = append(locations, fmt.Sprintf("%s@%s", username, arg))
+		default:
+			return nil, ErrMalformedInput
+		}
+	}
+
+	cmd := exec.Command(cmdPath, append(args, locations...)...)
+	log.Debug(*cmd)
+	return cmd, nil
+}
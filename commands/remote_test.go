@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCreateRequest(t *testing.T) {
+	flags := []cli.Flag{
+		cli.StringFlag{Name: "driver, d", Value: "none"},
+		cli.StringFlag{Name: "profile"},
+		cli.StringFlag{Name: "template"},
+		cli.BoolFlag{Name: "resume"},
+		cli.BoolFlag{Name: "keep-on-failure"},
+		cli.StringSliceFlag{Name: "label", Value: &cli.StringSlice{}},
+		cli.StringSliceFlag{Name: "engine-label", Value: &cli.StringSlice{}},
+		cli.IntFlag{Name: "virtualbox-memory", Value: 1024},
+		cli.BoolFlag{Name: "swarm"},
+	}
+
+	set := flag.NewFlagSet("create", flag.ContinueOnError)
+	for _, f := range flags {
+		f.Apply(set)
+	}
+	if err := set.Parse([]string{
+		"--driver", "virtualbox",
+		"--label", "env=dev",
+		"--engine-label", "a=b",
+		"--virtualbox-memory", "2048",
+		"dev",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := cli.NewContext(nil, set, flag.NewFlagSet("test", 0))
+	c.Command = cli.Command{Name: "create", Flags: flags}
+
+	req, err := getCreateRequest(c, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "dev", req.Name)
+	assert.Equal(t, "virtualbox", req.Driver)
+	assert.Equal(t, map[string]string{"env": "dev"}, req.Labels)
+	assert.Equal(t, map[string]interface{}{
+		"engine-label":      []interface{}{"a=b"},
+		"virtualbox-memory": "2048",
+	}, req.Options)
+}
+
+func TestFilterMachineInfos(t *testing.T) {
+	machines := []libmachine.MachineInfo{
+		{
+			Name:         "master",
+			DriverName:   "virtualbox",
+			State:        "Running",
+			SwarmOptions: &swarm.SwarmOptions{Master: true, Discovery: "token://a"},
+		},
+		{
+			Name:         "node",
+			DriverName:   "digitalocean",
+			State:        "Stopped",
+			Labels:       map[string]string{"env": "prod"},
+			SwarmOptions: &swarm.SwarmOptions{Discovery: "token://a"},
+		},
+		{
+			Name:       "dev",
+			DriverName: "virtualbox",
+			State:      "Running",
+		},
+	}
+
+	names := func(filter string) []string {
+		filters, err := parseFilters([]string{filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := []string{}
+		for _, m := range filterMachineInfos(machines, filters) {
+			result = append(result, m.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"master", "dev"}, names("driver=virtualbox"))
+	assert.Equal(t, []string{"node"}, names("state=Stopped"))
+	assert.Equal(t, []string{"master", "node"}, names("swarm=master"))
+	assert.Equal(t, []string{"node"}, names("label=env=prod"))
+	assert.Equal(t, []string{"dev"}, names("name=^d"))
+}
+
+func TestGetRemoteHostListItems(t *testing.T) {
+	items := getRemoteHostListItems([]libmachine.MachineInfo{
+		{Name: "dev", State: "Paused"},
+	})
+	assert.Equal(t, state.Paused, items[0].State)
+	assert.False(t, items[0].Active)
+}
+
+func TestCheckRemoteCommand(t *testing.T) {
+	check := func(host string, args ...string) error {
+		globalSet := flag.NewFlagSet("test", 0)
+		globalSet.String("host", host, "")
+		globalSet.Parse(args)
+		return CheckRemoteCommand(cli.NewContext(nil, globalSet, globalSet))
+	}
+
+	assert.NoError(t, check("", "rm", "dev"))
+	assert.NoError(t, check("machines.example.com", "ls"))
+	assert.NoError(t, check("machines.example.com", "help"))
+	assert.Error(t, check("machines.example.com", "rm", "dev"))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'/usr/local/bin/docker-machine'`, shellQuote("/usr/local/bin/docker-machine"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
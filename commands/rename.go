@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/log"
+)
+
+func cmdRename(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		cli.ShowCommandHelp(c, "rename")
+		log.Fatal("You must specify the current and the new name of the machine")
+	}
+
+	oldName := c.Args().Get(0)
+	newName := c.Args().Get(1)
+
+	provider := getDefaultProvider(c)
+
+	if err := provider.Rename(oldName, newName); err != nil {
+		log.Fatalf("Error renaming machine %s: %s", oldName, err)
+	}
+
+	log.Infof("Renamed %s to %s", oldName, newName)
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/log"
+)
+
+func cmdResize(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelp(c, "resize")
+		log.Fatal("You must specify a machine name")
+	}
+
+	name := c.Args().First()
+
+	opts := drivers.ResizeOptions{
+		CPU:      c.Int("cpus"),
+		Memory:   c.Int("memory"),
+		DiskSize: c.Int("disk-size"),
+		Size:     c.String("size"),
+	}
+	if opts == (drivers.ResizeOptions{}) {
+		cli.ShowCommandHelp(c, "resize")
+		log.Fatal("You must specify at least one of --cpus, --memory, --disk-size and --size")
+	}
+
+	if err := getDefaultProvider(c).Resize(name, opts); err != nil {
+		log.Fatalf("Error resizing machine %s: %s", name, err)
+	}
+
+	log.Infof("Resized %s", name)
+}
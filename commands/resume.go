@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/docker/pkg/term"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/state"
+)
+
+func cmdResume(c *cli.Context) {
+	if err := runActionWithContext("resume", c); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// offerResume asks whether to resume a paused or suspended machine, which
+// can not be used otherwise, and resumes it.  The question is asked on
+// stderr, as the output of commands such as env is evaluated by the shell,
+// and only if stdin is a terminal.  It returns the state of the machine.
+func offerResume(c *cli.Context, host *libmachine.Host) state.State {
+	machineState, err := host.Driver.GetState()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if machineState != state.Paused && machineState != state.Saved {
+		return machineState
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return machineState
+	}
+
+	fmt.Fprintf(os.Stderr, "Machine %s is %s. Resume it? (y/n): ", host.Name, strings.ToLower(machineState.String()))
+	var resp string
+	if _, err := fmt.Scanln(&resp); err != nil || !strings.HasPrefix(strings.ToLower(resp), "y") {
+		return machineState
+	}
+
+	ctx, cancel := newCommandContext(c)
+	defer cancel()
+
+	// The machine is resumed as it is once it is locked, and the config of
+	// host is reloaded afterwards, as it may have changed in the meantime.
+	if err := getDefaultProvider(c).RunAction(ctx, host.Name, "resume"); err != nil {
+		log.Fatalf("Error resuming machine %s: %s", host.Name, err)
+	}
+
+	if err := host.LoadConfig(); err != nil {
+		log.Fatalf("Error loading machine %s: %s", host.Name, err)
+	}
+
+	return state.Running
+}
+
+// notRunningHint returns how to get a machine which is not running going.
+func notRunningHint(appName, name string, machineState state.State) string {
+	switch machineState {
+	case state.Paused, state.Saved:
+		return fmt.Sprintf("%s is %s. Please resume it with %s resume %s", name, strings.ToLower(machineState.String()), appName, name)
+	}
+	return fmt.Sprintf("%s is not running. Please start this with %s start %s", name, appName, name)
+}
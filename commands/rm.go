@@ -6,11 +6,32 @@ import (
 )
 
 func cmdRm(c *cli.Context) {
-	if len(c.Args()) == 0 {
+	if len(c.Args()) == 0 && c.String("selector") == "" {
 		cli.ShowCommandHelp(c, "rm")
 		log.Fatal("You must specify a machine name")
 	}
 
+	names := c.Args()
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+
+	selected, err := getSelectedHosts(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, host := range selected {
+		if !seen[host.Name] {
+			seen[host.Name] = true
+			names = append(names, host.Name)
+		}
+	}
+	if len(names) == 0 {
+		log.Info("No machines match the selector")
+		return
+	}
+
 	force := c.Bool("force")
 
 	isError := false
@@ -30,8 +51,11 @@ func cmdRm(c *cli.Context) {
 		log.Fatal(err)
 	}
 
-	for _, host := range c.Args() {
-		if err := provider.Remove(host, force); err != nil {
+	ctx, cancel := newCommandContext(c)
+	defer cancel()
+
+	for _, host := range names {
+		if err := provider.RemoveContext(ctx, host, force); err != nil {
 			log.Errorf("Error removing machine %s: %s", host, err)
 			isError = true
 		} else {
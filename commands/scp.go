@@ -129,8 +129,16 @@ func cmdScp(c *cli.Context) {
 	src := args[0]
 	dest := args[1]
 
-	provider := getDefaultProvider(c)
-	cmd, err := getScpCmd(src, dest, sshArgs, *provider)
+	var (
+		cmd *exec.Cmd
+		err error
+	)
+	if api := getAPIClient(c); api != nil {
+		cmd, err = getRemoteScpCmd(c, api, src, dest, sshArgs)
+	} else {
+		provider := getDefaultProvider(c)
+		cmd, err = getScpCmd(src, dest, sshArgs, *provider)
+	}
 
 	if err != nil {
 		log.Fatal(err)
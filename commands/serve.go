@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"net/http"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+)
+
+func cmdServe(c *cli.Context) {
+	certInfo := getCertPathInfo(c)
+
+	tlsConfig, err := getServerTLSConfig(c, certInfo, "api-server")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := &http.Server{
+		Addr:      c.String("addr"),
+		Handler:   libmachine.NewAPIServer(getDefaultProvider(c), getServeCreateOptions(c)),
+		TLSConfig: tlsConfig,
+	}
+
+	log.Infof("Serving the machine API %s on https://%s", libmachine.APIVersion, server.Addr)
+	log.Infof("Clients authenticate with a certificate signed by %s", certInfo.CaCertPath)
+
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// getServeCreateOptions returns how the API server turns a create request
+// into the options of the machine, as the create command does with its
+// flags.  The defaults of the config of the server apply; it is read for
+// each request so that it can be changed while serving.
+func getServeCreateOptions(c *cli.Context) libmachine.CreateOptionsFunc {
+	certInfo := getCertPathInfo(c)
+
+	return func(req libmachine.CreateRequest) (string, *libmachine.HostOptions, drivers.DriverOptions, error) {
+		config, err := loadCLIConfig(getCLIConfigPath(c))
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if err := config.checkProfile(req.Profile); err != nil {
+			return "", nil, nil, err
+		}
+
+		driver := req.Driver
+		if driver == "" {
+			driver = config.getDriver(req.Profile)
+		}
+		if driver == "" {
+			driver = "none"
+		}
+
+		opts, err := getCreateOptions(driver, req.Options, config.getDefaults(req.Profile, driver))
+		if err != nil {
+			return "", nil, nil, err
+		}
+
+		if err := validateSwarmDiscovery(opts.String("swarm-discovery")); err != nil {
+			return "", nil, nil, err
+		}
+
+		labels, err := libmachine.ParseLabels(libmachine.FormatLabels(req.Labels))
+		if err != nil {
+			return "", nil, nil, err
+		}
+
+		return driver, getHostOptions(opts, certInfo, labels), opts, nil
+	}
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetServeCreateOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-serve-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, cliConfigFile), []byte(testCLIConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	globalSet := flag.NewFlagSet("test", 0)
+	globalSet.String("storage-path", dir, "")
+	createOptions := getServeCreateOptions(cli.NewContext(nil, flag.NewFlagSet("serve", 0), globalSet))
+
+	driver, hostOptions, opts, err := createOptions(libmachine.CreateRequest{
+		Profile: "prod",
+		Options: map[string]interface{}{"engine-label": []interface{}{"a=b"}},
+		Labels:  map[string]string{"env": "prod"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "amazonec2", driver)
+	assert.Equal(t, "eu-west-1", opts.String("amazonec2-region"))
+	assert.Equal(t, []string{"a=b"}, hostOptions.EngineOptions.Labels)
+	assert.Equal(t, "prod", hostOptions.Labels["env"])
+
+	driver, _, _, err = createOptions(libmachine.CreateRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "none", driver)
+
+	_, _, _, err = createOptions(libmachine.CreateRequest{Profile: "missing"})
+	assert.Error(t, err)
+
+	_, _, _, err = createOptions(libmachine.CreateRequest{Driver: "none", Options: map[string]interface{}{"unknown": 1}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/log"
+)
+
+func cmdSnapshotCreate(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		cli.ShowCommandHelp(c, "create")
+		log.Fatal("You must specify a machine name and a snapshot name")
+	}
+
+	machineName, snapshotName := c.Args()[0], c.Args()[1]
+
+	if err := getDefaultProvider(c).TakeSnapshot(machineName, snapshotName); err != nil {
+		log.Fatalf("Error taking snapshot %s of machine %s: %s", snapshotName, machineName, err)
+	}
+
+	log.Infof("Took snapshot %s of %s", snapshotName, machineName)
+}
+
+func cmdSnapshotLs(c *cli.Context) {
+	if len(c.Args()) != 1 {
+		cli.ShowCommandHelp(c, "ls")
+		log.Fatal("You must specify a machine name")
+	}
+
+	snapshots, err := getDefaultProvider(c).ListSnapshots(c.Args().First())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID")
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%s\t%s\n", snapshot.Name, snapshot.ID)
+	}
+	w.Flush()
+}
+
+func cmdSnapshotRestore(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		cli.ShowCommandHelp(c, "restore")
+		log.Fatal("You must specify a machine name and a snapshot name")
+	}
+
+	machineName, snapshotName := c.Args()[0], c.Args()[1]
+
+	if !c.Bool("force") && !confirmInput(fmt.Sprintf("Restore %s to snapshot %s?  Warning: the changes since the snapshot will be lost.", machineName, snapshotName)) {
+		return
+	}
+
+	if err := getDefaultProvider(c).RestoreSnapshot(machineName, snapshotName); err != nil {
+		log.Fatalf("Error restoring machine %s to snapshot %s: %s", machineName, snapshotName, err)
+	}
+
+	log.Infof("Restored %s to snapshot %s", machineName, snapshotName)
+	log.Info("The machine may have a new IP address. You may need to re-run the `docker-machine env` command.")
+}
+
+func cmdSnapshotRm(c *cli.Context) {
+	if len(c.Args()) < 2 {
+		cli.ShowCommandHelp(c, "rm")
+		log.Fatal("You must specify a machine name and one or more snapshot names")
+	}
+
+	machineName := c.Args().First()
+	provider := getDefaultProvider(c)
+
+	isError := false
+	for _, snapshotName := range c.Args().Tail() {
+		if err := provider.RemoveSnapshot(machineName, snapshotName); err != nil {
+			log.Errorf("Error removing snapshot %s of machine %s: %s", snapshotName, machineName, err)
+			isError = true
+		} else {
+			log.Infof("Successfully removed snapshot %s of %s", snapshotName, machineName)
+		}
+	}
+	if isError {
+		log.Fatal("There was an error removing a snapshot.")
+	}
+}
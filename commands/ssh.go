@@ -2,6 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"net"
+	"os"
 	"strings"
 
 	"github.com/docker/machine/log"
@@ -13,12 +16,21 @@ import (
 func cmdSsh(c *cli.Context) {
 	args := c.Args()
 	name := args.First()
-	cmd := ""
 
 	if name == "" {
 		log.Fatal("Error: Please specify a machine name.")
 	}
 
+	cmd := ""
+	if len(args) > 1 {
+		cmd = getSSHCommand(args)
+	}
+
+	if api := getAPIClient(c); api != nil {
+		cmdSshRemote(c, api, name, cmd)
+		return
+	}
+
 	certInfo := getCertPathInfo(c)
 	defaultStore, err := getDefaultStore(
 		c.GlobalString("storage-path"),
@@ -39,15 +51,37 @@ func cmdSsh(c *cli.Context) {
 		log.Fatal(err)
 	}
 
-	currentState, err := host.Driver.GetState()
-	if err != nil {
-		log.Fatal(err)
-	}
+	currentState := offerResume(c, host)
 
-	if currentState != state.Running {
+	switch currentState {
+	case state.Running:
+	case state.Paused, state.Saved:
+		log.Fatalf("Error: Cannot run SSH command: %s", notRunningHint(c.App.Name, host.Name, currentState))
+	default:
 		log.Fatalf("Error: Cannot run SSH command: Host %q is not running", host.Name)
 	}
 
+	if len(c.Args()) == 1 {
+		err := host.CreateSSHShell()
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		output, err := host.RunSSHCommand(cmd)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Print(output)
+	}
+
+}
+
+// getSSHCommand returns the command to run given in the arguments of ssh,
+// after the machine name.
+func getSSHCommand(args cli.Args) string {
+	cmd := ""
+
 	// Loop through the arguments and parse out a command which relies on
 	// flags if it exists, for instance an invocation of the form
 	// `docker-machine ssh dev -- df -h` would mandate this, otherwise we
@@ -71,18 +105,45 @@ func cmdSsh(c *cli.Context) {
 		cmd = strings.Join(args[1:], " ")
 	}
 
-	if len(c.Args()) == 1 {
-		err := host.CreateSSHShell()
-		if err != nil {
-			log.Fatal(err)
-		}
+	return cmd
+}
+
+// cmdSshProxy relays its standard input and output to the SSH port of a
+// machine, directly or through the machine server given with --host.  It is
+// used as the ProxyCommand of ssh and scp.
+func cmdSshProxy(c *cli.Context) {
+	name := c.Args().First()
+	if name == "" {
+		log.Fatal("Error: Please specify a machine name.")
+	}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if api := getAPIClient(c); api != nil {
+		conn, err = api.DialSSH(name)
 	} else {
-		output, err := host.RunSSHCommand(cmd)
-		if err != nil {
-			log.Fatal(err)
+		host := getHost(c)
+		addr, addrErr := host.GetSSHAddress()
+		if addrErr != nil {
+			log.Fatal(addrErr)
 		}
-
-		fmt.Print(output)
+		conn, err = net.Dial("tcp", addr)
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer conn.Close()
 
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(conn, os.Stdin)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(os.Stdout, conn)
+		done <- struct{}{}
+	}()
+	<-done
 }
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+func cmdStoreServe(c *cli.Context) {
+	storagePath := c.GlobalString("storage-path")
+	if libmachine.IsRemoteStorePath(storagePath) {
+		log.Fatal("Error: The store server can only serve a local storage path.")
+	}
+
+	certInfo := getCertPathInfo(c)
+
+	tlsConfig, err := getServerTLSConfig(c, certInfo, "store-server")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := libmachine.NewFilestore(
+		storagePath,
+		certInfo.CaCertPath,
+		certInfo.CaKeyPath,
+	)
+
+	server := &http.Server{
+		Addr:      c.String("addr"),
+		Handler:   libmachine.NewStoreServer(store),
+		TLSConfig: tlsConfig,
+	}
+
+	log.Infof("Serving machine store %s on https://%s", storagePath, server.Addr)
+	log.Infof("Clients authenticate with a certificate signed by %s", certInfo.CaCertPath)
+
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func cmdStoreRekey(c *cli.Context) {
+	storagePath := c.GlobalString("storage-path")
+	if libmachine.IsRemoteStorePath(storagePath) {
+		log.Fatal("Error: Only a local store can be rekeyed.")
+	}
+
+	certInfo := getCertPathInfo(c)
+
+	store := libmachine.NewFilestore(
+		storagePath,
+		certInfo.CaCertPath,
+		certInfo.CaKeyPath,
+	)
+
+	secretKey, err := libmachine.LoadSecretKey(storagePath, os.Getenv("MACHINE_STORE_PASSPHRASE"))
+	if err != nil {
+		log.Fatalf("Error loading the key of store %s: %s", storagePath, err)
+	}
+	store.SetSecretKey(secretKey)
+
+	passphrase := c.String("new-passphrase")
+	if _, err := libmachine.RekeyStore(store, passphrase); err != nil {
+		log.Fatalf("Error rekeying store %s: %s", storagePath, err)
+	}
+
+	if passphrase != "" {
+		log.Info("The credentials in the store are now encrypted with the new passphrase.")
+		log.Info("Set MACHINE_STORE_PASSPHRASE to it for further commands.")
+	} else {
+		log.Infof("The credentials in the store are now encrypted with %s", filepath.Join(storagePath, libmachine.SecretKeyFile))
+		if os.Getenv("MACHINE_STORE_PASSPHRASE") != "" {
+			log.Info("Unset MACHINE_STORE_PASSPHRASE for further commands.")
+		}
+	}
+}
+
+// getServerTLSConfig returns the TLS config of a server which clients
+// authenticate with a certificate signed by the machine CA.  The server
+// certificate, "name".pem in the certificate directory, is created for the
+// --hostname flags if it does not exist.
+func getServerTLSConfig(c *cli.Context, certInfo libmachine.CertPathInfo, name string) (*tls.Config, error) {
+	if err := setupCertificates(
+		certInfo.CaCertPath,
+		certInfo.CaKeyPath,
+		certInfo.ClientCertPath,
+		certInfo.ClientKeyPath); err != nil {
+		return nil, fmt.Errorf("Error generating certificates: %s", err)
+	}
+
+	serverCertPath := filepath.Join(utils.GetMachineCertDir(), name+".pem")
+	serverKeyPath := filepath.Join(utils.GetMachineCertDir(), name+"-key.pem")
+
+	if _, err := os.Stat(serverCertPath); os.IsNotExist(err) {
+		log.Infof("Creating server certificate: %s", serverCertPath)
+
+		if err := utils.GenerateCert(
+			getStoreServerHostnames(c.StringSlice("hostname")),
+			serverCertPath,
+			serverKeyPath,
+			certInfo.CaCertPath,
+			certInfo.CaKeyPath,
+			utils.GetUsername(),
+			2048,
+		); err != nil {
+			return nil, fmt.Errorf("Error generating server certificate: %s", err)
+		}
+	}
+
+	return utils.NewServerTLSConfig(certInfo.CaCertPath, serverCertPath, serverKeyPath)
+}
+
+// getStoreServerHostnames returns the names put into the certificate of
+// the store and API servers: the ones given by the user or the local
+// hostname.
+func getStoreServerHostnames(hostnames []string) []string {
+	if len(hostnames) > 0 {
+		return hostnames
+	}
+
+	names := []string{"localhost", "127.0.0.1"}
+	if hostname, err := os.Hostname(); err == nil {
+		names = append(names, hostname)
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+				names = append(names, ipNet.IP.String())
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,18 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStoreServerHostnamesGivenHostnames(t *testing.T) {
+	hostnames := getStoreServerHostnames([]string{"store.example.com"})
+	assert.Equal(t, []string{"store.example.com"}, hostnames)
+}
+
+func TestGetStoreServerHostnamesDefaultsToLocalhost(t *testing.T) {
+	hostnames := getStoreServerHostnames([]string{})
+	assert.Contains(t, hostnames, "localhost")
+	assert.Contains(t, hostnames, "127.0.0.1")
+}
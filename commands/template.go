@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/log"
+)
+
+func cmdTemplateSave(c *cli.Context) {
+	if len(c.Args()) != 2 {
+		cli.ShowCommandHelp(c, "save")
+		log.Fatal("You must specify a machine name and a template name")
+	}
+
+	machineName, templateName := c.Args()[0], c.Args()[1]
+
+	host, err := getDefaultProvider(c).Get(machineName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer host.Close()
+
+	t, err := libmachine.NewTemplate(templateName, host)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := getTemplateStore(c).Save(t); err != nil {
+		log.Fatalf("Error saving template %s: %s", templateName, err)
+	}
+
+	log.Infof("Saved template %s. Run `%s create --template %s <name>` to create a machine from it.", templateName, getAppName(c), templateName)
+}
+
+func cmdTemplateLs(c *cli.Context) {
+	templates, err := getTemplateStore(c).List()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDRIVER")
+	for _, t := range templates {
+		fmt.Fprintf(w, "%s\t%s\n", t.Name, t.DriverName)
+	}
+	w.Flush()
+}
+
+func cmdTemplateRm(c *cli.Context) {
+	if len(c.Args()) == 0 {
+		cli.ShowCommandHelp(c, "rm")
+		log.Fatal("You must specify a template name")
+	}
+
+	isError := false
+	for _, name := range c.Args() {
+		if err := getTemplateStore(c).Remove(name); err != nil {
+			log.Errorf("Error removing template %s: %s", name, err)
+			isError = true
+		} else {
+			log.Infof("Successfully removed template %s", name)
+		}
+	}
+	if isError {
+		log.Fatal("There was an error removing a template.")
+	}
+}
+
+// getTemplateStore returns the template store next to the machine store.
+// Templates of a remote store are kept locally.
+func getTemplateStore(c *cli.Context) *libmachine.TemplateStore {
+	return libmachine.NewTemplateStore(getLocalStorePath(c))
+}
+
+// getTemplateValues returns the values of the create flags of a machine
+// created with the driver from the template.  The driver options of the
+// template are only used if it is for the same driver.
+func getTemplateValues(t *libmachine.Template, driver string) map[string]interface{} {
+	values := make(map[string]interface{})
+
+	if driver == t.DriverName {
+		for name, value := range t.DriverOptions {
+			values[name] = value
+		}
+	} else if len(t.DriverOptions) > 0 {
+		log.Warnf("Not using the driver options of template %s, which is for driver %s", t.Name, t.DriverName)
+	}
+
+	if t.HostOptions != nil && t.HostOptions.EngineOptions != nil {
+		engineOptions := t.HostOptions.EngineOptions
+		setTemplateList(values, "engine-opt", engineOptions.ArbitraryFlags)
+		setTemplateList(values, "engine-env", engineOptions.Env)
+		setTemplateList(values, "engine-insecure-registry", engineOptions.InsecureRegistry)
+		setTemplateList(values, "engine-label", engineOptions.Labels)
+		setTemplateList(values, "engine-registry-mirror", engineOptions.RegistryMirror)
+		setTemplateString(values, "engine-storage-driver", engineOptions.StorageDriver)
+		setTemplateString(values, "engine-install-url", engineOptions.InstallURL)
+	}
+
+	if t.HostOptions != nil && t.HostOptions.SwarmOptions != nil && t.HostOptions.SwarmOptions.IsSwarm {
+		swarmOptions := t.HostOptions.SwarmOptions
+		values["swarm"] = true
+		values["swarm-master"] = swarmOptions.Master
+		setTemplateString(values, "swarm-discovery", swarmOptions.Discovery)
+		setTemplateString(values, "swarm-image", swarmOptions.Image)
+		setTemplateString(values, "swarm-strategy", swarmOptions.Strategy)
+		setTemplateString(values, "swarm-host", swarmOptions.Host)
+		setTemplateString(values, "swarm-addr", swarmOptions.Address)
+		setTemplateList(values, "swarm-opt", swarmOptions.ArbitraryFlags)
+	}
+
+	return values
+}
+
+func setTemplateString(values map[string]interface{}, name, value string) {
+	if value != "" {
+		values[name] = value
+	}
+}
+
+func setTemplateList(values map[string]interface{}, name string, list []string) {
+	if len(list) == 0 {
+		return
+	}
+	items := []interface{}{}
+	for _, item := range list {
+		items = append(items, item)
+	}
+	values[name] = items
+}
+
+// getTemplateLabels returns the labels of the template, along with the
+// labels which were set explicitly.
+func getTemplateLabels(t *libmachine.Template, labels map[string]string) map[string]string {
+	merged := make(map[string]string)
+	if t.HostOptions != nil {
+		for key, value := range t.HostOptions.Labels {
+			merged[key] = value
+		}
+	}
+	for key, value := range labels {
+		merged[key] = value
+	}
+	return merged
+}
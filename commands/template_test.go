@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestTemplate() *libmachine.Template {
+	return &libmachine.Template{
+		Name:          "web",
+		DriverName:    "none",
+		DriverOptions: map[string]interface{}{"url": "tcp://1.2.3.4:2376"},
+		HostOptions: &libmachine.HostOptions{
+			EngineOptions: &engine.EngineOptions{
+				StorageDriver: "aufs",
+				Env:           []string{"A=1"},
+			},
+			SwarmOptions: &swarm.SwarmOptions{
+				IsSwarm:   true,
+				Discovery: "token://1234",
+			},
+			Labels: map[string]string{"team": "web", "env": "dev"},
+		},
+	}
+}
+
+func TestGetTemplateValues(t *testing.T) {
+	values := getTemplateValues(getTestTemplate(), "none")
+
+	assert.Equal(t, map[string]interface{}{
+		"url":                   "tcp://1.2.3.4:2376",
+		"engine-storage-driver": "aufs",
+		"engine-env":            []interface{}{"A=1"},
+		"swarm":                 true,
+		"swarm-master":          false,
+		"swarm-discovery":       "token://1234",
+	}, values)
+
+	if _, err := getCreateOptions("none", values, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetTemplateValuesOtherDriver(t *testing.T) {
+	values := getTemplateValues(getTestTemplate(), "virtualbox")
+
+	_, ok := values["url"]
+	assert.False(t, ok)
+	assert.Equal(t, "aufs", values["engine-storage-driver"])
+}
+
+func TestGetTemplateLabels(t *testing.T) {
+	labels := getTemplateLabels(getTestTemplate(), map[string]string{"env": "prod"})
+	assert.Equal(t, map[string]string{"team": "web", "env": "prod"}, labels)
+}
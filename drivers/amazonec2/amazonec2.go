@@ -38,8 +38,8 @@ type Driver struct {
 	*drivers.BaseDriver
 	Id                  string
 	AccessKey           string
-	SecretKey           string
-	SessionToken        string
+	SecretKey           string `secret:"true"`
+	SessionToken        string `secret:"true"`
 	Region              string
 	AMI                 string
 	SSHKeyID            int
@@ -67,6 +67,8 @@ func init() {
 	drivers.Register(driverName, &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "Amazon Web Services EC2 instances",
+		RequiredFlags:  []string{"amazonec2-access-key", "amazonec2-secret-key"},
 	})
 }
 
@@ -173,7 +175,10 @@ func GetCreateFlags() []cli.Flag {
 }
 
 func NewDriver(machineName string, storePath string, caCert string, privateKey string) (drivers.Driver, error) {
-	id := generateId()
+	id, err := generateId()
+	if err != nil {
+		return nil, err
+	}
 	inner := drivers.NewBaseDriver(machineName, storePath, caCert, privateKey)
 	return &Driver{
 		Id:         id,
@@ -321,7 +326,7 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	log.Infof("Launching instance...")
+	d.Progress().Infof("Launching instance...")
 
 	if err := d.createKeyPair(); err != nil {
 		return fmt.Errorf("unable to create key pair: %s", err)
@@ -347,7 +352,7 @@ func (d *Driver) Create() error {
 		}
 		var instanceId string
 		var spotInstanceRequestStatus string
-		log.Info("Waiting for spot instance...")
+		d.Progress().Info("Waiting for spot instance...")
 		// check until fulfilled
 		for instanceId == "" {
 			time.Sleep(time.Second * 5)
@@ -400,6 +405,40 @@ func (d *Driver) Create() error {
 	return nil
 }
 
+func (d *Driver) PrepareRename(newName string) error {
+	return nil
+}
+
+// Rename updates the Name tag of the instance.  The key pair keeps the
+// old name.
+func (d *Driver) Rename(oldName string) error {
+	tags := map[string]string{
+		"Name": d.MachineName,
+	}
+
+	return d.getClient().CreateTags(d.InstanceId, tags)
+}
+
+// Resize changes the instance type of the instance.
+func (d *Driver) Resize(opts drivers.ResizeOptions) error {
+	if opts.CPU != 0 || opts.Memory != 0 || opts.DiskSize != 0 {
+		return fmt.Errorf("EC2 instances are resized by instance type; set the size instead")
+	}
+
+	if opts.Size == "" || opts.Size == d.InstanceType {
+		return nil
+	}
+
+	d.Progress().Infof("Changing the instance type of %s to %s...", d.InstanceId, opts.Size)
+	if err := d.getClient().ModifyInstanceType(d.InstanceId, opts.Size); err != nil {
+		return err
+	}
+
+	d.InstanceType = opts.Size
+
+	return nil
+}
+
 func (d *Driver) GetURL() (string, error) {
 	ip, err := d.GetIP()
 	if err != nil {
@@ -712,14 +751,14 @@ func (d *Driver) deleteKeyPair() error {
 	return nil
 }
 
-func generateId() string {
+func generateId() (string, error) {
 	rb := make([]byte, 10)
 	_, err := rand.Read(rb)
 	if err != nil {
-		log.Fatalf("unable to generate id: %s", err)
+		return "", fmt.Errorf("unable to generate id: %s", err)
 	}
 
 	h := md5.New()
 	io.WriteString(h, string(rb))
-	return fmt.Sprintf("%x", h.Sum(nil))
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
@@ -1,7 +1,9 @@
 package amz
 
 type Auth struct {
-	AccessKey, SecretKey, SessionToken string
+	AccessKey    string
+	SecretKey    string `secret:"true"`
+	SessionToken string `secret:"true"`
 }
 
 func GetAuth(accessKey, secretKey, sessionToken string) Auth {
@@ -636,6 +636,22 @@ func (e *EC2) StopInstance(instanceId string, force bool) error {
 	return nil
 }
 
+// ModifyInstanceType changes the type of a stopped instance.
+func (e *EC2) ModifyInstanceType(instanceId, instanceType string) error {
+	v := url.Values{}
+	v.Set("Action", "ModifyInstanceAttribute")
+	v.Set("InstanceId", instanceId)
+	v.Set("InstanceType.Value", instanceType)
+
+	resp, err := e.awsApiCall(v)
+	if err != nil {
+		return newAwsApiCallError(err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
 func (e *EC2) TerminateInstance(instanceId string) error {
 	if _, err := e.performInstanceAction(instanceId, "TerminateInstances", nil); err != nil {
 		return err
@@ -25,7 +25,7 @@ type Driver struct {
 	PublishSettingsFilePath string
 	Location                string
 	Size                    string
-	UserPassword            string
+	UserPassword            string `secret:"true"`
 	Image                   string
 	DockerPort              int
 	DockerSwarmMasterPort   int
@@ -35,6 +35,7 @@ func init() {
 	drivers.Register("azure", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "Microsoft Azure virtual machines",
 	})
 }
 
@@ -198,7 +199,7 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	log.Info("Creating Azure machine...")
+	d.Progress().Info("Creating Azure machine...")
 	vmConfig, err := vmClient.CreateAzureVMConfiguration(d.MachineName, d.Size, d.Image, d.Location)
 	if err != nil {
 		return err
@@ -272,7 +273,7 @@ func (d *Driver) Start() error {
 	if vmState, err := d.GetState(); err != nil {
 		return err
 	} else if vmState == state.Running || vmState == state.Starting {
-		log.Infof("Host is already running or starting")
+		d.Progress().Infof("Host is already running or starting")
 		return nil
 	}
 
@@ -295,7 +296,7 @@ func (d *Driver) Stop() error {
 	if vmState, err := d.GetState(); err != nil {
 		return err
 	} else if vmState == state.Stopped {
-		log.Infof("Host is already stopped")
+		d.Progress().Infof("Host is already stopped")
 		return nil
 	}
 
@@ -353,7 +354,7 @@ func (d *Driver) Kill() error {
 	if vmState, err := d.GetState(); err != nil {
 		return err
 	} else if vmState == state.Stopped {
-		log.Infof("Host is already stopped")
+		d.Progress().Infof("Host is already stopped")
 		return nil
 	}
 
@@ -1,6 +1,10 @@
 package drivers
 
-import "path/filepath"
+import (
+	"path/filepath"
+
+	"github.com/docker/machine/progress"
+)
 
 // BaseDriver - Embed this struct into drivers to provide the common set
 // of fields and functions.
@@ -15,6 +19,9 @@ type BaseDriver struct {
 	SwarmMaster    bool
 	SwarmHost      string
 	SwarmDiscovery string
+
+	// progressSink receives the progress of the operations of the driver
+	progressSink progress.Sink
 }
 
 // NewBaseDriver - Get an instance of a BaseDriver
@@ -57,6 +64,11 @@ func (d *BaseDriver) GetMachineName() string {
 	return d.MachineName
 }
 
+// SetMachineName - Used when the machine is renamed
+func (d *BaseDriver) SetMachineName(name string) {
+	d.MachineName = name
+}
+
 // GetSSHPort -
 func (d *BaseDriver) GetSSHPort() (int, error) {
 	if d.SSHPort == 0 {
@@ -74,3 +86,13 @@ func (d *BaseDriver) GetSSHUsername() string {
 
 	return d.SSHUser
 }
+
+// SetProgressSink - Set the sink the progress of the operations is published to
+func (d *BaseDriver) SetProgressSink(sink progress.Sink) {
+	d.progressSink = sink
+}
+
+// Progress - Get the reporter of the progress of the operations
+func (d *BaseDriver) Progress() progress.Reporter {
+	return progress.NewReporter(d.progressSink, d.MachineName)
+}
@@ -0,0 +1,164 @@
+package drivers
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Capabilities are the optional interfaces a driver can implement, on top
+// of Driver, for the features which not every provider offers.
+const (
+	CapabilityCancel   = "cancel"
+	CapabilityPause    = "pause"
+	CapabilityRename   = "rename"
+	CapabilityResize   = "resize"
+	CapabilitySnapshot = "snapshot"
+	CapabilitySuspend  = "suspend"
+)
+
+// Pauser is implemented by the drivers which can pause a machine, keeping
+// its memory, and resume it where it was.  A paused machine is in the
+// state.Paused state.
+type Pauser interface {
+	Pause() error
+	Resume() error
+}
+
+// Suspender is implemented by the drivers which can suspend a machine,
+// saving its memory to disk, and resume it where it was.  A suspended
+// machine is in the state.Saved state.  Drivers which implement Pauser as
+// well resume machines from both states.
+type Suspender interface {
+	Suspend() error
+	Resume() error
+}
+
+// Snapshot is a saved state of the disks of a machine.  ID is empty if the
+// provider only names snapshots.
+type Snapshot struct {
+	Name string
+	ID   string
+}
+
+// Snapshotter is implemented by the drivers which can take snapshots of a
+// machine and restore it to one.  Restoring a snapshot may leave the
+// machine stopped, or saved, in which case it is started by the caller.
+type Snapshotter interface {
+	TakeSnapshot(name string) error
+	ListSnapshots() ([]Snapshot, error)
+	RestoreSnapshot(name string) error
+	RemoveSnapshot(name string) error
+}
+
+// ErrSnapshotDoesNotExist is returned by Snapshotters for a snapshot the
+// machine does not have.
+type ErrSnapshotDoesNotExist struct {
+	Name string
+}
+
+func (e ErrSnapshotDoesNotExist) Error() string {
+	return fmt.Sprintf("Snapshot %s does not exist", e.Name)
+}
+
+// ErrSnapshotExists is returned by Snapshotters for a snapshot taken under
+// the name of an existing one.
+type ErrSnapshotExists struct {
+	Name string
+}
+
+func (e ErrSnapshotExists) Error() string {
+	return fmt.Sprintf("Snapshot %s already exists", e.Name)
+}
+
+// FindSnapshot returns the snapshot "name" in a list of snapshots.
+func FindSnapshot(snapshots []Snapshot, name string) (Snapshot, bool) {
+	for _, snapshot := range snapshots {
+		if snapshot.Name == name {
+			return snapshot, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// ResizeOptions are the resources a machine is resized to.  CPU, Memory
+// and DiskSize, in MB, are for the providers which set the resources one
+// by one; Size is the size, or instance type, for the providers which offer
+// a set of sizes.  The options which are zero are left as they are.
+type ResizeOptions struct {
+	CPU      int
+	Memory   int
+	DiskSize int
+	Size     string
+}
+
+// Resizer is implemented by the drivers which can change the resources of
+// an existing machine.  Resize is called while the machine is stopped, and
+// returns an error for the options the provider does not support.
+type Resizer interface {
+	Resize(opts ResizeOptions) error
+}
+
+// capabilityReporter is implemented by the drivers which forward their
+// calls to another driver, like RPCClientDriver, and so implement every
+// capability interface while offering only those of that driver.
+type capabilityReporter interface {
+	capabilities() []string
+}
+
+// Capabilities returns the capabilities of a driver, sorted by name.
+func Capabilities(d Driver) []string {
+	capabilities := []string{}
+	if d == nil {
+		return capabilities
+	}
+
+	if reporter, ok := d.(capabilityReporter); ok {
+		capabilities = append(capabilities, reporter.capabilities()...)
+		sort.Strings(capabilities)
+		return capabilities
+	}
+
+	if _, ok := d.(ContextDriver); ok {
+		capabilities = append(capabilities, CapabilityCancel)
+	}
+	if _, ok := d.(Pauser); ok {
+		capabilities = append(capabilities, CapabilityPause)
+	}
+	if _, ok := d.(Renamer); ok {
+		capabilities = append(capabilities, CapabilityRename)
+	}
+	if _, ok := d.(Resizer); ok {
+		capabilities = append(capabilities, CapabilityResize)
+	}
+	if _, ok := d.(Snapshotter); ok {
+		capabilities = append(capabilities, CapabilitySnapshot)
+	}
+	if _, ok := d.(Suspender); ok {
+		capabilities = append(capabilities, CapabilitySuspend)
+	}
+
+	sort.Strings(capabilities)
+	return capabilities
+}
+
+// HasCapability returns whether a driver offers a capability.  The
+// capability interfaces are checked through it rather than with a type
+// assertion alone, which would succeed for every plugin driver.
+func HasCapability(d Driver, capability string) bool {
+	for _, c := range Capabilities(d) {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCapabilitiesForDriver returns the capabilities of the driver "name".
+func GetCapabilitiesForDriver(name string) ([]string, error) {
+	d, err := NewDriver(name, "", "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer CloseDriver(d)
+	return Capabilities(d), nil
+}
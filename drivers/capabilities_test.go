@@ -0,0 +1,33 @@
+package drivers
+
+import (
+	"reflect"
+	"testing"
+)
+
+type capabilitiesTestDriver struct {
+	Driver
+}
+
+type capabilitiesTestResizer struct {
+	capabilitiesTestDriver
+}
+
+func (d *capabilitiesTestResizer) Pause() error                    { return nil }
+func (d *capabilitiesTestResizer) Resume() error                   { return nil }
+func (d *capabilitiesTestResizer) Resize(opts ResizeOptions) error { return nil }
+
+func TestCapabilities(t *testing.T) {
+	if capabilities := Capabilities(&capabilitiesTestDriver{}); len(capabilities) != 0 {
+		t.Fatalf("expected no capabilities, got %v", capabilities)
+	}
+
+	expected := []string{CapabilityPause, CapabilityResize}
+	if capabilities := Capabilities(&capabilitiesTestResizer{}); !reflect.DeepEqual(capabilities, expected) {
+		t.Fatalf("expected %v, got %v", expected, capabilities)
+	}
+
+	if capabilities := Capabilities(nil); len(capabilities) != 0 {
+		t.Fatalf("expected no capabilities, got %v", capabilities)
+	}
+}
@@ -0,0 +1,70 @@
+package drivers
+
+import (
+	"golang.org/x/net/context"
+)
+
+// ContextDriver is implemented by the drivers whose operations can be
+// cancelled, or given a deadline, with a context.  The operations return
+// the error of the context once it is done.
+type ContextDriver interface {
+	Driver
+
+	CreateContext(ctx context.Context) error
+	KillContext(ctx context.Context) error
+	RemoveContext(ctx context.Context) error
+	RestartContext(ctx context.Context) error
+	StartContext(ctx context.Context) error
+	StopContext(ctx context.Context) error
+}
+
+// NewContextDriver returns d as a ContextDriver.  Drivers which do not
+// implement it are adapted: their operations are not started once the
+// context is done, but run to completion once started, as they can not
+// be interrupted half way without leaving resources behind.  The error of
+// the context is returned if it was done in the meantime, so that callers
+// stop before the next step.
+func NewContextDriver(d Driver) ContextDriver {
+	if cd, ok := d.(ContextDriver); ok {
+		return cd
+	}
+	return &contextDriverAdapter{d}
+}
+
+type contextDriverAdapter struct {
+	Driver
+}
+
+func (a *contextDriverAdapter) CreateContext(ctx context.Context) error {
+	return runWithContext(ctx, a.Create)
+}
+
+func (a *contextDriverAdapter) KillContext(ctx context.Context) error {
+	return runWithContext(ctx, a.Kill)
+}
+
+func (a *contextDriverAdapter) RemoveContext(ctx context.Context) error {
+	return runWithContext(ctx, a.Remove)
+}
+
+func (a *contextDriverAdapter) RestartContext(ctx context.Context) error {
+	return runWithContext(ctx, a.Restart)
+}
+
+func (a *contextDriverAdapter) StartContext(ctx context.Context) error {
+	return runWithContext(ctx, a.Start)
+}
+
+func (a *contextDriverAdapter) StopContext(ctx context.Context) error {
+	return runWithContext(ctx, a.Stop)
+}
+
+func runWithContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,58 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+type contextTestDriver struct {
+	Driver
+	created bool
+}
+
+func (d *contextTestDriver) Create() error {
+	d.created = true
+	return nil
+}
+
+func TestContextDriverAdapter(t *testing.T) {
+	d := &contextTestDriver{}
+	cd := NewContextDriver(d)
+
+	if err := cd.CreateContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !d.created {
+		t.Fatal("expected the driver to be created")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	d.created = false
+	if err := cd.CreateContext(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled; received %v", err)
+	}
+	if d.created {
+		t.Fatal("expected the driver not to be created once the context is done")
+	}
+}
+
+func TestRunWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := runWithContext(ctx, func() error {
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected the operation to finish and report the cancellation; received %v", err)
+	}
+
+	fnErr := errors.New("failed")
+	if err := runWithContext(context.Background(), func() error { return fnErr }); err != fnErr {
+		t.Fatalf("expected the error of the operation; received %v", err)
+	}
+}
@@ -12,11 +12,13 @@ import (
 	"github.com/docker/machine/log"
 	"github.com/docker/machine/ssh"
 	"github.com/docker/machine/state"
+	"github.com/docker/machine/utils"
+	"golang.org/x/net/context"
 )
 
 type Driver struct {
 	*drivers.BaseDriver
-	AccessToken       string
+	AccessToken       string `secret:"true"`
 	DropletID         int
 	DropletName       string
 	Image             string
@@ -32,6 +34,8 @@ func init() {
 	drivers.Register("digitalocean", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "DigitalOcean droplets",
+		RequiredFlags:  []string{"digitalocean-access-token"},
 	})
 }
 
@@ -136,7 +140,17 @@ func (d *Driver) PreCreateCheck() error {
 }
 
 func (d *Driver) Create() error {
-	log.Infof("Creating SSH key...")
+	return d.CreateContext(context.Background())
+}
+
+// CreateContext creates the droplet, and stops waiting for its IP address
+// once ctx is done.  The droplet is kept then, so that it can be removed.
+func (d *Driver) CreateContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	d.Progress().Infof("Creating SSH key...")
 
 	key, err := d.createSSHKey()
 	if err != nil {
@@ -145,7 +159,7 @@ func (d *Driver) Create() error {
 
 	d.SSHKeyID = key.ID
 
-	log.Infof("Creating Digital Ocean droplet...")
+	d.Progress().Infof("Creating Digital Ocean droplet...")
 
 	client := d.getClient()
 
@@ -182,7 +196,11 @@ func (d *Driver) Create() error {
 			break
 		}
 
-		time.Sleep(1 * time.Second)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+		}
 	}
 
 	log.Debugf("Created droplet ID %d, IP address %s",
@@ -247,27 +265,51 @@ func (d *Driver) GetState() (state.State, error) {
 }
 
 func (d *Driver) Start() error {
+	return d.StartContext(context.Background())
+}
+
+func (d *Driver) StartContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, _, err := d.getClient().DropletActions.PowerOn(d.DropletID)
 	return err
 }
 
 func (d *Driver) Stop() error {
+	return d.StopContext(context.Background())
+}
+
+func (d *Driver) StopContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, _, err := d.getClient().DropletActions.Shutdown(d.DropletID)
 	return err
 }
 
 func (d *Driver) Remove() error {
+	return d.RemoveContext(context.Background())
+}
+
+// RemoveContext removes the SSH key and the droplet.  Once the removal
+// has started, it is not interrupted by ctx, so that nothing is left
+// behind.
+func (d *Driver) RemoveContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	client := d.getClient()
 	if resp, err := client.Keys.DeleteByID(d.SSHKeyID); err != nil {
 		if resp.StatusCode == 404 {
-			log.Infof("Digital Ocean SSH key doesn't exist, assuming it is already deleted")
+			d.Progress().Infof("Digital Ocean SSH key doesn't exist, assuming it is already deleted")
 		} else {
 			return err
 		}
 	}
 	if resp, err := client.Droplets.Delete(d.DropletID); err != nil {
 		if resp.StatusCode == 404 {
-			log.Infof("Digital Ocean droplet doesn't exist, assuming it is already deleted")
+			d.Progress().Infof("Digital Ocean droplet doesn't exist, assuming it is already deleted")
 		} else {
 			return err
 		}
@@ -276,15 +318,69 @@ func (d *Driver) Remove() error {
 }
 
 func (d *Driver) Restart() error {
+	return d.RestartContext(context.Background())
+}
+
+func (d *Driver) RestartContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, _, err := d.getClient().DropletActions.Reboot(d.DropletID)
 	return err
 }
 
 func (d *Driver) Kill() error {
+	return d.KillContext(context.Background())
+}
+
+func (d *Driver) KillContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, _, err := d.getClient().DropletActions.PowerOff(d.DropletID)
 	return err
 }
 
+// Resize changes the size of the droplet.  Its disk is kept, so that it
+// can be resized back to a smaller size.
+func (d *Driver) Resize(opts drivers.ResizeOptions) error {
+	if opts.CPU != 0 || opts.Memory != 0 || opts.DiskSize != 0 {
+		return fmt.Errorf("Droplets are resized by size; set the size instead")
+	}
+
+	if opts.Size == "" || opts.Size == d.Size {
+		return nil
+	}
+
+	client := d.getClient()
+
+	d.Progress().Infof("Resizing droplet %d to %s...", d.DropletID, opts.Size)
+	action, _, err := client.DropletActions.Resize(d.DropletID, opts.Size)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WaitForSpecificOrError(func() (bool, error) {
+		action, _, err := client.DropletActions.Get(d.DropletID, action.ID)
+		if err != nil {
+			return false, err
+		}
+		switch action.Status {
+		case "completed":
+			return true, nil
+		case "errored":
+			return false, fmt.Errorf("Error resizing droplet %d to %s", d.DropletID, opts.Size)
+		}
+		return false, nil
+	}, 120, 5*time.Second); err != nil {
+		return err
+	}
+
+	d.Size = opts.Size
+
+	return nil
+}
+
 func (d *Driver) getClient() *godo.Client {
 	t := &oauth.Transport{
 		Token: &oauth.Token{AccessToken: d.AccessToken},
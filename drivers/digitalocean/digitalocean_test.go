@@ -1 +1,25 @@
 package digitalocean
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers"
+	"golang.org/x/net/context"
+)
+
+func TestCreateContextCancelled(t *testing.T) {
+	d := &Driver{BaseDriver: &drivers.BaseDriver{}}
+	if !drivers.HasCapability(d, drivers.CapabilityCancel) {
+		t.Fatal("expected the driver to be cancellable")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := d.CreateContext(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if d.SSHKeyID != 0 || d.DropletID != 0 {
+		t.Fatal("expected nothing to be created once the context is done")
+	}
+}
@@ -3,7 +3,9 @@ package drivers
 import (
 	"errors"
 	"fmt"
+	"io"
 	"sort"
+	"sync"
 
 	"github.com/codegangsta/cli"
 	"github.com/docker/machine/log"
@@ -81,21 +83,41 @@ type Driver interface {
 	Stop() error
 }
 
+// Renamer is implemented by the drivers which name something at the
+// provider after the machine, e.g. the VM or the instance, and can rename
+// it when the machine is renamed.
+type Renamer interface {
+	// PrepareRename is called before the machine directory is moved,
+	// while the driver still has the old name.
+	PrepareRename(newName string) error
+
+	// Rename is called once the machine directory has been moved and the
+	// driver has been given the new name.
+	Rename(oldName string) error
+}
+
 // RegisteredDriver is used to register a driver with the Register function.
-// It has two attributes:
-// - New: a function that returns a new driver given a path to store host
-//   configuration in
-// - RegisterCreateFlags: a function that takes the FlagSet for
-//   "docker hosts create" and returns an object to pass to SetConfigFromFlags
+// It has the following attributes:
+//   - New: a function that returns a new driver given a path to store host
+//     configuration in
+//   - RegisterCreateFlags: a function that takes the FlagSet for
+//     "docker hosts create" and returns an object to pass to SetConfigFromFlags
+//   - Description: a short description of the driver
+//   - RequiredFlags: the names of the create flags which must be set
 type RegisteredDriver struct {
 	New            func(machineName string, storePath string, caCert string, privateKey string) (Driver, error)
 	GetCreateFlags func() []cli.Flag
+	Description    string
+	RequiredFlags  []string
 }
 
 var ErrHostIsNotRunning = errors.New("host is not running")
 
 var (
-	drivers map[string]*RegisteredDriver
+	// driversMu guards drivers, which gains the plugins as they are looked
+	// up, possibly by operations on several machines at once.
+	driversMu sync.RWMutex
+	drivers   map[string]*RegisteredDriver
 )
 
 func init() {
@@ -104,6 +126,9 @@ func init() {
 
 // Register a driver
 func Register(name string, registeredDriver *RegisteredDriver) error {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
 	if _, exists := drivers[name]; exists {
 		return fmt.Errorf("Name already registered %s", name)
 	}
@@ -112,22 +137,68 @@ func Register(name string, registeredDriver *RegisteredDriver) error {
 	return nil
 }
 
+// getRegisteredDriver returns the driver registered as "name".  Drivers
+// which are not compiled in are looked up as plugin binaries in the PATH.
+func getRegisteredDriver(name string) (*RegisteredDriver, bool) {
+	driversMu.RLock()
+	driver, exists := drivers[name]
+	driversMu.RUnlock()
+	if exists {
+		return driver, true
+	}
+
+	driver, exists = lookupPlugin(name)
+	if !exists {
+		return nil, false
+	}
+
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	// The plugin may have been looked up concurrently.
+	if registered, ok := drivers[name]; ok {
+		return registered, true
+	}
+	drivers[name] = driver
+	return driver, true
+}
+
+// getRegisteredDrivers returns the drivers registered so far, by name.
+func getRegisteredDrivers() map[string]*RegisteredDriver {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	registered := make(map[string]*RegisteredDriver, len(drivers))
+	for name, driver := range drivers {
+		registered[name] = driver
+	}
+	return registered
+}
+
 // NewDriver creates a new driver of type "name"
 func NewDriver(name string, machineName string, storePath string, caCert string, privateKey string) (Driver, error) {
-	driver, exists := drivers[name]
+	driver, exists := getRegisteredDriver(name)
 	if !exists {
 		return nil, fmt.Errorf("hosts: Unknown driver %q", name)
 	}
 	return driver.New(machineName, storePath, caCert, privateKey)
 }
 
+// CloseDriver releases what a driver holds on to once it is no longer
+// used, such as the process of a driver plugin.
+func CloseDriver(d Driver) error {
+	if c, ok := d.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // GetCreateFlags runs GetCreateFlags for all of the drivers and
 // returns their return values indexed by the driver name
 func GetCreateFlags() []cli.Flag {
 	flags := []cli.Flag{}
 
-	for driverName := range drivers {
-		driver := drivers[driverName]
+	for _, driver := range getRegisteredDrivers() {
 		for _, f := range driver.GetCreateFlags() {
 			flags = append(flags, f)
 		}
@@ -139,23 +210,40 @@ func GetCreateFlags() []cli.Flag {
 }
 
 func GetCreateFlagsForDriver(name string) ([]cli.Flag, error) {
+	driver, exists := getRegisteredDriver(name)
+	if !exists {
+		return nil, fmt.Errorf("Driver %s not found", name)
+	}
 
-	for driverName := range drivers {
-		if name == driverName {
-			driver := drivers[driverName]
-			flags := driver.GetCreateFlags()
-			sort.Sort(ByFlagName(flags))
-			return flags, nil
-		}
+	flags := driver.GetCreateFlags()
+	sort.Sort(ByFlagName(flags))
+	return flags, nil
+}
+
+// GetDriverDescription returns the description of the driver "name".
+func GetDriverDescription(name string) (string, error) {
+	driver, exists := getRegisteredDriver(name)
+	if !exists {
+		return "", fmt.Errorf("Driver %s not found", name)
 	}
+	return driver.Description, nil
+}
 
-	return nil, fmt.Errorf("Driver %s not found", name)
+// GetRequiredFlagsForDriver returns the names of the create flags which
+// must be set for the driver "name".
+func GetRequiredFlagsForDriver(name string) ([]string, error) {
+	driver, exists := getRegisteredDriver(name)
+	if !exists {
+		return nil, fmt.Errorf("Driver %s not found", name)
+	}
+	return driver.RequiredFlags, nil
 }
 
 // GetDriverNames returns a slice of all registered driver names
 func GetDriverNames() []string {
-	names := make([]string, 0, len(drivers))
-	for k := range drivers {
+	registered := getRegisteredDrivers()
+	names := make([]string, 0, len(registered))
+	for k := range registered {
 		names = append(names, k)
 	}
 	sort.Strings(names)
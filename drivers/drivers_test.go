@@ -1,6 +1,7 @@
 package drivers
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/codegangsta/cli"
@@ -75,3 +76,25 @@ func TestGetCreateFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistryConcurrentAccess(t *testing.T) {
+	done := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			Register(fmt.Sprintf("concurrent-%d", i), &RegisteredDriver{
+				GetCreateFlags: func() []cli.Flag { return []cli.Flag{} },
+			})
+			getRegisteredDriver("missing")
+			GetDriverNames()
+			GetCreateFlags()
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+	if _, exists := getRegisteredDriver("concurrent-0"); !exists {
+		t.Fatal("expected the driver to be registered")
+	}
+}
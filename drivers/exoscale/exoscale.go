@@ -20,7 +20,7 @@ type Driver struct {
 	*drivers.BaseDriver
 	URL              string
 	ApiKey           string
-	ApiSecretKey     string
+	ApiSecretKey     string `secret:"true"`
 	InstanceProfile  string
 	DiskSize         int
 	Image            string
@@ -35,6 +35,8 @@ func init() {
 	drivers.Register("exoscale", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "Exoscale virtual machines",
+		RequiredFlags:  []string{"exoscale-api-key", "exoscale-api-secret-key"},
 	})
 }
 
@@ -224,7 +226,7 @@ func (d *Driver) createDefaultSecurityGroup(client *egoscale.Client, group strin
 }
 
 func (d *Driver) Create() error {
-	log.Infof("Querying exoscale for the requested parameters...")
+	d.Progress().Infof("Querying exoscale for the requested parameters...")
 	client := egoscale.NewClient(d.URL, d.ApiKey, d.ApiSecretKey)
 	topology, err := client.GetTopology()
 	if err != nil {
@@ -265,7 +267,7 @@ func (d *Driver) Create() error {
 	for idx, group := range securityGroups {
 		sg, ok := topology.SecurityGroups[group]
 		if !ok {
-			log.Infof("Security group %v does not exist, create it",
+			d.Progress().Infof("Security group %v does not exist, create it",
 				group)
 			sg, err = d.createDefaultSecurityGroup(client, group)
 			if err != nil {
@@ -276,7 +278,7 @@ func (d *Driver) Create() error {
 		sgs[idx] = sg
 	}
 
-	log.Infof("Generate an SSH keypair...")
+	d.Progress().Infof("Generate an SSH keypair...")
 	keypairName := fmt.Sprintf("docker-machine-%s", d.MachineName)
 	kpresp, err := client.CreateKeypair(keypairName)
 	if err != nil {
@@ -288,7 +290,7 @@ func (d *Driver) Create() error {
 	}
 	d.KeyPair = keypairName
 
-	log.Infof("Spawn exoscale host...")
+	d.Progress().Infof("Spawn exoscale host...")
 
 	userdata, err := d.getCloudInit()
 	if err != nil {
@@ -328,7 +330,7 @@ func (d *Driver) Start() error {
 		return err
 	}
 	if vmstate == state.Running || vmstate == state.Starting {
-		log.Infof("Host is already running or starting")
+		d.Progress().Infof("Host is already running or starting")
 		return nil
 	}
 
@@ -349,7 +351,7 @@ func (d *Driver) Stop() error {
 		return err
 	}
 	if vmstate == state.Stopped {
-		log.Infof("Host is already stopped")
+		d.Progress().Infof("Host is already stopped")
 		return nil
 	}
 
@@ -425,7 +427,7 @@ func (d *Driver) jobIsDone(client *egoscale.Client, jobid string) (bool, error)
 }
 
 func (d *Driver) waitForJob(client *egoscale.Client, jobid string) error {
-	log.Infof("Waiting for job to complete...")
+	d.Progress().Infof("Waiting for job to complete...")
 	return utils.WaitForSpecificOrError(func() (bool, error) {
 		return d.jobIsDone(client, jobid)
 	}, 60, 2*time.Second)
@@ -0,0 +1,177 @@
+package drivers
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+// FlagName returns the name of a flag, without its aliases.
+func FlagName(f cli.Flag) string {
+	name, _ := getFlagFields(f)
+	return strings.TrimSpace(strings.Split(name, ",")[0])
+}
+
+// FlagEnvVar returns the environment variables the flag takes its value
+// from, separated by commas.
+func FlagEnvVar(f cli.Flag) string {
+	_, envVar := getFlagFields(f)
+	return envVar
+}
+
+// FlagEnvVarSet returns whether an environment variable the flag takes its
+// value from is set.
+func FlagEnvVarSet(f cli.Flag) bool {
+	envVar := FlagEnvVar(f)
+	if envVar == "" {
+		return false
+	}
+	for _, name := range strings.Split(envVar, ",") {
+		if os.Getenv(strings.TrimSpace(name)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func getFlagFields(f cli.Flag) (string, string) {
+	switch f := f.(type) {
+	case cli.StringFlag:
+		return f.Name, f.EnvVar
+	case cli.StringSliceFlag:
+		return f.Name, f.EnvVar
+	case cli.IntFlag:
+		return f.Name, f.EnvVar
+	case cli.BoolFlag:
+		return f.Name, f.EnvVar
+	case cli.BoolTFlag:
+		return f.Name, f.EnvVar
+	case cli.DurationFlag:
+		return f.Name, f.EnvVar
+	case cli.Float64Flag:
+		return f.Name, f.EnvVar
+	}
+	return "", ""
+}
+
+// GetCreateFlagValues returns the values of the create flags of the driver,
+// as found in its config.  Drivers keep the value of a flag in the field of
+// the same name, without the driver prefix, e.g. "amazonec2-instance-type"
+// in InstanceType; the flags without such a field are left out.  So are the
+// credentials, and the flags which are empty.
+func GetCreateFlagValues(d Driver) (map[string]interface{}, error) {
+	flags, err := GetCreateFlagsForDriver(d.DriverName())
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]bool)
+	if _, err := utils.CopySecrets(d, func(value string) (string, error) {
+		secrets[value] = true
+		return value, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	for name, value := range config {
+		fields[normalizeFieldName(name)] = value
+	}
+
+	values := make(map[string]interface{})
+	for _, f := range flags {
+		name := FlagName(f)
+
+		value, ok := fields[normalizeFieldName(strings.TrimPrefix(name, d.DriverName()+"-"))]
+		if !ok || !flagAccepts(f, value, secrets) {
+			log.Debugf("Not capturing the value of flag %s", name)
+			continue
+		}
+
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// GetSecretCreateFlags returns the create flags of the driver "name" whose
+// values are kept in a credential field of the driver, matched by name as
+// in GetCreateFlagValues.
+func GetSecretCreateFlags(name string) (map[string]bool, error) {
+	flags, err := GetCreateFlagsForDriver(name)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := NewDriver(name, "", "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer CloseDriver(d)
+
+	fields := make(map[string]bool)
+	for _, field := range utils.SecretFields(d) {
+		fields[normalizeFieldName(field)] = true
+	}
+
+	secret := make(map[string]bool)
+	for _, f := range flags {
+		flagName := FlagName(f)
+		if fields[normalizeFieldName(strings.TrimPrefix(flagName, name+"-"))] {
+			secret[flagName] = true
+		}
+	}
+
+	return secret, nil
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
+
+// flagAccepts returns whether the value of a field can be the value of the
+// flag, and is worth keeping.
+func flagAccepts(f cli.Flag, value interface{}, secrets map[string]bool) bool {
+	switch f.(type) {
+	case cli.StringFlag:
+		switch v := value.(type) {
+		case string:
+			return v != "" && !secrets[v]
+		case float64:
+			return true
+		}
+	case cli.IntFlag, cli.Float64Flag:
+		_, ok := value.(float64)
+		return ok
+	case cli.BoolFlag, cli.BoolTFlag:
+		_, ok := value.(bool)
+		return ok
+	case cli.StringSliceFlag:
+		list, ok := value.([]interface{})
+		if !ok || len(list) == 0 {
+			return false
+		}
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok || secrets[s] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package drivers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codegangsta/cli"
+)
+
+type flagValuesTestDriver struct {
+	*pluginTestDriver
+	InstanceType string
+	DiskSize     int
+	Tags         []string
+	Private      bool
+	SecretKey    string `secret:"true"`
+}
+
+func (d *flagValuesTestDriver) DriverName() string { return "flagvaluestest" }
+
+func TestGetCreateFlagValues(t *testing.T) {
+	Register("flagvaluestest", &RegisteredDriver{
+		New: func(machineName string, storePath string, caCert string, privateKey string) (Driver, error) {
+			return nil, nil
+		},
+		GetCreateFlags: func() []cli.Flag {
+			return []cli.Flag{
+				cli.StringFlag{Name: "flagvaluestest-instance-type"},
+				cli.IntFlag{Name: "flagvaluestest-disk-size"},
+				cli.StringSliceFlag{Name: "flagvaluestest-tags", Value: &cli.StringSlice{}},
+				cli.BoolFlag{Name: "flagvaluestest-private"},
+				cli.StringFlag{Name: "flagvaluestest-secret-key"},
+				cli.StringFlag{Name: "flagvaluestest-url"},
+				cli.StringFlag{Name: "flagvaluestest-region"},
+			}
+		},
+	})
+
+	d := &flagValuesTestDriver{
+		pluginTestDriver: &pluginTestDriver{BaseDriver: NewBaseDriver("test", "", "", "")},
+		InstanceType:     "large",
+		DiskSize:         20,
+		Tags:             []string{"a"},
+		Private:          true,
+		SecretKey:        "s3cr3t",
+	}
+
+	values, err := GetCreateFlagValues(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"flagvaluestest-instance-type": "large",
+		"flagvaluestest-disk-size":     float64(20),
+		"flagvaluestest-tags":          []interface{}{"a"},
+		"flagvaluestest-private":       true,
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected %v; received %v", expected, values)
+	}
+}
+
+func TestFlagName(t *testing.T) {
+	if name := FlagName(cli.StringFlag{Name: "driver, d"}); name != "driver" {
+		t.Fatalf("expected driver; received %s", name)
+	}
+}
@@ -27,6 +27,8 @@ func init() {
 	drivers.Register("generic", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "An existing machine, provisioned over SSH",
+		RequiredFlags:  []string{"generic-ip-address"},
 	})
 }
 
@@ -96,7 +98,7 @@ func (d *Driver) PreCreateCheck() error {
 }
 
 func (d *Driver) Create() error {
-	log.Infof("Importing SSH key...")
+	d.Progress().Infof("Importing SSH key...")
 
 	if err := utils.CopyFile(d.SSHKey, d.GetSSHKeyPath()); err != nil {
 		return fmt.Errorf("unable to copy ssh key: %s", err)
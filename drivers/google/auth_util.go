@@ -25,12 +25,15 @@ const (
 )
 
 func newGCEService(storePath, authTokenPath string) (*raw.Service, error) {
-	client := newOauthClient(storePath, authTokenPath)
+	client, err := newOauthClient(storePath, authTokenPath)
+	if err != nil {
+		return nil, err
+	}
 	service, err := raw.New(client)
 	return service, err
 }
 
-func newOauthClient(storePath, authTokenPath string) *http.Client {
+func newOauthClient(storePath, authTokenPath string) (*http.Client, error) {
 	config := &oauth.Config{
 		ClientId:     ClientId,
 		ClientSecret: ClientSecret,
@@ -39,16 +42,19 @@ func newOauthClient(storePath, authTokenPath string) *http.Client {
 		TokenURL:     TokenURL,
 	}
 
-	token := token(storePath, authTokenPath, config)
+	token, err := token(storePath, authTokenPath, config)
+	if err != nil {
+		return nil, err
+	}
 	t := oauth.Transport{
 		Token:     token,
 		Config:    config,
 		Transport: http.DefaultTransport,
 	}
-	return t.Client()
+	return t.Client(), nil
 }
 
-func token(storePath, authTokenPath string, config *oauth.Config) *oauth.Token {
+func token(storePath, authTokenPath string, config *oauth.Config) (*oauth.Token, error) {
 	tokenPath := authTokenPath
 	if authTokenPath == "" {
 		tokenPath = filepath.Join(storePath, "gce_token")
@@ -56,10 +62,13 @@ func token(storePath, authTokenPath string, config *oauth.Config) *oauth.Token {
 	log.Debugf("using auth token: %s", tokenPath)
 	token, err := tokenFromCache(tokenPath)
 	if err != nil {
-		token = tokenFromWeb(config)
+		token, err = tokenFromWeb(config)
+		if err != nil {
+			return nil, err
+		}
 		saveToken(storePath, token)
 	}
-	return token
+	return token, nil
 }
 
 func tokenFromCache(tokenPath string) (*oauth.Token, error) {
@@ -72,7 +81,7 @@ func tokenFromCache(tokenPath string) (*oauth.Token, error) {
 	return token, err
 }
 
-func tokenFromWeb(config *oauth.Config) *oauth.Token {
+func tokenFromWeb(config *oauth.Config) (*oauth.Token, error) {
 	randState := fmt.Sprintf("st%d", time.Now().UnixNano())
 
 	config.RedirectURL = RedirectURI
@@ -92,9 +101,9 @@ func tokenFromWeb(config *oauth.Config) *oauth.Token {
 	}
 	_, err := t.Exchange(code)
 	if err != nil {
-		log.Fatalf("Token exchange error: %v", err)
+		return nil, fmt.Errorf("Token exchange error: %v", err)
 	}
-	return t.Token
+	return t.Token, nil
 }
 
 func getCodeFromStdin() string {
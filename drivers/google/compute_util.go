@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
 	"github.com/docker/machine/ssh"
 	raw "google.golang.org/api/compute/v1"
 )
@@ -29,6 +30,7 @@ type ComputeUtil struct {
 	ipAddress     string
 	SwarmMaster   bool
 	SwarmHost     string
+	progress      progress.Reporter
 }
 
 const (
@@ -61,6 +63,7 @@ func newComputeUtil(driver *Driver) (*ComputeUtil, error) {
 		globalURL:     apiURL + driver.Project + "/global",
 		SwarmMaster:   driver.SwarmMaster,
 		SwarmHost:     driver.SwarmHost,
+		progress:      driver.Progress(),
 	}
 	return &c, nil
 }
@@ -80,12 +83,12 @@ func (c *ComputeUtil) disk() (*raw.Disk, error) {
 
 // deleteDisk deletes the persistent disk.
 func (c *ComputeUtil) deleteDisk() error {
-	log.Infof("Deleting disk.")
+	c.progress.Infof("Deleting disk.")
 	op, err := c.service.Disks.Delete(c.project, c.zone, c.diskName()).Do()
 	if err != nil {
 		return err
 	}
-	log.Infof("Waiting for disk to delete.")
+	c.progress.Infof("Waiting for disk to delete.")
 	return c.waitForRegionalOp(op.Name)
 }
 
@@ -119,7 +122,7 @@ func (c *ComputeUtil) firewallRule() (*raw.Firewall, error) {
 }
 
 func (c *ComputeUtil) createFirewallRule() error {
-	log.Infof("Creating firewall rule.")
+	c.progress.Infof("Creating firewall rule.")
 	allowed := []*raw.FirewallAllowed{
 
 		{
@@ -169,7 +172,7 @@ func (c *ComputeUtil) instance() (*raw.Instance, error) {
 
 // createInstance creates a GCE VM instance.
 func (c *ComputeUtil) createInstance(d *Driver) error {
-	log.Infof("Creating instance.")
+	c.progress.Infof("Creating instance.")
 	// The rule will either exist or be nil in case of an error.
 	if rule, _ := c.firewallRule(); rule == nil {
 		if err := c.createFirewallRule(); err != nil {
@@ -240,7 +243,7 @@ func (c *ComputeUtil) createInstance(d *Driver) error {
 		return err
 	}
 
-	log.Infof("Waiting for Instance...")
+	c.progress.Infof("Waiting for Instance...")
 	if err = c.waitForRegionalOp(op.Name); err != nil {
 		return err
 	}
@@ -255,7 +258,7 @@ func (c *ComputeUtil) createInstance(d *Driver) error {
 	if err != nil {
 		return err
 	}
-	log.Infof("Uploading SSH Key")
+	c.progress.Infof("Uploading SSH Key")
 	op, err = c.service.Instances.SetMetadata(c.project, c.zone, c.instanceName, &raw.Metadata{
 		Fingerprint: instance.Metadata.Fingerprint,
 		Items: []*raw.MetadataItems{
@@ -268,7 +271,7 @@ func (c *ComputeUtil) createInstance(d *Driver) error {
 	if err != nil {
 		return err
 	}
-	log.Infof("Waiting for SSH Key")
+	c.progress.Infof("Waiting for SSH Key")
 	err = c.waitForRegionalOp(op.Name)
 	if err != nil {
 		return err
@@ -279,12 +282,12 @@ func (c *ComputeUtil) createInstance(d *Driver) error {
 
 // deleteInstance deletes the instance, leaving the persistent disk.
 func (c *ComputeUtil) deleteInstance() error {
-	log.Infof("Deleting instance.")
+	c.progress.Infof("Deleting instance.")
 	op, err := c.service.Instances.Delete(c.project, c.zone, c.instanceName).Do()
 	if err != nil {
 		return err
 	}
-	log.Infof("Waiting for instance to delete.")
+	c.progress.Infof("Waiting for instance to delete.")
 	return c.waitForRegionalOp(op.Name)
 }
 
@@ -5,7 +5,6 @@ import (
 
 	"github.com/codegangsta/cli"
 	"github.com/docker/machine/drivers"
-	"github.com/docker/machine/log"
 	"github.com/docker/machine/ssh"
 	"github.com/docker/machine/state"
 )
@@ -28,6 +27,8 @@ func init() {
 	drivers.Register("google", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "Google Compute Engine instances",
+		RequiredFlags:  []string{"google-project"},
 	})
 }
 
@@ -153,14 +154,14 @@ func (d *Driver) Create() error {
 	if err != nil {
 		return err
 	}
-	log.Infof("Creating host...")
+	d.Progress().Infof("Creating host...")
 	// Check if the instance already exists. There will be an error if the instance
 	// doesn't exist, so just check instance for nil.
 	if instance, _ := c.instance(); instance != nil {
 		return fmt.Errorf("Instance %v already exists.", d.MachineName)
 	}
 
-	log.Infof("Generating SSH Key")
+	d.Progress().Infof("Generating SSH Key")
 	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
 		return err
 	}
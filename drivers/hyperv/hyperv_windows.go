@@ -31,6 +31,7 @@ func init() {
 	drivers.Register("hyper-v", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "Microsoft Hyper-V virtual machines",
 	})
 }
 
@@ -148,12 +149,13 @@ func (d *Driver) Create() error {
 
 	var isoURL string
 
-	b2dutils := utils.NewB2dUtils("", "")
+	b2dutils := utils.NewB2dUtils("", "", d.ResolveStorePath("."))
+	b2dutils.Progress = d.Progress()
 
 	if d.boot2DockerLoc == "" {
 		if d.boot2DockerURL != "" {
 			isoURL = d.boot2DockerURL
-			log.Infof("Downloading boot2docker.iso from %s...", isoURL)
+			d.Progress().Infof("Downloading boot2docker.iso from %s...", isoURL)
 			if err := b2dutils.DownloadISO(d.ResolveStorePath("."), "boot2docker.iso", isoURL); err != nil {
 				return err
 			}
@@ -170,7 +172,7 @@ func (d *Driver) Create() error {
 			imgPath := filepath.Join(rootPath, "images")
 			commonIsoPath := filepath.Join(imgPath, "boot2docker.iso")
 			if _, err := os.Stat(commonIsoPath); os.IsNotExist(err) {
-				log.Infof("Downloading boot2docker.iso to %s...", commonIsoPath)
+				d.Progress().Infof("Downloading boot2docker.iso to %s...", commonIsoPath)
 				// just in case boot2docker.iso has been manually deleted
 				if _, err := os.Stat(imgPath); os.IsNotExist(err) {
 					if err := os.Mkdir(imgPath, 0700); err != nil {
@@ -197,13 +199,13 @@ func (d *Driver) Create() error {
 		}
 	}
 
-	log.Infof("Creating SSH key...")
+	d.Progress().Infof("Creating SSH key...")
 
 	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
 		return err
 	}
 
-	log.Infof("Creating VM...")
+	d.Progress().Infof("Creating VM...")
 
 	virtualSwitch, err := d.chooseVirtualSwitch()
 	if err != nil {
@@ -252,7 +254,7 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	log.Infof("Starting  VM...")
+	d.Progress().Infof("Starting  VM...")
 	if err := d.Start(); err != nil {
 		return err
 	}
@@ -272,14 +274,14 @@ func (d *Driver) chooseVirtualSwitch() (string, error) {
 	}
 	switches := parseStdout(stdout)
 	if len(switches) > 0 {
-		log.Infof("Using switch %s", switches[0])
+		d.Progress().Infof("Using switch %s", switches[0])
 		return switches[0], nil
 	}
 	return "", fmt.Errorf("no vswitch found")
 }
 
 func (d *Driver) wait() error {
-	log.Infof("Waiting for host to start...")
+	d.Progress().Infof("Waiting for host to start...")
 	for {
 		ip, _ := d.GetIP()
 		if ip != "" {
@@ -414,7 +416,7 @@ func (d *Driver) generateDiskImage() error {
 
 	d.diskImage = d.ResolveStorePath("disk.vhd")
 	fixed := d.ResolveStorePath("fixed.vhd")
-	log.Infof("Creating VHD")
+	d.Progress().Infof("Creating VHD")
 	command := []string{
 		"New-VHD",
 		"-Path", fmt.Sprintf("'%s'", fixed),
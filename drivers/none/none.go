@@ -21,6 +21,8 @@ func init() {
 	drivers.Register("none", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "An existing Docker daemon, which is not provisioned",
+		RequiredFlags:  []string{"url"},
 	})
 }
 
@@ -68,7 +68,7 @@ func (c *GenericClient) CreateInstance(d *Driver) (string, error) {
 		}
 	}
 
-	log.Info("Creating machine...")
+	d.Progress().Info("Creating machine...")
 
 	server, err := servers.Create(c.Compute, keypairs.CreateOptsExt{
 		serverOpts,
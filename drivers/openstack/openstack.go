@@ -21,7 +21,7 @@ type Driver struct {
 	DomainID         string
 	DomainName       string
 	Username         string
-	Password         string
+	Password         string `secret:"true"`
 	TenantName       string
 	TenantId         string
 	Region           string
@@ -45,6 +45,8 @@ func init() {
 	drivers.Register("openstack", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "OpenStack instances",
+		RequiredFlags:  []string{"openstack-auth-url", "openstack-username", "openstack-password"},
 	})
 }
 
@@ -353,7 +355,7 @@ func (d *Driver) Stop() error {
 
 func (d *Driver) Remove() error {
 	log.WithField("MachineId", d.MachineId).Debug("deleting instance...")
-	log.Info("Deleting OpenStack instance...")
+	d.Progress().Info("Deleting OpenStack instance...")
 	if err := d.initCompute(); err != nil {
 		return err
 	}
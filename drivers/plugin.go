@@ -0,0 +1,374 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"os"
+	"os/exec"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/state"
+)
+
+// PluginBinaryPrefix is prepended to the name of a driver to find the
+// binary of an out-of-process driver plugin in the PATH.
+const PluginBinaryPrefix = "docker-machine-driver-"
+
+// errors which are compared by value by callers and therefore have to be
+// restored on the client side of the RPC channel
+var wellKnownErrors = []error{
+	ErrHostIsNotRunning,
+}
+
+// lookupPlugin returns a RegisteredDriver backed by the plugin binary for
+// the driver "name", if one can be found in the PATH.
+func lookupPlugin(name string) (*RegisteredDriver, bool) {
+	binaryPath, err := exec.LookPath(PluginBinaryPrefix + name)
+	if err != nil {
+		return nil, false
+	}
+
+	log.Debugf("Found plugin for driver %s: %s", name, binaryPath)
+
+	return &RegisteredDriver{
+		Description: fmt.Sprintf("Driver plugin %s", binaryPath),
+		New: func(machineName string, storePath string, caCert string, privateKey string) (Driver, error) {
+			client, err := startPlugin(name, binaryPath)
+			if err != nil {
+				return nil, err
+			}
+
+			args := RPCNewDriverArgs{
+				MachineName: machineName,
+				StorePath:   storePath,
+				CaCert:      caCert,
+				PrivateKey:  privateKey,
+			}
+			if err := client.call("NewDriver", args, &struct{}{}); err != nil {
+				client.Close()
+				return nil, err
+			}
+
+			return client, nil
+		},
+		GetCreateFlags: func() []cli.Flag {
+			client, err := startPlugin(name, binaryPath)
+			if err != nil {
+				log.Errorf("Error starting plugin for driver %s: %s", name, err)
+				return []cli.Flag{}
+			}
+			defer client.Close()
+
+			flags, err := client.getCreateFlags()
+			if err != nil {
+				log.Errorf("Error getting create flags for driver %s: %s", name, err)
+				return []cli.Flag{}
+			}
+			return flags
+		},
+	}, true
+}
+
+func startPlugin(name, binaryPath string) (*RPCClientDriver, error) {
+	cmd := exec.Command(binaryPath)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", PluginEnvKey, PluginEnvVal))
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Error starting plugin binary %s: %s", binaryPath, err)
+	}
+
+	client := newRPCClientDriver(name, &stdioConn{
+		r: stdout,
+		w: stdin,
+	})
+	client.cmd = cmd
+
+	return client, nil
+}
+
+// RPCClientDriver is the Driver used in the machine process for a driver
+// which lives in a plugin binary.  Every call is forwarded to the plugin.
+type RPCClientDriver struct {
+	name   string
+	client *rpc.Client
+	cmd    *exec.Cmd
+
+	// caps are the capabilities of the driver in the plugin, once known
+	caps []string
+}
+
+func newRPCClientDriver(name string, conn io.ReadWriteCloser) *RPCClientDriver {
+	return &RPCClientDriver{
+		name:   name,
+		client: rpc.NewClient(conn),
+	}
+}
+
+func (c *RPCClientDriver) call(method string, args interface{}, reply interface{}) error {
+	if err := c.client.Call(pluginServiceName+"."+method, args, reply); err != nil {
+		if serverErr, ok := err.(rpc.ServerError); ok {
+			for _, knownErr := range wellKnownErrors {
+				if string(serverErr) == knownErr.Error() {
+					return knownErr
+				}
+			}
+			return errors.New(string(serverErr))
+		}
+		return fmt.Errorf("Error calling plugin for driver %s: %s", c.name, err)
+	}
+	return nil
+}
+
+// Close shuts down the connection to the plugin, which makes the plugin
+// process exit.
+func (c *RPCClientDriver) Close() error {
+	err := c.client.Close()
+	if c.cmd != nil {
+		c.cmd.Wait()
+	}
+	return err
+}
+
+func (c *RPCClientDriver) getCreateFlags() ([]cli.Flag, error) {
+	var rpcFlags []RPCFlag
+	if err := c.call("GetCreateFlags", struct{}{}, &rpcFlags); err != nil {
+		return nil, err
+	}
+	return fromRPCFlags(rpcFlags), nil
+}
+
+// MarshalJSON asks the plugin for the JSON representation of its driver so
+// that plugin-backed hosts are stored in config.json like any other host.
+func (c *RPCClientDriver) MarshalJSON() ([]byte, error) {
+	var data []byte
+	if err := c.call("GetConfigRaw", struct{}{}, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// UnmarshalJSON hands the stored driver configuration back to the plugin.
+func (c *RPCClientDriver) UnmarshalJSON(data []byte) error {
+	return c.call("SetConfigRaw", data, &struct{}{})
+}
+
+func (c *RPCClientDriver) AuthorizePort(ports []*Port) error {
+	return c.call("AuthorizePort", ports, &struct{}{})
+}
+
+func (c *RPCClientDriver) Create() error {
+	return c.call("Create", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) DeauthorizePort(ports []*Port) error {
+	return c.call("DeauthorizePort", ports, &struct{}{})
+}
+
+func (c *RPCClientDriver) DriverName() string {
+	var name string
+	if err := c.call("DriverName", struct{}{}, &name); err != nil {
+		log.Warnf("Error getting driver name from plugin: %s", err)
+		return c.name
+	}
+	return name
+}
+
+func (c *RPCClientDriver) GetIP() (string, error) {
+	var ip string
+	err := c.call("GetIP", struct{}{}, &ip)
+	return ip, err
+}
+
+func (c *RPCClientDriver) GetMachineName() string {
+	var name string
+	if err := c.call("GetMachineName", struct{}{}, &name); err != nil {
+		log.Warnf("Error getting machine name from plugin: %s", err)
+	}
+	return name
+}
+
+func (c *RPCClientDriver) GetSSHHostname() (string, error) {
+	var hostname string
+	err := c.call("GetSSHHostname", struct{}{}, &hostname)
+	return hostname, err
+}
+
+func (c *RPCClientDriver) GetSSHKeyPath() string {
+	var path string
+	if err := c.call("GetSSHKeyPath", struct{}{}, &path); err != nil {
+		log.Warnf("Error getting SSH key path from plugin: %s", err)
+	}
+	return path
+}
+
+func (c *RPCClientDriver) GetSSHPort() (int, error) {
+	var port int
+	err := c.call("GetSSHPort", struct{}{}, &port)
+	return port, err
+}
+
+func (c *RPCClientDriver) GetSSHUsername() string {
+	var username string
+	if err := c.call("GetSSHUsername", struct{}{}, &username); err != nil {
+		log.Warnf("Error getting SSH username from plugin: %s", err)
+	}
+	return username
+}
+
+func (c *RPCClientDriver) GetURL() (string, error) {
+	var url string
+	err := c.call("GetURL", struct{}{}, &url)
+	return url, err
+}
+
+func (c *RPCClientDriver) GetState() (state.State, error) {
+	var st state.State
+	err := c.call("GetState", struct{}{}, &st)
+	return st, err
+}
+
+func (c *RPCClientDriver) Kill() error {
+	return c.call("Kill", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) PreCreateCheck() error {
+	return c.call("PreCreateCheck", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) Remove() error {
+	return c.call("Remove", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) Restart() error {
+	return c.call("Restart", struct{}{}, &struct{}{})
+}
+
+// SetConfigFromFlags reads the value of every create flag the plugin
+// declares and sends them over, since the flags themselves can not be.
+func (c *RPCClientDriver) SetConfigFromFlags(flags DriverOptions) error {
+	createFlags, err := c.getCreateFlags()
+	if err != nil {
+		return err
+	}
+
+	opts := RPCOptions{
+		Values: make(map[string]interface{}),
+	}
+
+	for _, f := range createFlags {
+		switch flag := f.(type) {
+		case cli.StringFlag:
+			name := flagName(flag.Name)
+			opts.Values[name] = flags.String(name)
+		case cli.IntFlag:
+			name := flagName(flag.Name)
+			opts.Values[name] = flags.Int(name)
+		case cli.BoolFlag:
+			name := flagName(flag.Name)
+			opts.Values[name] = flags.Bool(name)
+		case cli.BoolTFlag:
+			name := flagName(flag.Name)
+			opts.Values[name] = flags.Bool(name)
+		case cli.StringSliceFlag:
+			name := flagName(flag.Name)
+			opts.Values[name] = flags.StringSlice(name)
+		}
+	}
+
+	return c.call("SetConfigFromFlags", opts, &struct{}{})
+}
+
+func (c *RPCClientDriver) Start() error {
+	return c.call("Start", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) Stop() error {
+	return c.call("Stop", struct{}{}, &struct{}{})
+}
+
+// capabilities asks the plugin which capabilities its driver offers.
+// Contexts can not be sent to the plugin, so its operations can not be
+// cancelled.
+func (c *RPCClientDriver) capabilities() []string {
+	if c.caps != nil {
+		return c.caps
+	}
+
+	var capabilities []string
+	if err := c.call("Capabilities", struct{}{}, &capabilities); err != nil {
+		log.Warnf("Error getting capabilities from plugin: %s", err)
+		return []string{}
+	}
+
+	c.caps = []string{}
+	for _, capability := range capabilities {
+		if capability != CapabilityCancel {
+			c.caps = append(c.caps, capability)
+		}
+	}
+	return c.caps
+}
+
+func (c *RPCClientDriver) Pause() error {
+	return c.call("Pause", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) Suspend() error {
+	return c.call("Suspend", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) Resume() error {
+	return c.call("Resume", struct{}{}, &struct{}{})
+}
+
+func (c *RPCClientDriver) TakeSnapshot(name string) error {
+	return c.call("TakeSnapshot", name, &struct{}{})
+}
+
+func (c *RPCClientDriver) ListSnapshots() ([]Snapshot, error) {
+	var snapshots []Snapshot
+	err := c.call("ListSnapshots", struct{}{}, &snapshots)
+	return snapshots, err
+}
+
+func (c *RPCClientDriver) RestoreSnapshot(name string) error {
+	return c.call("RestoreSnapshot", name, &struct{}{})
+}
+
+func (c *RPCClientDriver) RemoveSnapshot(name string) error {
+	return c.call("RemoveSnapshot", name, &struct{}{})
+}
+
+func (c *RPCClientDriver) Resize(opts ResizeOptions) error {
+	return c.call("Resize", opts, &struct{}{})
+}
+
+func (c *RPCClientDriver) PrepareRename(newName string) error {
+	return c.call("PrepareRename", newName, &struct{}{})
+}
+
+func (c *RPCClientDriver) Rename(oldName string) error {
+	return c.call("Rename", oldName, &struct{}{})
+}
+
+// SetMachineName sets the name of the machine of the driver in the plugin
+// once the machine is renamed.
+func (c *RPCClientDriver) SetMachineName(name string) {
+	if err := c.call("SetMachineName", name, &struct{}{}); err != nil {
+		log.Warnf("Error setting machine name in plugin: %s", err)
+	}
+}
@@ -0,0 +1,550 @@
+package drivers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/rpc"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/state"
+)
+
+const (
+	// PluginEnvKey and PluginEnvVal are set in the environment of a plugin
+	// process by the machine binary so the plugin knows it is not being
+	// run interactively.
+	PluginEnvKey = "MACHINE_PLUGIN_MAGIC_COOKIE"
+	PluginEnvVal = "3e3f3dd7a1a5b8e7c5c1a0f0d9b2e8c4"
+
+	pluginServiceName = "Driver"
+)
+
+// RPCFlag is the wire representation of a cli.Flag.  Only the flag types
+// used by drivers (string, int, bool, boolT and string slice) are
+// supported.
+type RPCFlag struct {
+	Kind        string
+	Name        string
+	Usage       string
+	EnvVar      string
+	StringValue string
+	IntValue    int
+	SliceValue  []string
+}
+
+// RPCOptions carries the values of the create flags of a plugin driver
+// over the wire.  It implements DriverOptions.
+type RPCOptions struct {
+	Values map[string]interface{}
+}
+
+func (o RPCOptions) String(key string) string {
+	v, _ := o.Values[key].(string)
+	return v
+}
+
+func (o RPCOptions) StringSlice(key string) []string {
+	v, _ := o.Values[key].([]string)
+	return v
+}
+
+func (o RPCOptions) Int(key string) int {
+	v, _ := o.Values[key].(int)
+	return v
+}
+
+func (o RPCOptions) Bool(key string) bool {
+	v, _ := o.Values[key].(bool)
+	return v
+}
+
+// RPCNewDriverArgs are the arguments passed to RegisteredDriver.New in the
+// plugin process.
+type RPCNewDriverArgs struct {
+	MachineName string
+	StorePath   string
+	CaCert      string
+	PrivateKey  string
+}
+
+// RPCServerDriver exposes a driver compiled into a plugin binary over
+// net/rpc.  Each method maps to a method of the Driver interface.
+type RPCServerDriver struct {
+	registered *RegisteredDriver
+	driver     Driver
+}
+
+func (s *RPCServerDriver) getDriver() (Driver, error) {
+	if s.driver == nil {
+		return nil, fmt.Errorf("plugin driver has not been initialized")
+	}
+	return s.driver, nil
+}
+
+func (s *RPCServerDriver) NewDriver(args RPCNewDriverArgs, _ *struct{}) error {
+	driver, err := s.registered.New(args.MachineName, args.StorePath, args.CaCert, args.PrivateKey)
+	if err != nil {
+		return err
+	}
+	s.driver = driver
+	return nil
+}
+
+func (s *RPCServerDriver) GetCreateFlags(_ struct{}, reply *[]RPCFlag) error {
+	*reply = toRPCFlags(s.registered.GetCreateFlags())
+	return nil
+}
+
+func (s *RPCServerDriver) GetConfigRaw(_ struct{}, reply *[]byte) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	*reply = data
+	return nil
+}
+
+func (s *RPCServerDriver) SetConfigRaw(data []byte, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, d)
+}
+
+func (s *RPCServerDriver) AuthorizePort(ports []*Port, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.AuthorizePort(ports)
+}
+
+func (s *RPCServerDriver) DeauthorizePort(ports []*Port, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.DeauthorizePort(ports)
+}
+
+func (s *RPCServerDriver) Create(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.Create()
+}
+
+func (s *RPCServerDriver) DriverName(_ struct{}, reply *string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	*reply = d.DriverName()
+	return nil
+}
+
+func (s *RPCServerDriver) GetIP(_ struct{}, reply *string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	ip, err := d.GetIP()
+	*reply = ip
+	return err
+}
+
+func (s *RPCServerDriver) GetMachineName(_ struct{}, reply *string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	*reply = d.GetMachineName()
+	return nil
+}
+
+func (s *RPCServerDriver) GetSSHHostname(_ struct{}, reply *string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	hostname, err := d.GetSSHHostname()
+	*reply = hostname
+	return err
+}
+
+func (s *RPCServerDriver) GetSSHKeyPath(_ struct{}, reply *string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	*reply = d.GetSSHKeyPath()
+	return nil
+}
+
+func (s *RPCServerDriver) GetSSHPort(_ struct{}, reply *int) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	port, err := d.GetSSHPort()
+	*reply = port
+	return err
+}
+
+func (s *RPCServerDriver) GetSSHUsername(_ struct{}, reply *string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	*reply = d.GetSSHUsername()
+	return nil
+}
+
+func (s *RPCServerDriver) GetURL(_ struct{}, reply *string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	url, err := d.GetURL()
+	*reply = url
+	return err
+}
+
+func (s *RPCServerDriver) GetState(_ struct{}, reply *state.State) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	st, err := d.GetState()
+	*reply = st
+	return err
+}
+
+func (s *RPCServerDriver) Kill(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.Kill()
+}
+
+func (s *RPCServerDriver) PreCreateCheck(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.PreCreateCheck()
+}
+
+func (s *RPCServerDriver) Remove(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.Remove()
+}
+
+func (s *RPCServerDriver) Restart(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.Restart()
+}
+
+func (s *RPCServerDriver) SetConfigFromFlags(opts RPCOptions, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.SetConfigFromFlags(opts)
+}
+
+func (s *RPCServerDriver) Start(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.Start()
+}
+
+func (s *RPCServerDriver) Stop(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	return d.Stop()
+}
+
+func errCapabilityNotSupported(d Driver, capability string) error {
+	return fmt.Errorf("Driver %s does not support %s", d.DriverName(), capability)
+}
+
+func (s *RPCServerDriver) Capabilities(_ struct{}, reply *[]string) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	*reply = Capabilities(d)
+	return nil
+}
+
+func (s *RPCServerDriver) Pause(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	pauser, ok := d.(Pauser)
+	if !ok {
+		return errCapabilityNotSupported(d, CapabilityPause)
+	}
+	return pauser.Pause()
+}
+
+func (s *RPCServerDriver) Suspend(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	suspender, ok := d.(Suspender)
+	if !ok {
+		return errCapabilityNotSupported(d, CapabilitySuspend)
+	}
+	return suspender.Suspend()
+}
+
+// Resume resumes a machine paused by a Pauser or suspended by a Suspender.
+func (s *RPCServerDriver) Resume(_ struct{}, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	resumer, ok := d.(interface {
+		Resume() error
+	})
+	if !ok {
+		return errCapabilityNotSupported(d, CapabilityPause)
+	}
+	return resumer.Resume()
+}
+
+func (s *RPCServerDriver) getSnapshotter() (Snapshotter, error) {
+	d, err := s.getDriver()
+	if err != nil {
+		return nil, err
+	}
+	snapshotter, ok := d.(Snapshotter)
+	if !ok {
+		return nil, errCapabilityNotSupported(d, CapabilitySnapshot)
+	}
+	return snapshotter, nil
+}
+
+func (s *RPCServerDriver) TakeSnapshot(name string, _ *struct{}) error {
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return err
+	}
+	return snapshotter.TakeSnapshot(name)
+}
+
+func (s *RPCServerDriver) ListSnapshots(_ struct{}, reply *[]Snapshot) error {
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return err
+	}
+	snapshots, err := snapshotter.ListSnapshots()
+	*reply = snapshots
+	return err
+}
+
+func (s *RPCServerDriver) RestoreSnapshot(name string, _ *struct{}) error {
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return err
+	}
+	return snapshotter.RestoreSnapshot(name)
+}
+
+func (s *RPCServerDriver) RemoveSnapshot(name string, _ *struct{}) error {
+	snapshotter, err := s.getSnapshotter()
+	if err != nil {
+		return err
+	}
+	return snapshotter.RemoveSnapshot(name)
+}
+
+func (s *RPCServerDriver) Resize(opts ResizeOptions, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	resizer, ok := d.(Resizer)
+	if !ok {
+		return errCapabilityNotSupported(d, CapabilityResize)
+	}
+	return resizer.Resize(opts)
+}
+
+func (s *RPCServerDriver) PrepareRename(newName string, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	renamer, ok := d.(Renamer)
+	if !ok {
+		return errCapabilityNotSupported(d, CapabilityRename)
+	}
+	return renamer.PrepareRename(newName)
+}
+
+func (s *RPCServerDriver) Rename(oldName string, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	renamer, ok := d.(Renamer)
+	if !ok {
+		return errCapabilityNotSupported(d, CapabilityRename)
+	}
+	return renamer.Rename(oldName)
+}
+
+// SetMachineName renames the machine of drivers which embed BaseDriver.
+func (s *RPCServerDriver) SetMachineName(name string, _ *struct{}) error {
+	d, err := s.getDriver()
+	if err != nil {
+		return err
+	}
+	setter, ok := d.(interface {
+		SetMachineName(name string)
+	})
+	if !ok {
+		return fmt.Errorf("Driver %s does not support renaming machines", d.DriverName())
+	}
+	setter.SetMachineName(name)
+	return nil
+}
+
+// ServePlugin is the entry point for driver plugin binaries.  A plugin's
+// main function should do nothing but call it:
+//
+//	func main() {
+//		drivers.ServePlugin(&drivers.RegisteredDriver{
+//			New:            NewDriver,
+//			GetCreateFlags: GetCreateFlags,
+//		})
+//	}
+//
+// The plugin talks to machine over its stdin and stdout and exits when
+// machine closes them.
+func ServePlugin(registeredDriver *RegisteredDriver) {
+	if os.Getenv(PluginEnvKey) != PluginEnvVal {
+		fmt.Fprintln(os.Stderr, "This is a Docker Machine plugin binary.")
+		fmt.Fprintln(os.Stderr, "Plugin binaries are not intended to be invoked directly.")
+		fmt.Fprintln(os.Stderr, "Please use this plugin through the main 'docker-machine' binary.")
+		os.Exit(1)
+	}
+
+	conn := &stdioConn{
+		r: os.Stdin,
+		w: os.Stdout,
+	}
+
+	// stdout belongs to the RPC channel from here on, so anything the
+	// driver prints (including log output) is sent to stderr instead.
+	os.Stdout = os.Stderr
+
+	if err := servePluginConn(registeredDriver, conn); err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving plugin: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func servePluginConn(registeredDriver *RegisteredDriver, conn io.ReadWriteCloser) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(pluginServiceName, &RPCServerDriver{registered: registeredDriver}); err != nil {
+		return err
+	}
+	server.ServeConn(conn)
+	return nil
+}
+
+// stdioConn joins a reader and a writer into a single connection for use
+// with net/rpc.
+type stdioConn struct {
+	r io.ReadCloser
+	w io.WriteCloser
+}
+
+func (c *stdioConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (c *stdioConn) Write(p []byte) (int, error) {
+	return c.w.Write(p)
+}
+
+func (c *stdioConn) Close() error {
+	rerr := c.r.Close()
+	if err := c.w.Close(); err != nil {
+		return err
+	}
+	return rerr
+}
+
+func flagName(name string) string {
+	return strings.TrimSpace(strings.Split(name, ",")[0])
+}
+
+func toRPCFlags(flags []cli.Flag) []RPCFlag {
+	rpcFlags := []RPCFlag{}
+	for _, f := range flags {
+		switch flag := f.(type) {
+		case cli.StringFlag:
+			rpcFlags = append(rpcFlags, RPCFlag{Kind: "string", Name: flag.Name, Usage: flag.Usage, EnvVar: flag.EnvVar, StringValue: flag.Value})
+		case cli.IntFlag:
+			rpcFlags = append(rpcFlags, RPCFlag{Kind: "int", Name: flag.Name, Usage: flag.Usage, EnvVar: flag.EnvVar, IntValue: flag.Value})
+		case cli.BoolFlag:
+			rpcFlags = append(rpcFlags, RPCFlag{Kind: "bool", Name: flag.Name, Usage: flag.Usage, EnvVar: flag.EnvVar})
+		case cli.BoolTFlag:
+			rpcFlags = append(rpcFlags, RPCFlag{Kind: "boolT", Name: flag.Name, Usage: flag.Usage, EnvVar: flag.EnvVar})
+		case cli.StringSliceFlag:
+			var values []string
+			if flag.Value != nil {
+				values = flag.Value.Value()
+			}
+			rpcFlags = append(rpcFlags, RPCFlag{Kind: "stringSlice", Name: flag.Name, Usage: flag.Usage, EnvVar: flag.EnvVar, SliceValue: values})
+		}
+	}
+	return rpcFlags
+}
+
+func fromRPCFlags(rpcFlags []RPCFlag) []cli.Flag {
+	flags := []cli.Flag{}
+	for _, f := range rpcFlags {
+		switch f.Kind {
+		case "string":
+			flags = append(flags, cli.StringFlag{Name: f.Name, Usage: f.Usage, EnvVar: f.EnvVar, Value: f.StringValue})
+		case "int":
+			flags = append(flags, cli.IntFlag{Name: f.Name, Usage: f.Usage, EnvVar: f.EnvVar, Value: f.IntValue})
+		case "bool":
+			flags = append(flags, cli.BoolFlag{Name: f.Name, Usage: f.Usage, EnvVar: f.EnvVar})
+		case "boolT":
+			flags = append(flags, cli.BoolTFlag{Name: f.Name, Usage: f.Usage, EnvVar: f.EnvVar})
+		case "stringSlice":
+			values := cli.StringSlice(f.SliceValue)
+			flags = append(flags, cli.StringSliceFlag{Name: f.Name, Usage: f.Usage, EnvVar: f.EnvVar, Value: &values})
+		}
+	}
+	return flags
+}
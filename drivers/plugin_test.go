@@ -0,0 +1,256 @@
+package drivers
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/state"
+)
+
+type pluginTestDriver struct {
+	*BaseDriver
+	URL     string
+	Running bool
+}
+
+func (d *pluginTestDriver) Create() error          { return nil }
+func (d *pluginTestDriver) DriverName() string     { return "plugintest" }
+func (d *pluginTestDriver) GetIP() (string, error) { return "1.2.3.4", nil }
+func (d *pluginTestDriver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+func (d *pluginTestDriver) GetURL() (string, error) {
+	if !d.Running {
+		return "", ErrHostIsNotRunning
+	}
+	return d.URL, nil
+}
+func (d *pluginTestDriver) GetState() (state.State, error) {
+	if d.Running {
+		return state.Running, nil
+	}
+	return state.Stopped, nil
+}
+func (d *pluginTestDriver) Kill() error           { return nil }
+func (d *pluginTestDriver) PreCreateCheck() error { return nil }
+func (d *pluginTestDriver) Remove() error         { return nil }
+func (d *pluginTestDriver) Restart() error        { return nil }
+func (d *pluginTestDriver) SetConfigFromFlags(flags DriverOptions) error {
+	d.URL = flags.String("plugintest-url")
+	d.SSHPort = flags.Int("plugintest-ssh-port")
+	return nil
+}
+func (d *pluginTestDriver) Start() error {
+	d.Running = true
+	return nil
+}
+func (d *pluginTestDriver) Stop() error {
+	d.Running = false
+	return nil
+}
+func (d *pluginTestDriver) Pause() error {
+	d.Running = false
+	return nil
+}
+func (d *pluginTestDriver) Resume() error {
+	return d.Start()
+}
+
+var pluginTestRegisteredDriver = &RegisteredDriver{
+	New: func(machineName string, storePath string, caCert string, privateKey string) (Driver, error) {
+		return &pluginTestDriver{BaseDriver: NewBaseDriver(machineName, storePath, caCert, privateKey)}, nil
+	},
+	GetCreateFlags: func() []cli.Flag {
+		return []cli.Flag{
+			cli.StringFlag{
+				Name:  "plugintest-url",
+				Usage: "URL of the test host",
+				Value: "tcp://1.2.3.4:2376",
+			},
+			cli.IntFlag{
+				Name:  "plugintest-ssh-port",
+				Usage: "SSH port of the test host",
+				Value: 22,
+			},
+		}
+	},
+}
+
+func newTestPluginClient(t *testing.T) *RPCClientDriver {
+	serverConn, clientConn := net.Pipe()
+	go servePluginConn(pluginTestRegisteredDriver, serverConn)
+
+	client := newRPCClientDriver("plugintest", clientConn)
+	args := RPCNewDriverArgs{
+		MachineName: "test",
+		StorePath:   "/tmp/store",
+	}
+	if err := client.call("NewDriver", args, &struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestPluginGetCreateFlags(t *testing.T) {
+	client := newTestPluginClient(t)
+	defer client.Close()
+
+	flags, err := client.getCreateFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(flags) != 2 {
+		t.Fatalf("expected 2 flags; received %d", len(flags))
+	}
+
+	urlFlag, ok := flags[0].(cli.StringFlag)
+	if !ok {
+		t.Fatalf("expected a string flag; received %T", flags[0])
+	}
+	if urlFlag.Name != "plugintest-url" || urlFlag.Value != "tcp://1.2.3.4:2376" {
+		t.Fatalf("unexpected flag: %+v", urlFlag)
+	}
+
+	portFlag, ok := flags[1].(cli.IntFlag)
+	if !ok {
+		t.Fatalf("expected an int flag; received %T", flags[1])
+	}
+	if portFlag.Value != 22 {
+		t.Fatalf("expected default port 22; received %d", portFlag.Value)
+	}
+}
+
+func TestPluginDriverMethods(t *testing.T) {
+	client := newTestPluginClient(t)
+	defer client.Close()
+
+	opts := RPCOptions{
+		Values: map[string]interface{}{
+			"plugintest-url":      "tcp://5.6.7.8:2376",
+			"plugintest-ssh-port": 2222,
+		},
+	}
+	if err := client.SetConfigFromFlags(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if name := client.DriverName(); name != "plugintest" {
+		t.Fatalf("expected driver name plugintest; received %s", name)
+	}
+
+	if name := client.GetMachineName(); name != "test" {
+		t.Fatalf("expected machine name test; received %s", name)
+	}
+
+	if _, err := client.GetURL(); err != ErrHostIsNotRunning {
+		t.Fatalf("expected ErrHostIsNotRunning; received %v", err)
+	}
+
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := client.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st != state.Running {
+		t.Fatalf("expected state Running; received %s", st)
+	}
+
+	url, err := client.GetURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "tcp://5.6.7.8:2376" {
+		t.Fatalf("unexpected url: %s", url)
+	}
+
+	port, err := client.GetSSHPort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port != 2222 {
+		t.Fatalf("expected SSH port 2222; received %d", port)
+	}
+}
+
+func TestPluginDriverCapabilities(t *testing.T) {
+	client := newTestPluginClient(t)
+	defer client.Close()
+
+	if capabilities := Capabilities(client); !reflect.DeepEqual(capabilities, []string{CapabilityPause}) {
+		t.Fatalf("expected the plugin to pause only; received %v", capabilities)
+	}
+	if HasCapability(client, CapabilitySnapshot) {
+		t.Fatal("expected the plugin not to take snapshots")
+	}
+
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := client.GetState(); err != nil || st != state.Stopped {
+		t.Fatalf("expected the paused machine not to run; received %s, %v", st, err)
+	}
+	if err := client.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := client.GetState(); err != nil || st != state.Running {
+		t.Fatalf("expected the resumed machine to run; received %s, %v", st, err)
+	}
+
+	if err := client.TakeSnapshot("before"); err == nil {
+		t.Fatal("expected an error taking a snapshot with a driver which does not support it")
+	}
+}
+
+func TestPluginDriverJSON(t *testing.T) {
+	client := newTestPluginClient(t)
+	defer client.Close()
+
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newTestPluginClient(t)
+	defer restored.Close()
+
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := restored.GetState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st != state.Running {
+		t.Fatalf("expected restored state Running; received %s", st)
+	}
+}
+
+func TestCloseDriver(t *testing.T) {
+	client := newTestPluginClient(t)
+
+	if err := CloseDriver(client); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetIP(); err == nil {
+		t.Fatal("expected an error calling a closed plugin")
+	}
+
+	if err := CloseDriver(&pluginTestDriver{}); err != nil {
+		t.Fatalf("expected closing a driver without resources to succeed; received %s", err)
+	}
+}
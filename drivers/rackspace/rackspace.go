@@ -13,13 +13,15 @@ import (
 type Driver struct {
 	*openstack.Driver
 
-	APIKey string
+	APIKey string `secret:"true"`
 }
 
 func init() {
 	drivers.Register("rackspace", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "Rackspace cloud servers",
+		RequiredFlags:  []string{"rackspace-region", "rackspace-username", "rackspace-api-key"},
 	})
 }
 
@@ -45,6 +45,8 @@ func init() {
 	drivers.Register("softlayer", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "IBM SoftLayer virtual servers",
+		RequiredFlags:  []string{"softlayer-user", "softlayer-api-key", "softlayer-domain"},
 	})
 }
 
@@ -294,7 +296,7 @@ func (d *Driver) PreCreateCheck() error {
 }
 
 func (d *Driver) waitForStart() {
-	log.Infof("Waiting for host to become available")
+	d.Progress().Infof("Waiting for host to become available")
 	for {
 		s, err := d.GetState()
 		if err != nil {
@@ -312,7 +314,7 @@ func (d *Driver) waitForStart() {
 }
 
 func (d *Driver) getIp() (string, error) {
-	log.Infof("Getting Host IP")
+	d.Progress().Infof("Getting Host IP")
 	for {
 		var (
 			ip  string
@@ -338,7 +340,7 @@ func (d *Driver) getIp() (string, error) {
 }
 
 func (d *Driver) waitForSetupTransactions() {
-	log.Infof("Waiting for host setup transactions to complete")
+	d.Progress().Infof("Waiting for host setup transactions to complete")
 	// sometimes we'll hit a case where there's no active transaction, but if
 	// we check again in a few seconds, it moves to the next transaction. We
 	// don't want to get false-positives, so we check a few times in a row to make sure!
@@ -367,13 +369,13 @@ func (d *Driver) waitForSetupTransactions() {
 func (d *Driver) Create() error {
 	spec := d.buildHostSpec()
 
-	log.Infof("Creating SSH key...")
+	d.Progress().Infof("Creating SSH key...")
 	key, err := d.createSSHKey()
 	if err != nil {
 		return err
 	}
 
-	log.Infof("SSH key %s (%d) created in SoftLayer", key.Label, key.Id)
+	d.Progress().Infof("SSH key %s (%d) created in SoftLayer", key.Label, key.Id)
 	d.SSHKeyID = key.Id
 
 	spec.SshKeys = []*SshKey{key}
@@ -450,7 +452,7 @@ func (d *Driver) Kill() error {
 }
 
 func (d *Driver) Remove() error {
-	log.Infof("Canceling SoftLayer instance %d...", d.Id)
+	d.Progress().Infof("Canceling SoftLayer instance %d...", d.Id)
 	var err error
 	for i := 0; i < 5; i++ {
 		if err = d.getClient().VirtualGuest().Cancel(d.Id); err != nil {
@@ -463,7 +465,7 @@ func (d *Driver) Remove() error {
 		return err
 	}
 
-	log.Infof("Removing SSH Key %d...", d.SSHKeyID)
+	d.Progress().Infof("Removing SSH Key %d...", d.SSHKeyID)
 	if err = d.getClient().SshKey().Delete(d.SSHKeyID); err != nil {
 		return err
 	}
@@ -2,11 +2,14 @@ package drivers
 
 import (
 	"fmt"
-	"strings"
+	"os/exec"
+	"time"
 
 	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
 	"github.com/docker/machine/ssh"
 	"github.com/docker/machine/utils"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -33,8 +36,38 @@ func GetSSHClientFromDriver(d Driver) (ssh.Client, error) {
 
 }
 
-func isErr255Exit(err error) bool {
-	return strings.Contains(err.Error(), ErrExitCode255)
+// ErrSSHCommand is returned by RunSSHCommandFromDriver for a command which
+// failed.  ExitCode is -1 if the command did not exit, e.g. if the machine
+// could not be reached; the ssh binary exits with 255 for such errors.
+// Command and Output are left out of the message, as they may contain
+// credentials; they are logged at debug level.
+type ErrSSHCommand struct {
+	Command  string
+	ExitCode int
+	Output   string
+	Err      error
+}
+
+func (e ErrSSHCommand) Error() string {
+	return fmt.Sprintf("Error running SSH command: %v", e.Err)
+}
+
+type exitStatuser interface {
+	ExitStatus() int
+}
+
+// sshExitCode returns the exit code of the command which failed with err,
+// from either the ssh binary or the native client, or -1.
+func sshExitCode(err error) int {
+	switch err := err.(type) {
+	case *exec.ExitError:
+		if status, ok := err.Sys().(exitStatuser); ok {
+			return status.ExitStatus()
+		}
+	case exitStatuser:
+		return err.ExitStatus()
+	}
+	return -1
 }
 
 func RunSSHCommandFromDriver(d Driver, command string) (string, error) {
@@ -47,18 +80,43 @@ func RunSSHCommandFromDriver(d Driver, command string) (string, error) {
 
 	output, err := client.Output(command)
 	log.Debugf("SSH cmd err, output: %v: %s", err, output)
-	if err != nil && !isErr255Exit(err) {
-		log.Error("SSH cmd error!")
-		log.Errorf("command: %s", command)
-		log.Errorf("err    : %v", err)
-		log.Fatalf("output : %s", output)
+	if err != nil {
+		return output, ErrSSHCommand{
+			Command:  command,
+			ExitCode: sshExitCode(err),
+			Output:   output,
+			Err:      err,
+		}
 	}
 
-	return output, err
+	return output, nil
 }
 
-func sshAvailableFunc(d Driver) func() bool {
+// sshTCPWaitTimeout bounds each attempt of WaitForSSH to reach the SSH port
+const sshTCPWaitTimeout = 10 * time.Second
+
+// ProgressPublisher is implemented by the drivers which publish the
+// progress of their operations, which the drivers embedding BaseDriver do.
+type ProgressPublisher interface {
+	SetProgressSink(sink progress.Sink)
+	Progress() progress.Reporter
+}
+
+// GetProgress returns the reporter of the progress of the operations of a
+// driver.  The progress of the drivers which do not publish it is logged.
+func GetProgress(d Driver) progress.Reporter {
+	if p, ok := d.(ProgressPublisher); ok {
+		return p.Progress()
+	}
+	return progress.NewReporter(nil, d.GetMachineName())
+}
+
+func sshAvailableFunc(ctx context.Context, d Driver) func() bool {
+	reporter := GetProgress(d)
+	attempt := 0
 	return func() bool {
+		attempt++
+		reporter.WaitingForSSH(attempt)
 		log.Debug("Getting to WaitForSSH function...")
 		hostname, err := d.GetSSHHostname()
 		if err != nil {
@@ -70,7 +128,9 @@ func sshAvailableFunc(d Driver) func() bool {
 			log.Debugf("Error getting SSH port: %s", err)
 			return false
 		}
-		if err := ssh.WaitForTCP(fmt.Sprintf("%s:%d", hostname, port)); err != nil {
+		tcpCtx, cancel := context.WithTimeout(ctx, sshTCPWaitTimeout)
+		defer cancel()
+		if err := ssh.WaitForTCPContext(tcpCtx, fmt.Sprintf("%s:%d", hostname, port)); err != nil {
 			log.Debugf("Error waiting for TCP waiting for SSH: %s", err)
 			return false
 		}
@@ -84,7 +144,16 @@ func sshAvailableFunc(d Driver) func() bool {
 }
 
 func WaitForSSH(d Driver) error {
-	if err := utils.WaitFor(sshAvailableFunc(d)); err != nil {
+	return WaitForSSHContext(context.Background(), d)
+}
+
+// WaitForSSHContext waits until the SSH daemon of the machine answers, or
+// ctx is done.
+func WaitForSSHContext(ctx context.Context, d Driver) error {
+	if err := utils.WaitForContext(ctx, sshAvailableFunc(ctx, d)); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		return fmt.Errorf("Too many retries.  Last error: %s", err)
 	}
 	return nil
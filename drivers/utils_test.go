@@ -0,0 +1,37 @@
+package drivers
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSSHExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sh on windows")
+	}
+
+	err := exec.Command("sh", "-c", "exit 3").Run()
+	if code := sshExitCode(err); code != 3 {
+		t.Fatalf("expected exit code 3; received %d", code)
+	}
+
+	if code := sshExitCode(errors.New("connection refused")); code != -1 {
+		t.Fatalf("expected exit code -1; received %d", code)
+	}
+}
+
+func TestErrSSHCommandLeavesOutCommand(t *testing.T) {
+	err := ErrSSHCommand{
+		Command:  "echo secret-password",
+		ExitCode: 1,
+		Output:   "secret-password",
+		Err:      errors.New("exit status 1"),
+	}
+
+	if msg := err.Error(); strings.Contains(msg, "secret-password") {
+		t.Fatalf("expected the command and output to be left out; received %q", msg)
+	}
+}
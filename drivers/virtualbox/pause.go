@@ -0,0 +1,31 @@
+package virtualbox
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/state"
+)
+
+func (d *Driver) Pause() error {
+	d.Progress().Infof("Pausing VM %s...", d.MachineName)
+	return vbm("controlvm", d.MachineName, "pause")
+}
+
+// Suspend saves the state of the VM to disk and stops it.
+func (d *Driver) Suspend() error {
+	d.Progress().Infof("Saving the state of VM %s...", d.MachineName)
+	return vbm("controlvm", d.MachineName, "savestate")
+}
+
+// Resume resumes a paused VM, or starts a saved one from its saved state,
+// as Start does.
+func (d *Driver) Resume() error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if s != state.Paused && s != state.Saved {
+		return fmt.Errorf("VM %s is not paused or saved", d.MachineName)
+	}
+	return d.Start()
+}
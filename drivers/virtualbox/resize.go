@@ -0,0 +1,52 @@
+package virtualbox
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/drivers"
+)
+
+// Resize changes the CPUs and the memory of the VM, and grows its disk.
+// Disks can not shrink, and the new space of a disk is left unpartitioned.
+func (d *Driver) Resize(opts drivers.ResizeOptions) error {
+	if opts.Size != "" {
+		return fmt.Errorf("VirtualBox machines do not have sizes; set the CPUs, the memory or the disk size instead")
+	}
+
+	if opts.DiskSize != 0 && opts.DiskSize < d.DiskSize {
+		return fmt.Errorf("The disk of a VirtualBox machine can not shrink, it is %d MB", d.DiskSize)
+	}
+
+	args := []string{"modifyvm", d.MachineName}
+	if opts.CPU != 0 {
+		args = append(args, "--cpus", fmt.Sprintf("%d", vmCPUs(opts.CPU)))
+	}
+	if opts.Memory != 0 {
+		args = append(args, "--memory", fmt.Sprintf("%d", opts.Memory))
+	}
+	if len(args) > 2 {
+		d.Progress().Infof("Resizing VM %s...", d.MachineName)
+		if err := vbm(args...); err != nil {
+			return err
+		}
+
+		// The VM has its new CPUs and memory even if growing the disk
+		// fails below, so the config has to say so.
+		if opts.CPU != 0 {
+			d.CPU = opts.CPU
+		}
+		if opts.Memory != 0 {
+			d.Memory = opts.Memory
+		}
+	}
+
+	if opts.DiskSize != 0 && opts.DiskSize != d.DiskSize {
+		d.Progress().Infof("Growing the disk of VM %s to %d MB...", d.MachineName, opts.DiskSize)
+		if err := vbm("modifyhd", d.diskPath(), "--resize", fmt.Sprintf("%d", opts.DiskSize)); err != nil {
+			return err
+		}
+		d.DiskSize = opts.DiskSize
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package virtualbox
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/state"
+)
+
+var reSnapshotLine = regexp.MustCompile(`^Snapshot(Name|UUID)((?:-\d+)*)="(.*)"$`)
+
+// parseSnapshotList parses the output of `VBoxManage snapshot list
+// --machinereadable`, which names the snapshots of the tree by their path,
+// e.g. SnapshotName-1-2.
+func parseSnapshotList(out string) []drivers.Snapshot {
+	snapshots := []drivers.Snapshot{}
+	index := make(map[string]int)
+
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		res := reSnapshotLine.FindStringSubmatch(strings.TrimSpace(s.Text()))
+		if res == nil {
+			continue
+		}
+
+		field, path, value := res[1], res[2], res[3]
+		i, ok := index[path]
+		if !ok {
+			i = len(snapshots)
+			index[path] = i
+			snapshots = append(snapshots, drivers.Snapshot{})
+		}
+
+		switch field {
+		case "Name":
+			snapshots[i].Name = value
+		case "UUID":
+			snapshots[i].ID = value
+		}
+	}
+
+	return snapshots
+}
+
+func (d *Driver) ListSnapshots() ([]drivers.Snapshot, error) {
+	stdout, stderr, err := vbmOutErr("snapshot", d.MachineName, "list", "--machinereadable")
+	if err != nil {
+		if strings.Contains(stdout+stderr, "does not have any snapshots") {
+			return []drivers.Snapshot{}, nil
+		}
+		return nil, err
+	}
+	return parseSnapshotList(stdout), nil
+}
+
+func (d *Driver) TakeSnapshot(name string) error {
+	if err := d.checkSnapshot(name, false); err != nil {
+		return err
+	}
+
+	d.Progress().Infof("Taking snapshot %s of VM %s...", name, d.MachineName)
+	return vbm("snapshot", d.MachineName, "take", name)
+}
+
+// RestoreSnapshot powers the VM off, as VirtualBox only restores the
+// snapshots of VMs which are not running.  A VM restored to a snapshot
+// taken while it was running is left saved.
+func (d *Driver) RestoreSnapshot(name string) error {
+	if err := d.checkSnapshot(name, true); err != nil {
+		return err
+	}
+
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if s == state.Running || s == state.Paused {
+		if err := d.Kill(); err != nil {
+			return err
+		}
+	}
+
+	d.Progress().Infof("Restoring VM %s to snapshot %s...", d.MachineName, name)
+	if err := vbm("snapshot", d.MachineName, "restore", name); err != nil {
+		return err
+	}
+
+	d.IPAddress = ""
+
+	return nil
+}
+
+func (d *Driver) RemoveSnapshot(name string) error {
+	if err := d.checkSnapshot(name, true); err != nil {
+		return err
+	}
+
+	return vbm("snapshot", d.MachineName, "delete", name)
+}
+
+// checkSnapshot returns an error unless the snapshot "name" exists, or
+// does not exist, as expected.  VirtualBox allows several snapshots of the
+// same name, which could then not be told apart.
+func (d *Driver) checkSnapshot(name string, exists bool) error {
+	snapshots, err := d.ListSnapshots()
+	if err != nil {
+		return err
+	}
+
+	_, found := drivers.FindSnapshot(snapshots, name)
+	if exists && !found {
+		return drivers.ErrSnapshotDoesNotExist{Name: name}
+	}
+	if !exists && found {
+		return drivers.ErrSnapshotExists{Name: name}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package virtualbox
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/machine/drivers"
+)
+
+var (
+	testSnapshotListText = `
+SnapshotName="clean"
+SnapshotUUID="11111111-2222-3333-4444-555555555555"
+SnapshotName-1="before-upgrade"
+SnapshotUUID-1="66666666-7777-8888-9999-000000000000"
+SnapshotDescription-1="before the upgrade"
+SnapshotName-1-1="after-upgrade"
+SnapshotUUID-1-1="aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+CurrentSnapshotName="after-upgrade"
+CurrentSnapshotUUID="aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+CurrentSnapshotNode="SnapshotName-1-1"
+`
+)
+
+func TestParseSnapshotList(t *testing.T) {
+	expected := []drivers.Snapshot{
+		{Name: "clean", ID: "11111111-2222-3333-4444-555555555555"},
+		{Name: "before-upgrade", ID: "66666666-7777-8888-9999-000000000000"},
+		{Name: "after-upgrade", ID: "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"},
+	}
+
+	snapshots := parseSnapshotList(testSnapshotListText)
+	if !reflect.DeepEqual(snapshots, expected) {
+		t.Fatalf("expected %v; received %v", expected, snapshots)
+	}
+}
+
+func TestParseSnapshotListEmpty(t *testing.T) {
+	snapshots := parseSnapshotList("")
+	if len(snapshots) != 0 {
+		t.Fatalf("expected no snapshots; received %v", snapshots)
+	}
+}
@@ -54,6 +54,7 @@ func init() {
 	drivers.Register("virtualbox", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "Oracle VirtualBox virtual machines",
 	})
 }
 
@@ -179,12 +180,13 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	b2dutils := utils.NewB2dUtils("", "")
-	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+	b2dutils := utils.NewB2dUtils("", "", d.ResolveStorePath("."))
+	b2dutils.Progress = d.Progress()
+	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL); err != nil {
 		return err
 	}
 
-	log.Infof("Creating VirtualBox VM...")
+	d.Progress().Infof("Creating VirtualBox VM...")
 
 	// import b2d VM if requested
 	if d.Boot2DockerImportVM != "" {
@@ -221,7 +223,7 @@ func (d *Driver) Create() error {
 			return err
 		}
 	} else {
-		log.Infof("Creating SSH key...")
+		d.Progress().Infof("Creating SSH key...")
 		if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
 			return err
 		}
@@ -242,13 +244,7 @@ func (d *Driver) Create() error {
 	log.Debugf("VM CPUS: %d", d.CPU)
 	log.Debugf("VM Memory: %d", d.Memory)
 
-	cpus := d.CPU
-	if cpus < 1 {
-		cpus = int(runtime.NumCPU())
-	}
-	if cpus > 32 {
-		cpus = 32
-	}
+	cpus := vmCPUs(d.CPU)
 
 	if err := vbm("modifyvm", d.MachineName,
 		"--firmware", "bios",
@@ -294,21 +290,7 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	if err := vbm("storageattach", d.MachineName,
-		"--storagectl", "SATA",
-		"--port", "0",
-		"--device", "0",
-		"--type", "dvddrive",
-		"--medium", d.ResolveStorePath("boot2docker.iso")); err != nil {
-		return err
-	}
-
-	if err := vbm("storageattach", d.MachineName,
-		"--storagectl", "SATA",
-		"--port", "1",
-		"--device", "0",
-		"--type", "hdd",
-		"--medium", d.diskPath()); err != nil {
+	if err := d.attachStorage(); err != nil {
 		return err
 	}
 
@@ -355,7 +337,7 @@ func (d *Driver) Create() error {
 		}
 	}
 
-	log.Infof("Starting VirtualBox VM...")
+	d.Progress().Infof("Starting VirtualBox VM...")
 
 	if err := d.Start(); err != nil {
 		return err
@@ -364,6 +346,19 @@ func (d *Driver) Create() error {
 	return nil
 }
 
+// vmCPUs returns the number of CPUs of a VM created with "cpu" CPUs, all
+// the CPUs available if it is less than 1, and no more than 32.
+func vmCPUs(cpu int) int {
+	cpus := cpu
+	if cpus < 1 {
+		cpus = int(runtime.NumCPU())
+	}
+	if cpus > 32 {
+		cpus = 32
+	}
+	return cpus
+}
+
 func (d *Driver) hostOnlyIpAvailable() bool {
 	ip, err := d.GetIP()
 	if err != nil {
@@ -400,14 +395,14 @@ func (d *Driver) Start() error {
 		if err := vbm("startvm", d.MachineName, "--type", "headless"); err != nil {
 			return err
 		}
-		log.Infof("Starting VM...")
+		d.Progress().Infof("Starting VM...")
 	case state.Paused:
 		if err := vbm("controlvm", d.MachineName, "resume", "--type", "headless"); err != nil {
 			return err
 		}
-		log.Infof("Resuming VM ...")
+		d.Progress().Infof("Resuming VM ...")
 	default:
-		log.Infof("VM not in restartable state")
+		d.Progress().Infof("VM not in restartable state")
 	}
 
 	// Wait for SSH over NAT to be available before returning to user
@@ -450,7 +445,7 @@ func (d *Driver) Remove() error {
 	s, err := d.GetState()
 	if err != nil {
 		if err == ErrMachineNotExist {
-			log.Infof("machine does not exist, assuming it has been removed already")
+			d.Progress().Infof("machine does not exist, assuming it has been removed already")
 			return nil
 		}
 		return err
@@ -465,6 +460,77 @@ func (d *Driver) Remove() error {
 	return vbm("unregistervm", "--delete", d.MachineName)
 }
 
+// PrepareRename unregisters the VM, as its files move along with the
+// machine directory.  The disk and the ISO are detached first, since
+// VirtualBox refers to them by their absolute paths.
+func (d *Driver) PrepareRename(newName string) error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if s != state.Stopped {
+		return fmt.Errorf("The machine must be stopped to be renamed")
+	}
+
+	if err := d.detachStorage(); err != nil {
+		return err
+	}
+
+	return vbm("unregistervm", d.MachineName)
+}
+
+// Rename registers the VM from the new machine directory under its old
+// name and renames it, which also renames its folder.
+func (d *Driver) Rename(oldName string) error {
+	if err := vbm("registervm", d.ResolveStorePath(filepath.Join(oldName, oldName+".vbox"))); err != nil {
+		return err
+	}
+
+	if oldName != d.MachineName {
+		if err := vbm("modifyvm", oldName, "--name", d.MachineName); err != nil {
+			return err
+		}
+	}
+
+	return d.attachStorage()
+}
+
+func (d *Driver) attachStorage() error {
+	if err := vbm("storageattach", d.MachineName,
+		"--storagectl", "SATA",
+		"--port", "0",
+		"--device", "0",
+		"--type", "dvddrive",
+		"--medium", d.ResolveStorePath("boot2docker.iso")); err != nil {
+		return err
+	}
+
+	return vbm("storageattach", d.MachineName,
+		"--storagectl", "SATA",
+		"--port", "1",
+		"--device", "0",
+		"--type", "hdd",
+		"--medium", d.diskPath())
+}
+
+func (d *Driver) detachStorage() error {
+	for _, port := range []string{"0", "1"} {
+		if err := vbm("storageattach", d.MachineName,
+			"--storagectl", "SATA",
+			"--port", port,
+			"--device", "0",
+			"--medium", "none"); err != nil {
+			return err
+		}
+	}
+
+	if err := vbm("closemedium", "dvd", d.ResolveStorePath("boot2docker.iso")); err != nil {
+		return err
+	}
+
+	return vbm("closemedium", "disk", d.diskPath())
+}
+
 func (d *Driver) Restart() error {
 	s, err := d.GetState()
 	if err != nil {
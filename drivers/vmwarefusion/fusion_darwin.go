@@ -44,6 +44,7 @@ func init() {
 	drivers.Register("vmwarefusion", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "VMware Fusion virtual machines",
 	})
 }
 
@@ -155,6 +156,10 @@ func (d *Driver) GetState() (state.State, error) {
 	if stdout, _, _ := vmrun("list"); strings.Contains(stdout, d.vmxPath()) {
 		return state.Running, nil
 	}
+	// the memory of a suspended vm is kept next to its vmx
+	if _, err := os.Stat(d.vmssPath()); err == nil {
+		return state.Saved, nil
+	}
 	return state.Stopped, nil
 }
 
@@ -164,17 +169,18 @@ func (d *Driver) PreCreateCheck() error {
 
 func (d *Driver) Create() error {
 
-	b2dutils := utils.NewB2dUtils("", "")
-	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+	b2dutils := utils.NewB2dUtils("", "", d.ResolveStorePath("."))
+	b2dutils.Progress = d.Progress()
+	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL); err != nil {
 		return err
 	}
 
-	log.Infof("Creating SSH key...")
+	d.Progress().Infof("Creating SSH key...")
 	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
 		return err
 	}
 
-	log.Infof("Creating VM...")
+	d.Progress().Infof("Creating VM...")
 	if err := os.MkdirAll(d.ResolveStorePath("."), 0755); err != nil {
 		return err
 	}
@@ -203,12 +209,12 @@ func (d *Driver) Create() error {
 		}
 	}
 
-	log.Infof("Starting %s...", d.MachineName)
+	d.Progress().Infof("Starting %s...", d.MachineName)
 	vmrun("start", d.vmxPath(), "nogui")
 
 	var ip string
 
-	log.Infof("Waiting for VM to come online...")
+	d.Progress().Infof("Waiting for VM to come online...")
 	for i := 1; i <= 60; i++ {
 		ip, err = d.getIPfromDHCPLease()
 		if err != nil {
@@ -268,7 +274,7 @@ func (d *Driver) Create() error {
 }
 
 func (d *Driver) Start() error {
-	log.Infof("Starting %s...", d.MachineName)
+	d.Progress().Infof("Starting %s...", d.MachineName)
 	vmrun("start", d.vmxPath(), "nogui")
 
 	log.Debugf("Mounting Shared Folders...")
@@ -293,7 +299,7 @@ func (d *Driver) Start() error {
 }
 
 func (d *Driver) Stop() error {
-	log.Infof("Gracefully shutting down %s...", d.MachineName)
+	d.Progress().Infof("Gracefully shutting down %s...", d.MachineName)
 	vmrun("stop", d.vmxPath(), "nogui")
 	return nil
 }
@@ -306,23 +312,40 @@ func (d *Driver) Remove() error {
 			return fmt.Errorf("Error stopping VM before deletion")
 		}
 	}
-	log.Infof("Deleting %s...", d.MachineName)
+	d.Progress().Infof("Deleting %s...", d.MachineName)
 	vmrun("deleteVM", d.vmxPath(), "nogui")
 	return nil
 }
 
 func (d *Driver) Restart() error {
-	log.Infof("Gracefully restarting %s...", d.MachineName)
+	d.Progress().Infof("Gracefully restarting %s...", d.MachineName)
 	vmrun("reset", d.vmxPath(), "nogui")
 	return nil
 }
 
 func (d *Driver) Kill() error {
-	log.Infof("Forcibly halting %s...", d.MachineName)
+	d.Progress().Infof("Forcibly halting %s...", d.MachineName)
 	vmrun("stop", d.vmxPath(), "hard nogui")
 	return nil
 }
 
+// Suspend saves the memory of the vm to disk and stops it.
+func (d *Driver) Suspend() error {
+	d.Progress().Infof("Suspending %s...", d.MachineName)
+	if _, err := vmrunOut("suspend", d.vmxPath()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Resume starts a suspended vm, which resumes where it was.
+func (d *Driver) Resume() error {
+	if s, err := d.GetState(); err != nil || s != state.Saved {
+		return fmt.Errorf("%s is not suspended", d.MachineName)
+	}
+	return d.Start()
+}
+
 func (d *Driver) Upgrade() error {
 	return fmt.Errorf("VMware Fusion does not currently support the upgrade operation")
 }
@@ -331,6 +354,10 @@ func (d *Driver) vmxPath() string {
 	return d.ResolveStorePath(fmt.Sprintf("%s.vmx", d.MachineName))
 }
 
+func (d *Driver) vmssPath() string {
+	return d.ResolveStorePath(fmt.Sprintf("%s.vmss", d.MachineName))
+}
+
 func (d *Driver) vmdkPath() string {
 	return d.ResolveStorePath(fmt.Sprintf("%s.vmdk", d.MachineName))
 }
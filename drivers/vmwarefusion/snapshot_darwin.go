@@ -0,0 +1,92 @@
+package vmwarefusion
+
+import (
+	"strings"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/state"
+)
+
+// parseSnapshotList parses the output of `vmrun listSnapshots`, a count of
+// the snapshots followed by their names.  vmrun does not give snapshots an
+// ID.
+func parseSnapshotList(out string) []drivers.Snapshot {
+	snapshots := []drivers.Snapshot{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "Total snapshots:") {
+			continue
+		}
+		snapshots = append(snapshots, drivers.Snapshot{Name: line})
+	}
+	return snapshots
+}
+
+func (d *Driver) ListSnapshots() ([]drivers.Snapshot, error) {
+	stdout, err := vmrunOut("listSnapshots", d.vmxPath())
+	if err != nil {
+		return nil, err
+	}
+	return parseSnapshotList(stdout), nil
+}
+
+func (d *Driver) TakeSnapshot(name string) error {
+	if err := d.checkSnapshot(name, false); err != nil {
+		return err
+	}
+
+	d.Progress().Infof("Taking snapshot %s of %s...", name, d.MachineName)
+	_, err := vmrunOut("snapshot", d.vmxPath(), name)
+	return err
+}
+
+// RestoreSnapshot stops the VM first, so that it is left stopped, or
+// suspended if the snapshot was taken while it was running.
+func (d *Driver) RestoreSnapshot(name string) error {
+	if err := d.checkSnapshot(name, true); err != nil {
+		return err
+	}
+
+	if s, err := d.GetState(); err == nil && s == state.Running {
+		if _, err := vmrunOut("stop", d.vmxPath(), "hard"); err != nil {
+			return err
+		}
+	}
+
+	d.Progress().Infof("Restoring %s to snapshot %s...", d.MachineName, name)
+	if _, err := vmrunOut("revertToSnapshot", d.vmxPath(), name); err != nil {
+		return err
+	}
+
+	d.IPAddress = ""
+
+	return nil
+}
+
+func (d *Driver) RemoveSnapshot(name string) error {
+	if err := d.checkSnapshot(name, true); err != nil {
+		return err
+	}
+
+	_, err := vmrunOut("deleteSnapshot", d.vmxPath(), name)
+	return err
+}
+
+// checkSnapshot returns an error unless the snapshot "name" exists, or
+// does not exist, as expected.
+func (d *Driver) checkSnapshot(name string, exists bool) error {
+	snapshots, err := d.ListSnapshots()
+	if err != nil {
+		return err
+	}
+
+	_, found := drivers.FindSnapshot(snapshots, name)
+	if exists && !found {
+		return drivers.ErrSnapshotDoesNotExist{Name: name}
+	}
+	if !exists && found {
+		return drivers.ErrSnapshotExists{Name: name}
+	}
+
+	return nil
+}
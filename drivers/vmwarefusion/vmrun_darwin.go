@@ -48,6 +48,19 @@ func vmrun(args ...string) (string, string, error) {
 	return stdout.String(), stderr.String(), err
 }
 
+// vmrunOut runs vmrun and returns its output.  vmrun reports its errors on
+// stdout, so they are part of the error.
+func vmrunOut(args ...string) (string, error) {
+	stdout, stderr, err := vmrun(args...)
+	if err != nil {
+		if err == ErrVMRUNNotFound {
+			return "", err
+		}
+		return "", fmt.Errorf("vmrun %s failed: %s", args[0], strings.TrimSpace(stdout+stderr))
+	}
+	return stdout, nil
+}
+
 // Make a vmdk disk image with the given size (in MB).
 func vdiskmanager(dest string, size int) error {
 	cmd := exec.Command(vdiskmanbin, "-c", "-t", "0", "-s", fmt.Sprintf("%dMB", size), "-a", "lsilogic", dest)
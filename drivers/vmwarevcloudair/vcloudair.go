@@ -22,7 +22,7 @@ import (
 type Driver struct {
 	*drivers.BaseDriver
 	UserName     string
-	UserPassword string
+	UserPassword string `secret:"true"`
 	ComputeID    string
 	VDCID        string
 	OrgVDCNet    string
@@ -41,6 +41,8 @@ func init() {
 	drivers.Register("vmwarevcloudair", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "VMware vCloud Air virtual machines",
+		RequiredFlags:  []string{"vmwarevcloudair-username", "vmwarevcloudair-password", "vmwarevcloudair-vdcid", "vmwarevcloudair-publicip"},
 	})
 }
 
@@ -255,7 +257,7 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	log.Infof("Connecting to vCloud Air...")
+	d.Progress().Infof("Connecting to vCloud Air...")
 	// Authenticate to vCloud Air
 	v, err := p.Authenticate(d.UserName, d.UserPassword, d.ComputeID, d.VDCID)
 	if err != nil {
@@ -301,7 +303,7 @@ func (d *Driver) Create() error {
 	// Create a new empty vApp
 	vapp := govcloudair.NewVApp(p)
 
-	log.Infof("Creating a new vApp: %s...", d.MachineName)
+	d.Progress().Infof("Creating a new vApp: %s...", d.MachineName)
 	// Compose the vApp with ComposeVApp
 	task, err := vapp.ComposeVApp(net, vapptemplate, d.MachineName, "Container Host created with Docker Host")
 	if err != nil {
@@ -347,13 +349,13 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	log.Infof("Waiting for the VM to power on and run the customization script...")
+	d.Progress().Infof("Waiting for the VM to power on and run the customization script...")
 
 	if err = task.WaitTaskCompletion(); err != nil {
 		return err
 	}
 
-	log.Infof("Creating NAT and Firewall Rules on %s...", d.EdgeGateway)
+	d.Progress().Infof("Creating NAT and Firewall Rules on %s...", d.EdgeGateway)
 	task, err = edge.Create1to1Mapping(vapp.VApp.Children.VM[0].NetworkConnectionSection.NetworkConnection.IPAddress, d.PublicIP, d.MachineName)
 	if err != nil {
 		return err
@@ -382,7 +384,7 @@ func (d *Driver) Remove() error {
 		return err
 	}
 
-	log.Infof("Connecting to vCloud Air...")
+	d.Progress().Infof("Connecting to vCloud Air...")
 	// Authenticate to vCloud Air
 	v, err := p.Authenticate(d.UserName, d.UserPassword, d.ComputeID, d.VDCID)
 	if err != nil {
@@ -397,7 +399,7 @@ func (d *Driver) Remove() error {
 
 	vapp, err := v.FindVAppByID(d.VAppID)
 	if err != nil {
-		log.Infof("Can't find the vApp, assuming it was deleted already...")
+		d.Progress().Infof("Can't find the vApp, assuming it was deleted already...")
 		return nil
 	}
 
@@ -406,7 +408,7 @@ func (d *Driver) Remove() error {
 		return err
 	}
 
-	log.Infof("Removing NAT and Firewall Rules on %s...", d.EdgeGateway)
+	d.Progress().Infof("Removing NAT and Firewall Rules on %s...", d.EdgeGateway)
 	task, err := edge.Remove1to1Mapping(vapp.VApp.Children.VM[0].NetworkConnectionSection.NetworkConnection.IPAddress, d.PublicIP)
 	if err != nil {
 		return err
@@ -417,7 +419,7 @@ func (d *Driver) Remove() error {
 
 	if status == "POWERED_ON" {
 		// If it's powered on, power it off before deleting
-		log.Infof("Powering Off %s...", d.MachineName)
+		d.Progress().Infof("Powering Off %s...", d.MachineName)
 		task, err = vapp.PowerOff()
 		if err != nil {
 			return err
@@ -437,7 +439,7 @@ func (d *Driver) Remove() error {
 		return err
 	}
 
-	log.Infof("Deleting %s...", d.MachineName)
+	d.Progress().Infof("Deleting %s...", d.MachineName)
 	task, err = vapp.Delete()
 	if err != nil {
 		return err
@@ -460,7 +462,7 @@ func (d *Driver) Start() error {
 		return err
 	}
 
-	log.Infof("Connecting to vCloud Air...")
+	d.Progress().Infof("Connecting to vCloud Air...")
 	// Authenticate to vCloud Air
 	v, err := p.Authenticate(d.UserName, d.UserPassword, d.ComputeID, d.VDCID)
 	if err != nil {
@@ -478,7 +480,7 @@ func (d *Driver) Start() error {
 	}
 
 	if status == "POWERED_OFF" {
-		log.Infof("Starting %s...", d.MachineName)
+		d.Progress().Infof("Starting %s...", d.MachineName)
 		task, err := vapp.PowerOn()
 		if err != nil {
 			return err
@@ -504,7 +506,7 @@ func (d *Driver) Stop() error {
 		return err
 	}
 
-	log.Infof("Connecting to vCloud Air...")
+	d.Progress().Infof("Connecting to vCloud Air...")
 	// Authenticate to vCloud Air
 	v, err := p.Authenticate(d.UserName, d.UserPassword, d.ComputeID, d.VDCID)
 	if err != nil {
@@ -522,7 +524,7 @@ func (d *Driver) Stop() error {
 	}
 
 	if status == "POWERED_ON" {
-		log.Infof("Shutting down %s...", d.MachineName)
+		d.Progress().Infof("Shutting down %s...", d.MachineName)
 		task, err := vapp.Shutdown()
 		if err != nil {
 			return err
@@ -549,7 +551,7 @@ func (d *Driver) Restart() error {
 		return err
 	}
 
-	log.Infof("Connecting to vCloud Air...")
+	d.Progress().Infof("Connecting to vCloud Air...")
 	// Authenticate to vCloud Air
 	v, err := p.Authenticate(d.UserName, d.UserPassword, d.ComputeID, d.VDCID)
 	if err != nil {
@@ -568,7 +570,7 @@ func (d *Driver) Restart() error {
 
 	if status == "POWERED_ON" {
 		// If it's powered on, restart the machine
-		log.Infof("Restarting %s...", d.MachineName)
+		d.Progress().Infof("Restarting %s...", d.MachineName)
 		task, err := vapp.Reset()
 		if err != nil {
 			return err
@@ -579,7 +581,7 @@ func (d *Driver) Restart() error {
 
 	} else {
 		// If it's not powered on, start it.
-		log.Infof("Docker host %s is powered off, powering it back on...", d.MachineName)
+		d.Progress().Infof("Docker host %s is powered off, powering it back on...", d.MachineName)
 		task, err := vapp.PowerOn()
 		if err != nil {
 			return err
@@ -604,7 +606,7 @@ func (d *Driver) Kill() error {
 		return err
 	}
 
-	log.Infof("Connecting to vCloud Air...")
+	d.Progress().Infof("Connecting to vCloud Air...")
 	// Authenticate to vCloud Air
 	v, err := p.Authenticate(d.UserName, d.UserPassword, d.ComputeID, d.VDCID)
 	if err != nil {
@@ -622,7 +624,7 @@ func (d *Driver) Kill() error {
 	}
 
 	if status == "POWERED_ON" {
-		log.Infof("Stopping %s...", d.MachineName)
+		d.Progress().Infof("Stopping %s...", d.MachineName)
 		task, err := vapp.PowerOff()
 		if err != nil {
 			return err
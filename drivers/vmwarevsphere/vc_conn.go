@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/docker/machine/drivers/vmwarevsphere/errors"
-	"github.com/docker/machine/log"
 )
 
 type VcConn struct {
@@ -40,7 +39,7 @@ func (conn VcConn) DatastoreMkdir(dirName string) error {
 		return nil
 	}
 
-	log.Infof("Creating directory %s on datastore %s of vCenter %s... ",
+	conn.driver.Progress().Infof("Creating directory %s on datastore %s of vCenter %s... ",
 		dirName, conn.driver.Datastore, conn.driver.IP)
 
 	args := []string{"datastore.mkdir"}
@@ -64,11 +63,11 @@ func (conn VcConn) DatastoreMkdir(dirName string) error {
 func (conn VcConn) DatastoreUpload(localPath, destination string) error {
 	stdout, err := conn.DatastoreLs(destination)
 	if err == nil && strings.Contains(stdout, B2DISOName) {
-		log.Infof("boot2docker ISO already uploaded, skipping upload... ")
+		conn.driver.Progress().Infof("boot2docker ISO already uploaded, skipping upload... ")
 		return nil
 	}
 
-	log.Infof("Uploading %s to %s on datastore %s of vCenter %s... ",
+	conn.driver.Progress().Infof("Uploading %s to %s on datastore %s of vCenter %s... ",
 		localPath, destination, conn.driver.Datastore, conn.driver.IP)
 
 	dsPath := fmt.Sprintf("%s/%s", destination, B2DISOName)
@@ -99,7 +98,7 @@ func (conn VcConn) VMInfo() (string, error) {
 }
 
 func (conn VcConn) VMCreate(isoPath string) error {
-	log.Infof("Creating virtual machine %s of vCenter %s... ",
+	conn.driver.Progress().Infof("Creating virtual machine %s of vCenter %s... ",
 		conn.driver.MachineName, conn.driver.IP)
 
 	args := []string{"vm.create"}
@@ -131,7 +130,7 @@ func (conn VcConn) VMCreate(isoPath string) error {
 }
 
 func (conn VcConn) VMPowerOn() error {
-	log.Infof("Powering on virtual machine %s of vCenter %s... ",
+	conn.driver.Progress().Infof("Powering on virtual machine %s of vCenter %s... ",
 		conn.driver.MachineName, conn.driver.IP)
 
 	args := []string{"vm.power"}
@@ -148,7 +147,7 @@ func (conn VcConn) VMPowerOn() error {
 }
 
 func (conn VcConn) VMPowerOff() error {
-	log.Infof("Powering off virtual machine %s of vCenter %s... ",
+	conn.driver.Progress().Infof("Powering off virtual machine %s of vCenter %s... ",
 		conn.driver.MachineName, conn.driver.IP)
 
 	args := []string{"vm.power"}
@@ -165,7 +164,7 @@ func (conn VcConn) VMPowerOff() error {
 }
 
 func (conn VcConn) VMShutdown() error {
-	log.Infof("Powering off virtual machine %s of vCenter %s... ",
+	conn.driver.Progress().Infof("Powering off virtual machine %s of vCenter %s... ",
 		conn.driver.MachineName, conn.driver.IP)
 
 	args := []string{"vm.power"}
@@ -182,7 +181,7 @@ func (conn VcConn) VMShutdown() error {
 }
 
 func (conn VcConn) VMDestroy() error {
-	log.Infof("Deleting virtual machine %s of vCenter %s... ",
+	conn.driver.Progress().Infof("Deleting virtual machine %s of vCenter %s... ",
 		conn.driver.MachineName, conn.driver.IP)
 
 	args := []string{"vm.destroy"}
@@ -40,7 +40,7 @@ type Driver struct {
 	Boot2DockerURL string
 	IP             string
 	Username       string
-	Password       string
+	Password       string `secret:"true"`
 	Network        string
 	Datastore      string
 	Datacenter     string
@@ -53,6 +53,7 @@ func init() {
 	drivers.Register("vmwarevsphere", &drivers.RegisteredDriver{
 		New:            NewDriver,
 		GetCreateFlags: GetCreateFlags,
+		Description:    "VMware vSphere virtual machines",
 	})
 }
 
@@ -166,7 +167,8 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.SwarmHost = flags.String("swarm-host")
 	d.SwarmDiscovery = flags.String("swarm-discovery")
 
-	imgPath := utils.GetMachineCacheDir()
+	// the ISO is cached in the store of the machine, as NewB2dUtils does
+	imgPath := filepath.Join(filepath.Dir(filepath.Dir(d.ResolveStorePath("."))), "cache")
 	commonIsoPath := filepath.Join(imgPath, isoFilename)
 
 	d.ISO = path.Join(commonIsoPath)
@@ -225,18 +227,19 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	b2dutils := utils.NewB2dUtils("", "")
-	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+	b2dutils := utils.NewB2dUtils("", "", d.ResolveStorePath("."))
+	b2dutils.Progress = d.Progress()
+	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL); err != nil {
 		return err
 	}
 
-	log.Infof("Generating SSH Keypair...")
+	d.Progress().Infof("Generating SSH Keypair...")
 	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
 		return err
 	}
 
 	vcConn := NewVcConn(d)
-	log.Infof("Uploading Boot2docker ISO ...")
+	d.Progress().Infof("Uploading Boot2docker ISO ...")
 	if err := vcConn.DatastoreMkdir(d.MachineName); err != nil {
 		return err
 	}
@@ -255,7 +258,7 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	log.Infof("Configuring the virtual machine %s... ", d.MachineName)
+	d.Progress().Infof("Configuring the virtual machine %s... ", d.MachineName)
 	if err := vcConn.VMDiskCreate(); err != nil {
 		return err
 	}
@@ -294,7 +297,7 @@ func (d *Driver) Start() error {
 
 	switch machineState {
 	case state.Running:
-		log.Infof("VM %s has already been started", d.MachineName)
+		d.Progress().Infof("VM %s has already been started", d.MachineName)
 		return nil
 	case state.Stopped:
 		// TODO add transactional or error handling in the following steps
@@ -0,0 +1,713 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
+	"github.com/docker/machine/version"
+	"golang.org/x/net/context"
+)
+
+const (
+	// APIVersion is the version of the machine API, which is part of the
+	// path of its endpoints.  It is bumped for incompatible changes.
+	APIVersion = "v1"
+
+	apiPrefix = "/api/" + APIVersion
+
+	sshTunnelDialTimeout = 10 * time.Second
+)
+
+// VersionInfo is the version of an APIServer.
+type VersionInfo struct {
+	Version    string
+	GitCommit  string
+	APIVersion string
+}
+
+// CreateRequest asks an APIServer to create a machine.  Options are the
+// values of the create flags, by name without the leading dashes, as in
+// templates.  Profile selects a profile of the server, e.g. to use the
+// credentials it holds.
+type CreateRequest struct {
+	Name    string
+	Driver  string                 `json:",omitempty"`
+	Profile string                 `json:",omitempty"`
+	Options map[string]interface{} `json:",omitempty"`
+	Labels  map[string]string      `json:",omitempty"`
+}
+
+// CertBundle holds the certificates a client needs to connect to the Docker
+// daemon of a machine, in PEM form.
+type CertBundle struct {
+	CA   string
+	Cert string
+	Key  string
+}
+
+// SSHKey is what a client needs to log into a machine through the SSH
+// tunnel of an APIServer.
+type SSHKey struct {
+	Username string
+	Key      string
+}
+
+// LabelsRequest asks an APIServer to set and remove labels of a machine.
+type LabelsRequest struct {
+	Set    map[string]string `json:",omitempty"`
+	Remove []string          `json:",omitempty"`
+}
+
+// RenameRequest asks an APIServer to rename a machine.
+type RenameRequest struct {
+	Name string
+}
+
+// SnapshotRequest asks an APIServer to take a snapshot of a machine.
+type SnapshotRequest struct {
+	Name string
+}
+
+// APIErrorHostDoesNotExist is the code of the errors about a machine which
+// does not exist, which clients return as ErrHostDoesNotExist.
+const APIErrorHostDoesNotExist = "HostDoesNotExist"
+
+// APIError is the body of the error responses of the machine API.  Code is
+// set for the errors which clients tell apart.
+type APIError struct {
+	Error string
+	Code  string `json:",omitempty"`
+}
+
+// CreateOptionsFunc returns the driver and the options of a machine
+// created with the values of a CreateRequest, along with the defaults of
+// the server.
+type CreateOptionsFunc func(req CreateRequest) (string, *HostOptions, drivers.DriverOptions, error)
+
+// APIServer serves the operations of a Provider as a JSON API over HTTP.
+// The operations which change machines run as jobs in the background;
+// clients poll the job, or follow its log, to know when it is done.
+type APIServer struct {
+	provider      *Provider
+	createOptions CreateOptionsFunc
+	jobs          *jobManager
+
+	// serial makes the jobs on VirtualBox machines run one at a time, as
+	// VirtualBox is temperamental about doing things concurrently.
+	serial sync.Mutex
+}
+
+// NewAPIServer returns a server for the machines of the provider.  The
+// progress of the operations of the provider is logged, and kept in the
+// logs of the jobs.
+func NewAPIServer(provider *Provider, createOptions CreateOptionsFunc) *APIServer {
+	s := &APIServer{
+		provider:      provider,
+		createOptions: createOptions,
+		jobs:          newJobManager(),
+	}
+	provider.SetProgressSink(progress.SinkFunc(s.publish))
+	return s
+}
+
+func (s *APIServer) publish(e progress.Event) {
+	progress.LogSink.Publish(e)
+	s.jobs.Publish(e)
+}
+
+func (s *APIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("api server: %s %s", r.Method, r.URL.Path)
+
+	if !strings.HasPrefix(r.URL.Path, apiPrefix+"/") {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("Unknown endpoint %s, the API version is %s", r.URL.Path, APIVersion))
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, apiPrefix+"/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "version":
+		s.route(w, r, map[string]http.HandlerFunc{"GET": s.getVersion})
+	case len(parts) == 1 && parts[0] == "machines":
+		s.route(w, r, map[string]http.HandlerFunc{
+			"GET":  s.listMachines,
+			"POST": s.createMachine,
+		})
+	case len(parts) == 2 && parts[0] == "machines":
+		name := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"GET":    func(w http.ResponseWriter, r *http.Request) { s.getMachine(w, r, name) },
+			"DELETE": func(w http.ResponseWriter, r *http.Request) { s.removeMachine(w, r, name) },
+		})
+	case len(parts) == 3 && parts[0] == "machines" && parts[2] == "ssh":
+		name := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"POST": func(w http.ResponseWriter, r *http.Request) { s.tunnelSSH(w, r, name) },
+		})
+	case len(parts) == 3 && parts[0] == "machines" && parts[2] == "labels":
+		name := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"POST": func(w http.ResponseWriter, r *http.Request) { s.updateLabels(w, r, name) },
+		})
+	case len(parts) == 3 && parts[0] == "machines" && parts[2] == "rename":
+		name := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"POST": func(w http.ResponseWriter, r *http.Request) { s.renameMachine(w, r, name) },
+		})
+	case len(parts) == 3 && parts[0] == "machines" && parts[2] == "resize":
+		name := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"POST": func(w http.ResponseWriter, r *http.Request) { s.resizeMachine(w, r, name) },
+		})
+	case len(parts) == 3 && parts[0] == "machines" && parts[2] == "snapshots":
+		name := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"GET":  func(w http.ResponseWriter, r *http.Request) { s.listSnapshots(w, r, name) },
+			"POST": func(w http.ResponseWriter, r *http.Request) { s.takeSnapshot(w, r, name) },
+		})
+	case len(parts) == 4 && parts[0] == "machines" && parts[2] == "snapshots":
+		name, snapshot := parts[1], parts[3]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"DELETE": func(w http.ResponseWriter, r *http.Request) { s.removeSnapshot(w, r, name, snapshot) },
+		})
+	case len(parts) == 5 && parts[0] == "machines" && parts[2] == "snapshots" && parts[4] == "restore":
+		name, snapshot := parts[1], parts[3]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"POST": func(w http.ResponseWriter, r *http.Request) { s.restoreSnapshot(w, r, name, snapshot) },
+		})
+	case len(parts) == 3 && parts[0] == "machines":
+		name, endpoint := parts[1], parts[2]
+		if _, ok := hostActions[endpoint]; ok {
+			s.route(w, r, map[string]http.HandlerFunc{
+				"POST": func(w http.ResponseWriter, r *http.Request) { s.runAction(w, r, name, endpoint) },
+			})
+			return
+		}
+		s.route(w, r, map[string]http.HandlerFunc{
+			"GET": func(w http.ResponseWriter, r *http.Request) { s.getMachineValue(w, r, name, endpoint) },
+		})
+	case len(parts) == 1 && parts[0] == "jobs":
+		s.route(w, r, map[string]http.HandlerFunc{"GET": s.listJobs})
+	case len(parts) == 2 && parts[0] == "jobs":
+		id := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"GET":    func(w http.ResponseWriter, r *http.Request) { s.getJob(w, r, id) },
+			"DELETE": func(w http.ResponseWriter, r *http.Request) { s.cancelJob(w, r, id) },
+		})
+	case len(parts) == 3 && parts[0] == "jobs" && parts[2] == "log":
+		id := parts[1]
+		s.route(w, r, map[string]http.HandlerFunc{
+			"GET": func(w http.ResponseWriter, r *http.Request) { s.getJobLog(w, r, id) },
+		})
+	default:
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("Unknown endpoint %s", r.URL.Path))
+	}
+}
+
+func (s *APIServer) route(w http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("Method %s not allowed on %s", r.Method, r.URL.Path))
+		return
+	}
+	handler(w, r)
+}
+
+func (s *APIServer) getVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, VersionInfo{
+		Version:    version.Version,
+		GitCommit:  version.GitCommit,
+		APIVersion: APIVersion,
+	})
+}
+
+func (s *APIServer) listMachines(w http.ResponseWriter, r *http.Request) {
+	machines, err := s.provider.ListMachines()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, machines)
+}
+
+func (s *APIServer) getMachine(w http.ResponseWriter, r *http.Request, name string) {
+	if !ValidateHostName(name) {
+		writeAPIError(w, http.StatusBadRequest, ErrInvalidHostname)
+		return
+	}
+
+	machine, err := s.provider.GetMachine(name)
+	if err != nil {
+		writeProviderError(w, err)
+		return
+	}
+	writeJSON(w, machine)
+}
+
+// getMachineValue serves the ip, url, env, certs and ssh-key endpoints of a
+// machine.
+func (s *APIServer) getMachineValue(w http.ResponseWriter, r *http.Request, name, endpoint string) {
+	switch endpoint {
+	case "ip", "url", "env", "certs", "ssh-key":
+	default:
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("Unknown endpoint %s", r.URL.Path))
+		return
+	}
+
+	host, ok := s.getHost(w, name)
+	if !ok {
+		return
+	}
+	defer host.Close()
+
+	switch endpoint {
+	case "certs":
+		bundle, err := getCertBundle(host)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, bundle)
+		return
+	case "ssh-key":
+		key, err := ioutil.ReadFile(host.Driver.GetSSHKeyPath())
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, fmt.Errorf("Error reading the SSH key of %s: %s", name, err))
+			return
+		}
+		writeJSON(w, SSHKey{
+			Username: host.Driver.GetSSHUsername(),
+			Key:      string(key),
+		})
+		return
+	}
+
+	if endpoint == "ip" {
+		ip, err := host.Driver.GetIP()
+		if err != nil {
+			writeAPIError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, map[string]string{"IP": ip})
+		return
+	}
+
+	u, err := host.GetURL()
+	if err != nil {
+		writeAPIError(w, http.StatusConflict, err)
+		return
+	}
+
+	if endpoint == "url" {
+		writeJSON(w, map[string]string{"URL": u})
+		return
+	}
+
+	if swarmMaster, _ := strconv.ParseBool(r.URL.Query().Get("swarm")); swarmMaster {
+		if host.HostOptions == nil || host.HostOptions.SwarmOptions == nil {
+			writeAPIError(w, http.StatusConflict, fmt.Errorf("%s is not a swarm master", name))
+			return
+		}
+		if u, err = swarm.GetMasterURL(u, *host.HostOptions.SwarmOptions); err != nil {
+			writeAPIError(w, http.StatusConflict, err)
+			return
+		}
+	}
+
+	// The client knows where its certificates are, so it sets
+	// DOCKER_CERT_PATH.
+	writeJSON(w, map[string]string{
+		"DOCKER_TLS_VERIFY":   "1",
+		"DOCKER_HOST":         u,
+		"DOCKER_MACHINE_NAME": name,
+	})
+}
+
+// getCertBundle returns the certificates in the directory of the machine,
+// which the env command points Docker to locally.  Machines without TLS,
+// e.g. of the none driver, have none.
+func getCertBundle(host *Host) (CertBundle, error) {
+	files := []string{"ca.pem", "cert.pem", "key.pem"}
+	data := make([]string, len(files))
+	for i, file := range files {
+		b, err := ioutil.ReadFile(filepath.Join(host.StorePath, file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return CertBundle{}, fmt.Errorf("Error reading the certificates of %s: %s", host.Name, err)
+		}
+		data[i] = string(b)
+	}
+
+	return CertBundle{
+		CA:   data[0],
+		Cert: data[1],
+		Key:  data[2],
+	}, nil
+}
+
+// tunnelSSH relays the connection of the client to the SSH port of the
+// machine, so that clients can log into machines which only the server can
+// reach.  The server answers 101 Switching Protocols, after which the
+// connection carries the SSH session.
+func (s *APIServer) tunnelSSH(w http.ResponseWriter, r *http.Request, name string) {
+	host, ok := s.getHost(w, name)
+	if !ok {
+		return
+	}
+
+	addr, err := host.GetSSHAddress()
+	host.Close()
+	if err != nil {
+		writeAPIError(w, http.StatusConflict, err)
+		return
+	}
+
+	backend, err := net.DialTimeout("tcp", addr, sshTunnelDialTimeout)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, fmt.Errorf("Error connecting to the SSH port of %s: %s", name, err))
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		backend.Close()
+		writeAPIError(w, http.StatusInternalServerError, errors.New("The connection can not be tunnelled"))
+		return
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		backend.Close()
+		log.Errorf("Error tunnelling SSH to %s: %s", name, err)
+		return
+	}
+
+	log.Debugf("api server: tunnelling SSH from %s to %s (%s)", r.RemoteAddr, name, addr)
+
+	if _, err := fmt.Fprint(conn, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: ssh\r\n\r\n"); err != nil {
+		conn.Close()
+		backend.Close()
+		return
+	}
+
+	relay(conn, buf, backend)
+}
+
+// relay copies between the client, whose data may be buffered in r, and the
+// backend until either side is done, then closes both connections.
+func relay(client net.Conn, r io.Reader, backend net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backend, r)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, backend)
+		done <- struct{}{}
+	}()
+
+	<-done
+	client.Close()
+	backend.Close()
+}
+
+func (s *APIServer) createMachine(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if !ValidateHostName(req.Name) {
+		writeAPIError(w, http.StatusBadRequest, ErrInvalidHostname)
+		return
+	}
+
+	exists, err := s.provider.Exists(req.Name)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if exists {
+		writeAPIError(w, http.StatusConflict, fmt.Errorf("Machine %s already exists", req.Name))
+		return
+	}
+
+	driver, hostOptions, driverOptions, err := s.createOptions(req)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job := s.jobs.Start(req.Name, "create", func(ctx context.Context) error {
+		if driver == "virtualbox" {
+			s.serial.Lock()
+			defer s.serial.Unlock()
+		}
+		host, err := s.provider.CreateContext(ctx, req.Name, driver, hostOptions, driverOptions)
+		if host != nil {
+			host.Close()
+		}
+		return err
+	})
+	writeJob(w, job)
+}
+
+func (s *APIServer) removeMachine(w http.ResponseWriter, r *http.Request, name string) {
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+
+	s.startJob(w, name, "rm", func(ctx context.Context) error {
+		return s.provider.RemoveContext(ctx, name, force)
+	})
+}
+
+func (s *APIServer) runAction(w http.ResponseWriter, r *http.Request, name, action string) {
+	s.startJob(w, name, action, func(ctx context.Context) error {
+		return s.provider.RunAction(ctx, name, action)
+	})
+}
+
+func (s *APIServer) updateLabels(w http.ResponseWriter, r *http.Request, name string) {
+	var req LabelsRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if !ValidateHostName(name) {
+		writeAPIError(w, http.StatusBadRequest, ErrInvalidHostname)
+		return
+	}
+
+	if err := s.provider.UpdateLabels(name, req.Set, req.Remove); err != nil {
+		writeProviderError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *APIServer) renameMachine(w http.ResponseWriter, r *http.Request, name string) {
+	var req RenameRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	if !ValidateHostName(req.Name) {
+		writeAPIError(w, http.StatusBadRequest, ErrInvalidHostname)
+		return
+	}
+
+	s.startJob(w, name, "rename", func(ctx context.Context) error {
+		return s.provider.Rename(name, req.Name)
+	})
+}
+
+func (s *APIServer) resizeMachine(w http.ResponseWriter, r *http.Request, name string) {
+	var opts drivers.ResizeOptions
+	if !decodeRequest(w, r, &opts) {
+		return
+	}
+
+	s.startJob(w, name, "resize", func(ctx context.Context) error {
+		return s.provider.Resize(name, opts)
+	})
+}
+
+func (s *APIServer) listSnapshots(w http.ResponseWriter, r *http.Request, name string) {
+	if !ValidateHostName(name) {
+		writeAPIError(w, http.StatusBadRequest, ErrInvalidHostname)
+		return
+	}
+
+	snapshots, err := s.provider.ListSnapshots(name)
+	if err != nil {
+		writeProviderError(w, err)
+		return
+	}
+	if snapshots == nil {
+		snapshots = []drivers.Snapshot{}
+	}
+	writeJSON(w, snapshots)
+}
+
+func (s *APIServer) takeSnapshot(w http.ResponseWriter, r *http.Request, name string) {
+	var req SnapshotRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	s.startJob(w, name, "snapshot create", func(ctx context.Context) error {
+		return s.provider.TakeSnapshot(name, req.Name)
+	})
+}
+
+func (s *APIServer) restoreSnapshot(w http.ResponseWriter, r *http.Request, name, snapshot string) {
+	s.startJob(w, name, "snapshot restore", func(ctx context.Context) error {
+		return s.provider.RestoreSnapshot(name, snapshot)
+	})
+}
+
+func (s *APIServer) removeSnapshot(w http.ResponseWriter, r *http.Request, name, snapshot string) {
+	s.startJob(w, name, "snapshot rm", func(ctx context.Context) error {
+		return s.provider.RemoveSnapshot(name, snapshot)
+	})
+}
+
+// startJob starts a job running op on the machine "name", once it is known
+// to exist, and writes the job.  The jobs on VirtualBox machines run one at
+// a time.
+func (s *APIServer) startJob(w http.ResponseWriter, name, action string, op func(ctx context.Context) error) {
+	host, ok := s.getHost(w, name)
+	if !ok {
+		return
+	}
+	driverName := host.DriverName
+	host.Close()
+
+	job := s.jobs.Start(name, action, func(ctx context.Context) error {
+		if driverName == "virtualbox" {
+			s.serial.Lock()
+			defer s.serial.Unlock()
+		}
+		return op(ctx)
+	})
+	writeJob(w, job)
+}
+
+// getHost returns the machine "name", or writes the error getting it.
+func (s *APIServer) getHost(w http.ResponseWriter, name string) (*Host, bool) {
+	if !ValidateHostName(name) {
+		writeAPIError(w, http.StatusBadRequest, ErrInvalidHostname)
+		return nil, false
+	}
+
+	host, err := s.provider.Get(name)
+	if err != nil {
+		writeProviderError(w, err)
+		return nil, false
+	}
+	return host, true
+}
+
+func (s *APIServer) listJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.jobs.List())
+}
+
+func (s *APIServer) getJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := s.jobs.Get(id)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("Job %s does not exist", id))
+		return
+	}
+	writeJSON(w, job)
+}
+
+func (s *APIServer) cancelJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := s.jobs.Cancel(id)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("Job %s does not exist", id))
+		return
+	}
+	writeJSON(w, job)
+}
+
+// getJobLog writes the log of a job, one JSON encoded progress.Event per
+// line.  With follow set, the events are streamed until the job is done.
+func (s *APIServer) getJobLog(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := s.jobs.Get(id); !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("Job %s does not exist", id))
+		return
+	}
+
+	follow, _ := strconv.ParseBool(r.URL.Query().Get("follow"))
+
+	var gone <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		gone = notifier.CloseNotify()
+	}
+	flusher, _ := w.(http.Flusher)
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+
+	from := 0
+	for {
+		events, done, changed, ok := s.jobs.Log(id, from)
+		if !ok {
+			return
+		}
+
+		for _, e := range events {
+			if err := encoder.Encode(e); err != nil {
+				return
+			}
+		}
+		from += len(events)
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if done || !follow {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-gone:
+			return
+		}
+	}
+}
+
+// writeJob writes a job which was started, and where to poll it.
+func writeJob(w http.ResponseWriter, job Job) {
+	w.Header().Set("Location", apiPrefix+"/jobs/"+job.ID)
+	writeJSONStatus(w, http.StatusAccepted, job)
+}
+
+// decodeRequest decodes the body of the request into v, or writes the error
+// decoding it.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("Error decoding the request: %s", err))
+		return false
+	}
+	return true
+}
+
+// writeProviderError writes an error returned by the provider with the
+// status which fits it.
+func writeProviderError(w http.ResponseWriter, err error) {
+	switch err.(type) {
+	case ErrHostDoesNotExist:
+		writeAPIError(w, http.StatusNotFound, err)
+	case ErrHostLocked:
+		writeAPIError(w, http.StatusConflict, err)
+	case ErrNotSupported:
+		writeAPIError(w, http.StatusNotImplemented, err)
+	default:
+		writeAPIError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	apiErr := APIError{Error: err.Error()}
+	if _, ok := err.(ErrHostDoesNotExist); ok {
+		apiErr.Code = APIErrorHostDoesNotExist
+	}
+	writeJSONStatus(w, status, apiErr)
+}
@@ -0,0 +1,241 @@
+package libmachine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/progress"
+)
+
+func getTestAPIServer(t *testing.T) *httptest.Server {
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createOptions := func(req CreateRequest) (string, *HostOptions, drivers.DriverOptions, error) {
+		hostOptions := &HostOptions{
+			EngineOptions: &engine.EngineOptions{},
+			SwarmOptions:  &swarm.SwarmOptions{},
+			AuthOptions: &auth.AuthOptions{
+				CaCertPath:     hostTestCaCert,
+				PrivateKeyPath: hostTestPrivateKey,
+			},
+			Labels: req.Labels,
+		}
+		return hostTestDriverName, hostOptions, getTestDriverFlags(), nil
+	}
+
+	return httptest.NewServer(NewAPIServer(provider, createOptions))
+}
+
+func doAPIRequest(t *testing.T, method, url string, body, v interface{}) int {
+	var data []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = b
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func waitForTestJob(t *testing.T, serverURL string, job Job) Job {
+	for i := 0; i < 100 && !job.Done(); i++ {
+		time.Sleep(50 * time.Millisecond)
+		doAPIRequest(t, "GET", serverURL+apiPrefix+"/jobs/"+job.ID, nil, &job)
+	}
+	if !job.Done() {
+		t.Fatalf("job %s did not finish", job.ID)
+	}
+	return job
+}
+
+func TestAPIServerVersion(t *testing.T) {
+	defer cleanup()
+
+	server := getTestAPIServer(t)
+	defer server.Close()
+
+	var info VersionInfo
+	if status := doAPIRequest(t, "GET", server.URL+apiPrefix+"/version", nil, &info); status != http.StatusOK {
+		t.Fatalf("expected status 200; received %d", status)
+	}
+	if info.APIVersion != APIVersion {
+		t.Fatalf("expected API version %s; received %s", APIVersion, info.APIVersion)
+	}
+}
+
+func TestAPIServerCreateAndRemove(t *testing.T) {
+	defer cleanup()
+
+	server := getTestAPIServer(t)
+	defer server.Close()
+
+	var job Job
+	req := CreateRequest{
+		Name:   hostTestName,
+		Driver: "none",
+		Labels: map[string]string{"env": "test"},
+	}
+	if status := doAPIRequest(t, "POST", server.URL+apiPrefix+"/machines", req, &job); status != http.StatusAccepted {
+		t.Fatalf("expected status 202; received %d", status)
+	}
+	if job = waitForTestJob(t, server.URL, job); job.State != JobSucceeded {
+		t.Fatalf("expected the creation to succeed; it %s: %s", job.State, job.Error)
+	}
+
+	var apiErr APIError
+	if status := doAPIRequest(t, "POST", server.URL+apiPrefix+"/machines", req, &apiErr); status != http.StatusConflict {
+		t.Fatalf("expected status 409 creating an existing machine; received %d", status)
+	}
+
+	var machines []MachineInfo
+	doAPIRequest(t, "GET", server.URL+apiPrefix+"/machines", nil, &machines)
+	if len(machines) != 1 || machines[0].Name != hostTestName || machines[0].DriverName != "none" {
+		t.Fatalf("unexpected machines: %+v", machines)
+	}
+	if machines[0].Labels["env"] != "test" {
+		t.Fatal("expected the machine to have its labels")
+	}
+
+	var urlInfo map[string]string
+	doAPIRequest(t, "GET", server.URL+apiPrefix+"/machines/"+hostTestName+"/url", nil, &urlInfo)
+	if urlInfo["URL"] != "unix:///var/run/docker.sock" {
+		t.Fatalf("unexpected URL: %s", urlInfo["URL"])
+	}
+
+	if status := doAPIRequest(t, "DELETE", server.URL+apiPrefix+"/machines/"+hostTestName, nil, &job); status != http.StatusAccepted {
+		t.Fatalf("expected status 202; received %d", status)
+	}
+	if job = waitForTestJob(t, server.URL, job); job.State != JobSucceeded {
+		t.Fatalf("expected the removal to succeed; it %s: %s", job.State, job.Error)
+	}
+
+	if status := doAPIRequest(t, "GET", server.URL+apiPrefix+"/machines/"+hostTestName, nil, &apiErr); status != http.StatusNotFound {
+		t.Fatalf("expected status 404 for a removed machine; received %d", status)
+	}
+}
+
+func TestAPIServerJobLog(t *testing.T) {
+	defer cleanup()
+
+	server := getTestAPIServer(t)
+	defer server.Close()
+
+	var job Job
+	doAPIRequest(t, "POST", server.URL+apiPrefix+"/machines", CreateRequest{Name: hostTestName}, &job)
+
+	resp, err := http.Get(server.URL + apiPrefix + "/jobs/" + job.ID + "/log?follow=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	events := []progress.Event{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var e progress.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+
+	if len(events) == 0 || events[0].Type != progress.PhaseStarted || events[0].Phase != CreatePhaseInstance {
+		t.Fatalf("expected the log to start with the first phase of the creation; received %+v", events)
+	}
+
+	if job = waitForTestJob(t, server.URL, job); job.State != JobSucceeded {
+		t.Fatalf("expected the creation to succeed; it %s: %s", job.State, job.Error)
+	}
+}
+
+func TestAPIServerNotFound(t *testing.T) {
+	defer cleanup()
+
+	server := getTestAPIServer(t)
+	defer server.Close()
+
+	var apiErr APIError
+	if status := doAPIRequest(t, "POST", server.URL+apiPrefix+"/machines/missing/start", nil, &apiErr); status != http.StatusNotFound {
+		t.Fatalf("expected status 404; received %d", status)
+	}
+	if apiErr.Error == "" || apiErr.Code != APIErrorHostDoesNotExist {
+		t.Fatalf("expected an error about a missing machine; received %+v", apiErr)
+	}
+
+	apiErr = APIError{}
+	if status := doAPIRequest(t, "GET", server.URL+"/api/v0/machines", nil, &apiErr); status != http.StatusNotFound {
+		t.Fatalf("expected status 404 for an unknown API version; received %d", status)
+	}
+	if apiErr.Code != "" {
+		t.Fatalf("expected no code for an unknown endpoint; received %s", apiErr.Code)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	client, clientEnd := net.Pipe()
+	backend, backendEnd := net.Pipe()
+
+	// The first bytes of the client were buffered with its request.
+	go relay(clientEnd, io.MultiReader(strings.NewReader("SSH-2.0-"), clientEnd), backendEnd)
+
+	go client.Write([]byte("client"))
+	data := make([]byte, len("SSH-2.0-client"))
+	if _, err := io.ReadFull(backend, data); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "SSH-2.0-client" {
+		t.Fatalf("expected the backend to receive the buffered bytes first; received %q", data)
+	}
+
+	go backend.Write([]byte("server"))
+	data = make([]byte, len("server"))
+	if _, err := io.ReadFull(client, data); err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "server" {
+		t.Fatalf("expected the client to receive %q; received %q", "server", data)
+	}
+
+	backend.Close()
+	if _, err := client.Read(data); err == nil {
+		t.Fatal("expected the client connection to be closed with the backend")
+	}
+}
@@ -0,0 +1,316 @@
+package libmachine
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	bundleVersion      = 1
+	bundleManifestFile = "manifest.json"
+	bundleConfigFile   = "config.json"
+	bundleCAKeyFile    = "ca-key.pem"
+
+	// bundles only hold a few small files
+	maxBundleFileSize = 1 << 20
+)
+
+// BundleManifest describes the host in an export bundle.  The paths are
+// the ones the host had in the store it was exported from, and are
+// rewritten on import.
+type BundleManifest struct {
+	Version        int
+	Name           string
+	StorePath      string
+	CaCertPath     string
+	CaKeyPath      string
+	ClientCertPath string
+	ClientKeyPath  string
+	IncludesCA     bool
+}
+
+// ExportHost writes the host "name" to w as a gzipped tarball, which can be
+// imported into another store with ImportHost.  The bundle holds the config,
+// SSH key and certificates of the host, and the CA key if includeCA is set.
+// The credentials of the driver are not encrypted in the bundle.
+func ExportHost(store Store, name string, w io.Writer, includeCA bool) error {
+	host, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+	defer host.Close()
+
+	config, err := marshalHost(host, nil)
+	if err != nil {
+		return err
+	}
+
+	files, err := readHostFiles(host.StorePath)
+	if err != nil {
+		return err
+	}
+	files[bundleConfigFile] = config
+
+	if host.HostOptions == nil || host.HostOptions.AuthOptions == nil {
+		return fmt.Errorf("Machine %s has no auth options", name)
+	}
+
+	authOptions := host.HostOptions.AuthOptions
+
+	manifest := BundleManifest{
+		Version:        bundleVersion,
+		Name:           name,
+		StorePath:      host.StorePath,
+		CaCertPath:     authOptions.CaCertPath,
+		CaKeyPath:      authOptions.PrivateKeyPath,
+		ClientCertPath: authOptions.ClientCertPath,
+		ClientKeyPath:  authOptions.ClientKeyPath,
+		IncludesCA:     includeCA,
+	}
+
+	if includeCA {
+		caCert, err := ioutil.ReadFile(authOptions.CaCertPath)
+		if err != nil {
+			return fmt.Errorf("Error reading CA certificate: %s", err)
+		}
+		caKey, err := ioutil.ReadFile(authOptions.PrivateKeyPath)
+		if err != nil {
+			return fmt.Errorf("Error reading CA key: %s", err)
+		}
+		files["ca.pem"] = caCert
+		files[bundleCAKeyFile] = caKey
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	files[bundleManifestFile] = data
+
+	return writeBundle(w, files)
+}
+
+// ImportHost reads a bundle written by ExportHost from r and adds its host
+// to store as "name", or under its original name if name is empty.  The
+// paths in the config are rewritten to the machine directory in store.
+// Unless the bundle includes the CA, the certificates of the host are
+// expected to be regenerated with the CA of store.
+func ImportHost(store Store, r io.Reader, name string) (*Host, error) {
+	files, err := readBundle(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(files[bundleManifestFile], &manifest); err != nil {
+		return nil, fmt.Errorf("Error reading bundle manifest: %s", err)
+	}
+	if manifest.Version != bundleVersion {
+		return nil, fmt.Errorf("Unsupported bundle version %d", manifest.Version)
+	}
+
+	config, ok := files[bundleConfigFile]
+	if !ok {
+		return nil, fmt.Errorf("The bundle does not contain a machine config")
+	}
+
+	if name == "" {
+		name = manifest.Name
+	}
+	if !ValidateHostName(name) {
+		return nil, ErrInvalidHostname
+	}
+
+	exists, err := store.Exists(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("Machine %s already exists", name)
+	}
+
+	hostPath := filepath.Join(store.GetPath(), "machines", name)
+	if _, err := os.Stat(hostPath); err == nil {
+		return nil, fmt.Errorf("Machine directory %s already exists", hostPath)
+	}
+	if err := os.MkdirAll(hostPath, 0700); err != nil {
+		return nil, err
+	}
+
+	host, err := importHost(store, hostPath, name, manifest, config, files)
+	if err != nil {
+		os.RemoveAll(hostPath)
+		return nil, err
+	}
+
+	return host, nil
+}
+
+func importHost(store Store, hostPath, name string, manifest BundleManifest, config []byte, files map[string][]byte) (*Host, error) {
+	lock, err := LockHost(hostPath, "import")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	caCertPath, err := store.GetCACertificatePath()
+	if err != nil {
+		return nil, err
+	}
+	caKeyPath, err := store.GetPrivateKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	clientCertPath := filepath.Join(filepath.Dir(caCertPath), "cert.pem")
+	clientKeyPath := filepath.Join(filepath.Dir(caCertPath), "key.pem")
+
+	// With its CA, the host keeps working with the certificates it was
+	// exported with, which are kept in the machine directory.
+	if manifest.IncludesCA {
+		caCertPath = filepath.Join(hostPath, "ca.pem")
+		caKeyPath = filepath.Join(hostPath, bundleCAKeyFile)
+		clientCertPath = filepath.Join(hostPath, "cert.pem")
+		clientKeyPath = filepath.Join(hostPath, "key.pem")
+	}
+
+	// The paths are replaced in the whole config so that the paths the
+	// driver keeps are rewritten as well.  The machine directory goes
+	// first, as the other paths may be inside of it.
+	replacements := [][2]string{
+		{manifest.StorePath, hostPath},
+		{manifest.CaCertPath, caCertPath},
+		{manifest.CaKeyPath, caKeyPath},
+		{manifest.ClientCertPath, clientCertPath},
+		{manifest.ClientKeyPath, clientKeyPath},
+	}
+	for _, r := range replacements {
+		if r[0] != "" {
+			config = replaceStorePath(config, r[0], r[1])
+		}
+	}
+
+	if err := writeHostFiles(hostPath, config, files); err != nil {
+		return nil, err
+	}
+	if manifest.IncludesCA {
+		if err := ioutil.WriteFile(filepath.Join(hostPath, bundleCAKeyFile), files[bundleCAKeyFile], 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	host, err := LoadHost(name, hostPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if host.HostOptions == nil || host.HostOptions.AuthOptions == nil {
+		return nil, fmt.Errorf("The machine config in the bundle has no auth options")
+	}
+
+	authOptions := host.HostOptions.AuthOptions
+	authOptions.StorePath = hostPath
+	authOptions.CaCertPath = caCertPath
+	authOptions.PrivateKeyPath = caKeyPath
+	authOptions.ClientCertPath = clientCertPath
+	authOptions.ClientKeyPath = clientKeyPath
+	authOptions.ServerCertPath = filepath.Join(hostPath, "server.pem")
+	authOptions.ServerKeyPath = filepath.Join(hostPath, "server-key.pem")
+
+	if ks, ok := store.(secretKeyStore); ok {
+		host.secretKey = ks.getSecretKey()
+	}
+	host.journal = newStoreEventJournal(store)
+	registerSecrets(host.Driver)
+
+	if err := store.Save(host); err != nil {
+		return nil, err
+	}
+
+	return host, nil
+}
+
+func writeBundle(w io.Writer, files map[string][]byte) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(files[name])),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// readBundle returns the files in a bundle.  Only the files a bundle is
+// made of are accepted, so a bundle can not write anywhere else.
+func readBundle(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading bundle: %s", err)
+	}
+	defer gz.Close()
+
+	allowed := map[string]bool{
+		bundleManifestFile: true,
+		bundleConfigFile:   true,
+		bundleCAKeyFile:    true,
+	}
+	for _, name := range hostFiles {
+		allowed[name] = true
+	}
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error reading bundle: %s", err)
+		}
+
+		if !allowed[hdr.Name] || hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("Unexpected file in bundle: %s", hdr.Name)
+		}
+		if hdr.Size > maxBundleFileSize {
+			return nil, fmt.Errorf("File %s in bundle is too large", hdr.Name)
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading bundle: %s", err)
+		}
+		files[hdr.Name] = data
+	}
+
+	return files, nil
+}
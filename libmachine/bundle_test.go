@@ -0,0 +1,184 @@
+package libmachine
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func getTestBundleHost(t *testing.T, store Store) *Host {
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authOptions := host.HostOptions.AuthOptions
+	authOptions.CaCertPath = filepath.Join(store.GetPath(), "certs", "ca.pem")
+	authOptions.PrivateKeyPath = filepath.Join(store.GetPath(), "certs", "ca-key.pem")
+	authOptions.ClientCertPath = filepath.Join(store.GetPath(), "certs", "cert.pem")
+	authOptions.ClientKeyPath = filepath.Join(store.GetPath(), "certs", "key.pem")
+	authOptions.ServerCertPath = filepath.Join(host.StorePath, "server.pem")
+	authOptions.ServerKeyPath = filepath.Join(host.StorePath, "server-key.pem")
+
+	if err := os.MkdirAll(filepath.Dir(authOptions.CaCertPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{authOptions.CaCertPath, authOptions.PrivateKeyPath} {
+		if err := ioutil.WriteFile(path, []byte(filepath.Base(path)), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Save(host); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(host.StorePath, "id_rsa"), []byte("ssh-key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return host
+}
+
+func getTestBundleStore(t *testing.T) Store {
+	path, err := ioutil.TempDir("", "machine-test-import-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFilestore(path, filepath.Join(path, "certs", "ca.pem"), filepath.Join(path, "certs", "ca-key.pem"))
+}
+
+func TestExportImportHost(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := getTestBundleHost(t, store)
+
+	var bundle bytes.Buffer
+	if err := ExportHost(store, host.Name, &bundle, false); err != nil {
+		t.Fatal(err)
+	}
+
+	otherStore := getTestBundleStore(t)
+	defer os.RemoveAll(otherStore.GetPath())
+
+	imported, err := ImportHost(otherStore, &bundle, "imported")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostPath := filepath.Join(otherStore.GetPath(), "machines", "imported")
+	if imported.Name != "imported" || imported.StorePath != hostPath {
+		t.Fatalf("expected host imported in %s; received %s in %s", hostPath, imported.Name, imported.StorePath)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(hostPath, "id_rsa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ssh-key" {
+		t.Fatalf("expected the SSH key to be imported; received %q", data)
+	}
+
+	config, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(config, []byte(store.GetPath())) {
+		t.Fatalf("expected every path of the old store to be rewritten; received %s", config)
+	}
+
+	loaded, err := otherStore.Get("imported")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authOptions := loaded.HostOptions.AuthOptions
+	expected := map[string]string{
+		authOptions.StorePath:      hostPath,
+		authOptions.CaCertPath:     filepath.Join(otherStore.GetPath(), "certs", "ca.pem"),
+		authOptions.PrivateKeyPath: filepath.Join(otherStore.GetPath(), "certs", "ca-key.pem"),
+		authOptions.ClientCertPath: filepath.Join(otherStore.GetPath(), "certs", "cert.pem"),
+		authOptions.ServerCertPath: filepath.Join(hostPath, "server.pem"),
+	}
+	for actual, path := range expected {
+		if actual != path {
+			t.Fatalf("expected path %s; received %s", path, actual)
+		}
+	}
+
+	if _, err := ImportHost(otherStore, bytes.NewReader(nil), "imported"); err == nil {
+		t.Fatal("expected an error importing an invalid bundle")
+	}
+}
+
+func TestExportImportHostWithCA(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := getTestBundleHost(t, store)
+
+	var bundle bytes.Buffer
+	if err := ExportHost(store, host.Name, &bundle, true); err != nil {
+		t.Fatal(err)
+	}
+
+	otherStore := getTestBundleStore(t)
+	defer os.RemoveAll(otherStore.GetPath())
+
+	imported, err := ImportHost(otherStore, &bundle, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if imported.Name != host.Name {
+		t.Fatalf("expected the original name %s; received %s", host.Name, imported.Name)
+	}
+
+	authOptions := imported.HostOptions.AuthOptions
+	if !strings.HasPrefix(authOptions.PrivateKeyPath, imported.StorePath) {
+		t.Fatalf("expected the CA key in the machine directory; received %s", authOptions.PrivateKeyPath)
+	}
+
+	data, err := ioutil.ReadFile(authOptions.PrivateKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ca-key.pem" {
+		t.Fatalf("expected the CA key to be imported; received %q", data)
+	}
+}
+
+func TestImportHostExists(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := getTestBundleHost(t, store)
+
+	var bundle bytes.Buffer
+	if err := ExportHost(store, host.Name, &bundle, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ImportHost(store, &bundle, ""); err == nil {
+		t.Fatal("expected an error importing over an existing host")
+	}
+
+	if _, err := os.Stat(host.StorePath); err != nil {
+		t.Fatalf("expected the existing host to be left alone: %s", err)
+	}
+}
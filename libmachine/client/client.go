@@ -0,0 +1,510 @@
+// Package client is a Go client of the machine API served by
+// libmachine.APIServer, e.g. with `docker-machine serve`.  It implements
+// libmachine.MachineAPI, like a libmachine.Provider, on the machines of the
+// server.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/progress"
+	"golang.org/x/net/context"
+)
+
+const apiPrefix = "/api/" + libmachine.APIVersion
+
+// Client talks to a machine API server.  The operations of
+// libmachine.MachineAPI wait for the job running them on the server, and
+// publish its progress to the progress sink of the client.  The other
+// operations which change machines return the job; Wait waits for it.
+type Client struct {
+	url          string
+	host         string
+	tlsConfig    *tls.Config
+	client       *http.Client
+	progressSink progress.Sink
+}
+
+// ErrJobFailed is returned by Wait when the operation of a job failed.
+type ErrJobFailed struct {
+	Job libmachine.Job
+}
+
+func (e ErrJobFailed) Error() string {
+	return fmt.Sprintf("Error running %s on %s: %s", e.Job.Action, e.Job.Machine, e.Job.Error)
+}
+
+// New returns a client of the server at serverURL, e.g.
+// https://machines.example.com:8444.  A URL without a scheme is taken to be
+// https.  tlsConfig holds the client certificate signed by the machine CA of
+// the server.
+func New(serverURL string, tlsConfig *tls.Config) (*Client, error) {
+	if !strings.Contains(serverURL, "://") {
+		serverURL = "https://" + serverURL
+	}
+
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("Invalid server URL: %s", serverURL)
+	}
+
+	host := u.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		if u.Scheme == "https" {
+			host = net.JoinHostPort(host, "443")
+		} else {
+			host = net.JoinHostPort(host, "80")
+		}
+	}
+
+	return &Client{
+		url:       strings.TrimRight(u.String(), "/"),
+		host:      host,
+		tlsConfig: tlsConfig,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}, nil
+}
+
+// URL returns the URL of the server.
+func (c *Client) URL() string {
+	return c.url
+}
+
+// SetProgressSink sets the sink which receives the progress of the
+// operations of libmachine.MachineAPI.  Without a sink, the progress is
+// discarded.
+func (c *Client) SetProgressSink(sink progress.Sink) {
+	c.progressSink = sink
+}
+
+// wait waits for a job started by an operation of libmachine.MachineAPI.
+func (c *Client) wait(ctx context.Context, job libmachine.Job, err error) error {
+	if err != nil {
+		return err
+	}
+
+	sink := c.progressSink
+	if sink == nil {
+		sink = progress.Discard
+	}
+	_, err = c.Wait(ctx, job, sink)
+	return err
+}
+
+func (c *Client) do(method, path string, body interface{}) (*http.Response, error) {
+	var data []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	req, err := http.NewRequest(method, c.url+apiPrefix+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error contacting machine server at %s: %s", c.url, err)
+	}
+	return resp, nil
+}
+
+// call does a request and decodes the response into v, if it is not nil.
+// The machine the request is about, if any, is named in the
+// libmachine.ErrHostDoesNotExist returned for it.
+func (c *Client) call(method, path, machine string, body, v interface{}) error {
+	resp, err := c.do(method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, machine); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func checkResponse(resp *http.Response, machine string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var apiErr libmachine.APIError
+	data, _ := ioutil.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &apiErr); err != nil || apiErr.Error == "" {
+		apiErr.Error = strings.TrimSpace(string(data))
+	}
+
+	if apiErr.Code == libmachine.APIErrorHostDoesNotExist && machine != "" {
+		return libmachine.ErrHostDoesNotExist{
+			Name: machine,
+		}
+	}
+
+	return fmt.Errorf("Machine server returned %s: %s", resp.Status, apiErr.Error)
+}
+
+func machinePath(name string, endpoint ...string) string {
+	return "/machines/" + strings.Join(append([]string{url.QueryEscape(name)}, endpoint...), "/")
+}
+
+// Version returns the version of the server.  It fails if the server does
+// not serve the version of the API of the client.
+func (c *Client) Version() (libmachine.VersionInfo, error) {
+	var info libmachine.VersionInfo
+	if err := c.call("GET", "/version", "", nil, &info); err != nil {
+		return info, err
+	}
+	if info.APIVersion != libmachine.APIVersion {
+		return info, fmt.Errorf("The machine server serves API %s; expected %s", info.APIVersion, libmachine.APIVersion)
+	}
+	return info, nil
+}
+
+func (c *Client) ListMachines() ([]libmachine.MachineInfo, error) {
+	machines := []libmachine.MachineInfo{}
+	if err := c.call("GET", "/machines", "", nil, &machines); err != nil {
+		return nil, err
+	}
+	return machines, nil
+}
+
+func (c *Client) GetMachine(name string) (libmachine.MachineInfo, error) {
+	var machine libmachine.MachineInfo
+	err := c.call("GET", machinePath(name), name, nil, &machine)
+	return machine, err
+}
+
+func (c *Client) Exists(name string) (bool, error) {
+	_, err := c.GetMachine(name)
+	if _, ok := err.(libmachine.ErrHostDoesNotExist); ok {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Create starts creating a machine.
+func (c *Client) Create(req libmachine.CreateRequest) (libmachine.Job, error) {
+	var job libmachine.Job
+	err := c.call("POST", "/machines", "", req, &job)
+	return job, err
+}
+
+// Remove starts removing a machine.  With force set, the machine is
+// removed from the store even if it can not be removed from its provider.
+func (c *Client) Remove(name string, force bool) (libmachine.Job, error) {
+	var job libmachine.Job
+	err := c.call("DELETE", machinePath(name)+"?force="+strconv.FormatBool(force), name, nil, &job)
+	return job, err
+}
+
+// RemoveContext removes a machine, see Remove.  The removal is cancelled
+// if the context is done first.
+func (c *Client) RemoveContext(ctx context.Context, name string, force bool) error {
+	job, err := c.Remove(name, force)
+	return c.wait(ctx, job, err)
+}
+
+// Run starts an action on a machine: start, stop, restart, kill, pause,
+// suspend, resume, upgrade or regenerate-certs.
+func (c *Client) Run(name, action string) (libmachine.Job, error) {
+	var job libmachine.Job
+	err := c.call("POST", machinePath(name, action), name, nil, &job)
+	return job, err
+}
+
+// RunAction runs an action on a machine, see Run.  The action is cancelled
+// if the context is done first.
+func (c *Client) RunAction(ctx context.Context, name, action string) error {
+	job, err := c.Run(name, action)
+	return c.wait(ctx, job, err)
+}
+
+func (c *Client) Start(name string) (libmachine.Job, error) {
+	return c.Run(name, "start")
+}
+
+func (c *Client) Stop(name string) (libmachine.Job, error) {
+	return c.Run(name, "stop")
+}
+
+func (c *Client) Restart(name string) (libmachine.Job, error) {
+	return c.Run(name, "restart")
+}
+
+func (c *Client) Kill(name string) (libmachine.Job, error) {
+	return c.Run(name, "kill")
+}
+
+func (c *Client) Pause(name string) (libmachine.Job, error) {
+	return c.Run(name, "pause")
+}
+
+func (c *Client) Suspend(name string) (libmachine.Job, error) {
+	return c.Run(name, "suspend")
+}
+
+func (c *Client) Resume(name string) (libmachine.Job, error) {
+	return c.Run(name, "resume")
+}
+
+func (c *Client) Upgrade(name string) (libmachine.Job, error) {
+	return c.Run(name, "upgrade")
+}
+
+func (c *Client) RegenerateCerts(name string) (libmachine.Job, error) {
+	return c.Run(name, "regenerate-certs")
+}
+
+// UpdateLabels sets the labels in "set" on a machine, and removes the
+// labels with the keys in "remove".
+func (c *Client) UpdateLabels(name string, set map[string]string, remove []string) error {
+	req := libmachine.LabelsRequest{
+		Set:    set,
+		Remove: remove,
+	}
+	return c.call("POST", machinePath(name, "labels"), name, req, nil)
+}
+
+func (c *Client) Rename(oldName, newName string) error {
+	var job libmachine.Job
+	err := c.call("POST", machinePath(oldName, "rename"), oldName, libmachine.RenameRequest{Name: newName}, &job)
+	return c.wait(context.Background(), job, err)
+}
+
+func (c *Client) Resize(name string, opts drivers.ResizeOptions) error {
+	var job libmachine.Job
+	err := c.call("POST", machinePath(name, "resize"), name, opts, &job)
+	return c.wait(context.Background(), job, err)
+}
+
+func (c *Client) ListSnapshots(name string) ([]drivers.Snapshot, error) {
+	snapshots := []drivers.Snapshot{}
+	if err := c.call("GET", machinePath(name, "snapshots"), name, nil, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (c *Client) TakeSnapshot(name, snapshot string) error {
+	var job libmachine.Job
+	err := c.call("POST", machinePath(name, "snapshots"), name, libmachine.SnapshotRequest{Name: snapshot}, &job)
+	return c.wait(context.Background(), job, err)
+}
+
+func (c *Client) RestoreSnapshot(name, snapshot string) error {
+	var job libmachine.Job
+	err := c.call("POST", machinePath(name, "snapshots", url.QueryEscape(snapshot), "restore"), name, nil, &job)
+	return c.wait(context.Background(), job, err)
+}
+
+func (c *Client) RemoveSnapshot(name, snapshot string) error {
+	var job libmachine.Job
+	err := c.call("DELETE", machinePath(name, "snapshots", url.QueryEscape(snapshot)), name, nil, &job)
+	return c.wait(context.Background(), job, err)
+}
+
+func (c *Client) GetIP(name string) (string, error) {
+	var v map[string]string
+	err := c.call("GET", machinePath(name, "ip"), name, nil, &v)
+	return v["IP"], err
+}
+
+func (c *Client) GetURL(name string) (string, error) {
+	var v map[string]string
+	err := c.call("GET", machinePath(name, "url"), name, nil, &v)
+	return v["URL"], err
+}
+
+// GetEnv returns the Docker environment of a machine, or of the swarm it is
+// the master of, without DOCKER_CERT_PATH: the certificates returned by
+// GetCertBundle need to be saved locally first.
+func (c *Client) GetEnv(name string, swarmMaster bool) (map[string]string, error) {
+	var env map[string]string
+	err := c.call("GET", machinePath(name, "env")+"?swarm="+strconv.FormatBool(swarmMaster), name, nil, &env)
+	return env, err
+}
+
+func (c *Client) GetCertBundle(name string) (libmachine.CertBundle, error) {
+	var bundle libmachine.CertBundle
+	err := c.call("GET", machinePath(name, "certs"), name, nil, &bundle)
+	return bundle, err
+}
+
+func (c *Client) GetSSHKey(name string) (libmachine.SSHKey, error) {
+	var key libmachine.SSHKey
+	err := c.call("GET", machinePath(name, "ssh-key"), name, nil, &key)
+	return key, err
+}
+
+// DialSSH returns a connection to the SSH port of a machine, tunnelled
+// through the server.
+func (c *Client) DialSSH(name string) (net.Conn, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+	if strings.HasPrefix(c.url, "https://") {
+		conn, err = tls.Dial("tcp", c.host, c.tlsConfig)
+	} else {
+		conn, err = net.Dial("tcp", c.host)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error contacting machine server at %s: %s", c.url, err)
+	}
+
+	req, err := http.NewRequest("POST", c.url+apiPrefix+machinePath(name, "ssh"), nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "ssh")
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer conn.Close()
+		defer resp.Body.Close()
+		if err := checkResponse(resp, name); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Machine server returned %s for the SSH tunnel", resp.Status)
+	}
+
+	return &bufferedConn{Conn: conn, r: r}, nil
+}
+
+// bufferedConn is a connection whose first bytes were read into r along
+// with the response of the server.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+func (c *Client) ListJobs() ([]libmachine.Job, error) {
+	jobs := []libmachine.Job{}
+	if err := c.call("GET", "/jobs", "", nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (c *Client) GetJob(id string) (libmachine.Job, error) {
+	var job libmachine.Job
+	err := c.call("GET", "/jobs/"+url.QueryEscape(id), "", nil, &job)
+	return job, err
+}
+
+// CancelJob cancels a running job.  The job fails once its operation
+// returns.
+func (c *Client) CancelJob(id string) (libmachine.Job, error) {
+	var job libmachine.Job
+	err := c.call("DELETE", "/jobs/"+url.QueryEscape(id), "", nil, &job)
+	return job, err
+}
+
+// JobLog publishes the events of the log of a job to the sink.  With follow
+// set, it returns once the job is done.
+func (c *Client) JobLog(id string, follow bool, sink progress.Sink) error {
+	resp, err := c.do("GET", "/jobs/"+url.QueryEscape(id)+"/log?follow="+strconv.FormatBool(follow), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, ""); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var e progress.Event
+		if err := decoder.Decode(&e); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("Error reading the log of job %s: %s", id, err)
+		}
+		sink.Publish(e)
+	}
+}
+
+// Wait follows the log of a job, publishing it to the sink, until the job
+// is done.  The job is cancelled if the context is done first.  It returns
+// ErrJobFailed if the operation of the job failed.
+func (c *Client) Wait(ctx context.Context, job libmachine.Job, sink progress.Sink) (libmachine.Job, error) {
+	followed := make(chan error, 1)
+	go func() {
+		followed <- c.JobLog(job.ID, true, sink)
+	}()
+
+	select {
+	case err := <-followed:
+		if err != nil {
+			return job, err
+		}
+	case <-ctx.Done():
+		if _, err := c.CancelJob(job.ID); err != nil {
+			return job, err
+		}
+		// The log ends once the operation has stopped.
+		if err := <-followed; err != nil {
+			return job, err
+		}
+	}
+
+	job, err := c.GetJob(job.ID)
+	if err != nil {
+		return job, err
+	}
+	if job.State == libmachine.JobFailed {
+		return job, ErrJobFailed{Job: job}
+	}
+	return job, nil
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/docker/machine/drivers"
+	_ "github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/progress"
+	"golang.org/x/net/context"
+)
+
+type DriverOptionsMock struct {
+	Data map[string]interface{}
+}
+
+func (d DriverOptionsMock) String(key string) string {
+	return d.Data[key].(string)
+}
+
+func (d DriverOptionsMock) StringSlice(key string) []string {
+	return d.Data[key].([]string)
+}
+
+func (d DriverOptionsMock) Int(key string) int {
+	return d.Data[key].(int)
+}
+
+func (d DriverOptionsMock) Bool(key string) bool {
+	return d.Data[key].(bool)
+}
+
+func getTestServer(t *testing.T, storePath string) *httptest.Server {
+	provider, err := libmachine.New(libmachine.NewFilestore(storePath, "test-cert", "test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createOptions := func(req libmachine.CreateRequest) (string, *libmachine.HostOptions, drivers.DriverOptions, error) {
+		hostOptions := &libmachine.HostOptions{
+			EngineOptions: &engine.EngineOptions{},
+			SwarmOptions:  &swarm.SwarmOptions{},
+			AuthOptions:   &auth.AuthOptions{},
+			Labels:        req.Labels,
+		}
+		driverOptions := DriverOptionsMock{
+			Data: map[string]interface{}{
+				"url":             "tcp://10.0.0.1:2376",
+				"swarm":           false,
+				"swarm-host":      "",
+				"swarm-master":    false,
+				"swarm-discovery": "",
+			},
+		}
+		return "none", hostOptions, driverOptions, nil
+	}
+
+	return httptest.NewServer(libmachine.NewAPIServer(provider, createOptions))
+}
+
+func TestClient(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "machine-client-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storePath)
+
+	server := getTestServer(t, storePath)
+	defer server.Close()
+
+	client, err := New(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Version(); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := client.Create(libmachine.CreateRequest{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := 0
+	sink := progress.SinkFunc(func(e progress.Event) {
+		if e.Machine != "dev" {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+		events++
+	})
+	if job, err = client.Wait(context.Background(), job, sink); err != nil {
+		t.Fatal(err)
+	}
+	if job.State != libmachine.JobSucceeded || events == 0 {
+		t.Fatalf("expected the creation to succeed with progress; it %s with %d events", job.State, events)
+	}
+
+	machines, err := client.ListMachines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(machines) != 1 || machines[0].Name != "dev" {
+		t.Fatalf("unexpected machines: %+v", machines)
+	}
+
+	env, err := client.GetEnv("dev", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env["DOCKER_HOST"] != "tcp://10.0.0.1:2376" || env["DOCKER_MACHINE_NAME"] != "dev" {
+		t.Fatalf("unexpected environment: %v", env)
+	}
+
+	if _, err := client.DialSSH("dev"); err == nil {
+		t.Fatal("expected an error tunnelling SSH to a machine without SSH")
+	}
+
+	if err := client.UpdateLabels("dev", map[string]string{"env": "dev"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Rename("dev", "prod"); err != nil {
+		t.Fatal(err)
+	}
+	machine, err := client.GetMachine("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if machine.Labels["env"] != "dev" {
+		t.Fatalf("expected the renamed machine to keep its labels; received %v", machine.Labels)
+	}
+
+	if _, err := client.ListSnapshots("prod"); err == nil {
+		t.Fatal("expected an error listing the snapshots of a machine whose driver does not take them")
+	}
+
+	if err := client.RemoveContext(context.Background(), "prod", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetMachine("prod"); err == nil {
+		t.Fatal("expected an error getting a removed machine")
+	} else if _, ok := err.(libmachine.ErrHostDoesNotExist); !ok {
+		t.Fatalf("expected ErrHostDoesNotExist; received %v", err)
+	}
+
+	if exists, err := client.Exists("prod"); err != nil || exists {
+		t.Fatalf("expected the machine not to exist; received %v, %v", exists, err)
+	}
+
+	if err := client.RunAction(context.Background(), "prod", "start"); err == nil {
+		t.Fatal("expected an error starting a removed machine")
+	} else if _, ok := err.(libmachine.ErrHostDoesNotExist); !ok {
+		t.Fatalf("expected ErrHostDoesNotExist; received %v", err)
+	}
+}
+
+// Client and libmachine.Provider are interchangeable.
+var _ = []libmachine.MachineAPI{&Client{}, &libmachine.Provider{}}
+
+func TestClientDialSSH(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != apiPrefix+"/machines/dev/ssh" {
+			http.NotFound(w, r)
+			return
+		}
+
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		// The greeting of the SSH server may arrive with the response.
+		fmt.Fprint(conn, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: ssh\r\n\r\nSSH-2.0-test\r\n")
+		io.Copy(conn, buf)
+	}))
+	defer server.Close()
+
+	client, err := New(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := client.DialSSH("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	greeting, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if greeting != "SSH-2.0-test\r\n" {
+		t.Fatalf("unexpected greeting: %q", greeting)
+	}
+
+	fmt.Fprint(conn, "ping\n")
+	echo, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if echo != "ping\n" {
+		t.Fatalf("expected the tunnel to carry data both ways; received %q", echo)
+	}
+
+	if _, err := client.DialSSH("missing"); err == nil {
+		t.Fatal("expected an error tunnelling SSH to a missing machine")
+	}
+}
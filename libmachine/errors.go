@@ -3,6 +3,8 @@ package libmachine
 import (
 	"errors"
 	"fmt"
+
+	"github.com/docker/machine/version"
 )
 
 var (
@@ -17,3 +19,63 @@ type ErrHostDoesNotExist struct {
 func (e ErrHostDoesNotExist) Error() string {
 	return fmt.Sprintf("Error: Host does not exist: %s", e.Name)
 }
+
+type ErrHostLocked struct {
+	Name    string
+	Pid     int
+	Command string
+}
+
+func (e ErrHostLocked) Error() string {
+	if e.Pid == 0 {
+		return fmt.Sprintf("Error: machine %s is locked by another process", e.Name)
+	}
+	return fmt.Sprintf("Error: machine %s is locked by pid %d running `%s`", e.Name, e.Pid, e.Command)
+}
+
+// ErrCreateFailed is returned when the creation of a machine failed.  The
+// machine is either kept, or was rolled back, in which case Cleaned lists
+// what was removed and RollbackErr is set if it could not be removed
+// entirely.
+type ErrCreateFailed struct {
+	Name        string
+	Err         error
+	Kept        bool
+	Cleaned     []string
+	RollbackErr error
+}
+
+func (e ErrCreateFailed) Error() string {
+	return e.Err.Error()
+}
+
+// ErrConfigVersionTooNew is returned for the config of a machine which was
+// written by a newer version of machine.
+type ErrConfigVersionTooNew struct {
+	Name    string
+	Version int
+}
+
+func (e ErrConfigVersionTooNew) Error() string {
+	return fmt.Sprintf("Machine %s has config version %d, which is newer than the version %d this docker-machine supports. Please upgrade docker-machine.", e.Name, e.Version, version.ConfigVersion)
+}
+
+type ErrTemplateDoesNotExist struct {
+	Name string
+}
+
+func (e ErrTemplateDoesNotExist) Error() string {
+	return fmt.Sprintf("Template %s does not exist", e.Name)
+}
+
+// ErrNotSupported is returned for an operation the driver of a machine does
+// not support.
+type ErrNotSupported struct {
+	Name       string
+	DriverName string
+	Operation  string
+}
+
+func (e ErrNotSupported) Error() string {
+	return fmt.Sprintf("Machine %s uses driver %s, which does not support %s", e.Name, e.DriverName, e.Operation)
+}
@@ -0,0 +1,246 @@
+package libmachine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+const (
+	// EventJournalFile lives in the root of a store.  It holds one JSON
+	// encoded Event per line.
+	EventJournalFile = "events.log"
+
+	EventSuccess = "success"
+	EventFailure = "failure"
+
+	eventFollowInterval = 500 * time.Millisecond
+	eventWebhookTimeout = 10 * time.Second
+)
+
+// Event records an operation on a machine.
+type Event struct {
+	Time    time.Time
+	User    string
+	Machine string
+	Action  string
+	Result  string
+	Error   string `json:",omitempty"`
+}
+
+// EventFilter selects events.  Empty fields match every event.
+type EventFilter struct {
+	Since   time.Time
+	Machine string
+	Action  string
+}
+
+func (f EventFilter) Match(e Event) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Machine != "" && e.Machine != f.Machine {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	return true
+}
+
+// EventJournal is the journal of the operations on the machines of a store.
+type EventJournal struct {
+	path string
+}
+
+func NewEventJournal(storePath string) *EventJournal {
+	return &EventJournal{
+		path: filepath.Join(storePath, EventJournalFile),
+	}
+}
+
+// journalStore is implemented by the stores which do not record the
+// events of their machines in their own path.
+type journalStore interface {
+	getJournalPath() string
+}
+
+// newStoreEventJournal returns the journal the events of the machines of
+// store are recorded in.
+func newStoreEventJournal(store Store) *EventJournal {
+	if js, ok := store.(journalStore); ok {
+		return NewEventJournal(js.getJournalPath())
+	}
+	return NewEventJournal(store.GetPath())
+}
+
+// Append adds an event to the journal.  Each event is written with a
+// single append, so that processes can share the journal.
+func (j *EventJournal) Append(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read returns the events in the journal which match filter.
+func (j *EventJournal) Read(filter EventFilter) ([]Event, error) {
+	events := []Event{}
+	_, err := j.readFrom(0, -1, filter, func(e Event) error {
+		events = append(events, e)
+		return nil
+	})
+	return events, err
+}
+
+// Follow calls history with the events in the journal which match filter
+// and were recorded before it was called, then calls fn with the new ones
+// as they are recorded, until stop is closed or either returns an error.
+func (j *EventJournal) Follow(filter EventFilter, stop <-chan struct{}, history, fn func(Event) error) error {
+	var end int64
+	if fi, err := os.Stat(j.path); err == nil {
+		end = fi.Size()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	offset, err := j.readFrom(0, end, filter, history)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if offset, err = j.readFrom(offset, -1, filter, fn); err != nil {
+			return err
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(eventFollowInterval):
+		}
+	}
+}
+
+// readFrom calls fn with the events matching filter which start at offset
+// and end before end, or anywhere if end is negative, and returns the
+// offset after the last complete line.
+func (j *EventJournal) readFrom(offset, end int64, filter EventFilter, fn func(Event) error) (int64, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return offset, nil
+		}
+		return offset, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, 0); err != nil {
+		return offset, err
+	}
+
+	var lines io.Reader = f
+	if end >= 0 {
+		lines = io.LimitReader(f, end-offset)
+	}
+
+	r := bufio.NewReader(lines)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// a partial line is being written; read it again next time
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+		offset += int64(len(line))
+
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			log.Debugf("Skipping invalid event in %s: %s", j.path, err)
+			continue
+		}
+
+		if filter.Match(e) {
+			if err := fn(e); err != nil {
+				return offset, err
+			}
+		}
+	}
+}
+
+// PostEvent sends the event as JSON to a webhook.
+func PostEvent(url string, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{
+		Timeout: eventWebhookTimeout,
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+// recordEvent appends the outcome of action to the journal of the store
+// the host is kept in, and returns err.  A host which was not loaded from a
+// store has no journal.  A journal which can not be written does not fail
+// the action.
+func (h *Host) recordEvent(action string, err error) error {
+	if h.journal == nil {
+		return err
+	}
+
+	e := Event{
+		Time:    time.Now().UTC(),
+		User:    utils.GetUsername(),
+		Machine: h.Name,
+		Action:  action,
+		Result:  EventSuccess,
+	}
+	if err != nil {
+		e.Result = EventFailure
+		e.Error = log.Redact(err.Error())
+	}
+
+	if journalErr := h.journal.Append(e); journalErr != nil {
+		log.Debugf("Error recording event %s of machine %s: %s", action, h.Name, journalErr)
+	}
+
+	return err
+}
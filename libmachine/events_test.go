@@ -0,0 +1,154 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHostRecordsEvents(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(host); err != nil {
+		t.Fatal(err)
+	}
+
+	// a host which was not loaded from a store has no journal
+	host.recordEvent("create", nil)
+
+	host, err = store.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host.recordEvent("start", nil)
+	host.recordEvent("stop", errors.New("stop failed"))
+
+	journal := NewEventJournal(store.GetPath())
+
+	events, err := journal.Read(EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events; received %d", len(events))
+	}
+
+	e := events[1]
+	if e.Machine != hostTestName || e.Action != "stop" || e.Result != EventFailure || e.Error != "stop failed" {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	events, err = journal.Read(EventFilter{Action: "start"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Result != EventSuccess {
+		t.Fatalf("expected the start event; received %+v", events)
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	now := time.Now()
+	e := Event{Time: now, Machine: "dev", Action: "start"}
+
+	filters := map[EventFilter]bool{
+		EventFilter{}:                           true,
+		EventFilter{Machine: "dev"}:             true,
+		EventFilter{Machine: "prod"}:            false,
+		EventFilter{Action: "stop"}:             false,
+		EventFilter{Since: now.Add(-time.Hour)}: true,
+		EventFilter{Since: now.Add(time.Hour)}:  false,
+	}
+
+	for filter, expected := range filters {
+		if filter.Match(e) != expected {
+			t.Fatalf("expected %+v to match: %t", filter, expected)
+		}
+	}
+}
+
+func TestEventJournalFollow(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(store.GetPath(), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	journal := NewEventJournal(store.GetPath())
+	if err := journal.Append(Event{Machine: "dev", Action: "create"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a line which is still being written is not returned
+	f, err := os.OpenFile(filepath.Join(store.GetPath(), EventJournalFile), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(`{"Machine":"dev",`))
+	f.Close()
+
+	stop := make(chan struct{})
+	history := []Event{}
+	received := []Event{}
+	if err := journal.Follow(EventFilter{}, stop, func(e Event) error {
+		history = append(history, e)
+
+		// the line is complete once Follow has started, so it is new
+		f, err := os.OpenFile(filepath.Join(store.GetPath(), EventJournalFile), os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write([]byte(`"Action":"start"}` + "\n"))
+		return err
+	}, func(e Event) error {
+		received = append(received, e)
+		close(stop)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history) != 1 || history[0].Action != "create" {
+		t.Fatalf("expected the create event in the history; received %+v", history)
+	}
+	if len(received) != 1 || received[0].Action != "start" {
+		t.Fatalf("expected the new start event; received %+v", received)
+	}
+}
+
+func TestPostEvent(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	if err := PostEvent(server.URL, Event{Machine: "dev", Action: "start"}); err != nil {
+		t.Fatal(err)
+	}
+	if received.Machine != "dev" || received.Action != "start" {
+		t.Fatalf("unexpected event %+v", received)
+	}
+}
@@ -1,7 +1,6 @@
 package libmachine
 
 import (
-	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"os"
@@ -16,6 +15,7 @@ type Filestore struct {
 	path           string
 	caCertPath     string
 	privateKeyPath string
+	secretKey      *SecretKey
 }
 
 func NewFilestore(rootPath string, caCert string, privateKey string) *Filestore {
@@ -26,8 +26,22 @@ func NewFilestore(rootPath string, caCert string, privateKey string) *Filestore
 	}
 }
 
+// SetSecretKey sets the key the credentials of the drivers are encrypted
+// with.  Without a key they are stored in plaintext.
+func (s *Filestore) SetSecretKey(key *SecretKey) {
+	s.secretKey = key
+}
+
+func (s Filestore) getSecretKey() *SecretKey {
+	return s.secretKey
+}
+
+func (s Filestore) getMachinesDir() string {
+	return filepath.Join(s.path, "machines")
+}
+
 func (s Filestore) loadHost(name string) (*Host, error) {
-	hostPath := filepath.Join(utils.GetMachineDir(), name)
+	hostPath := filepath.Join(s.getMachinesDir(), name)
 	if _, err := os.Stat(hostPath); os.IsNotExist(err) {
 		return nil, ErrHostDoesNotExist{
 			Name: name,
@@ -37,6 +51,8 @@ func (s Filestore) loadHost(name string) (*Host, error) {
 	host := &Host{
 		Name:      name,
 		StorePath: hostPath,
+		secretKey: s.secretKey,
+		journal:   NewEventJournal(s.path),
 	}
 	if err := host.LoadConfig(); err != nil {
 		return nil, err
@@ -58,18 +74,18 @@ func (s Filestore) GetPrivateKeyPath() (string, error) {
 }
 
 func (s Filestore) Save(host *Host) error {
-	data, err := json.Marshal(host)
+	data, err := marshalHost(host, s.secretKey)
 	if err != nil {
 		return err
 	}
 
-	hostPath := filepath.Join(utils.GetMachineDir(), host.Name)
+	hostPath := filepath.Join(s.getMachinesDir(), host.Name)
 
 	if err := os.MkdirAll(hostPath, 0700); err != nil {
 		return err
 	}
 
-	if err := ioutil.WriteFile(filepath.Join(hostPath, "config.json"), data, 0600); err != nil {
+	if err := utils.WriteFileAtomic(filepath.Join(hostPath, "config.json"), data, 0600); err != nil {
 		return err
 	}
 
@@ -77,12 +93,12 @@ func (s Filestore) Save(host *Host) error {
 }
 
 func (s Filestore) Remove(name string, force bool) error {
-	hostPath := filepath.Join(utils.GetMachineDir(), name)
+	hostPath := filepath.Join(s.getMachinesDir(), name)
 	return os.RemoveAll(hostPath)
 }
 
 func (s Filestore) List() ([]*Host, error) {
-	dir, err := ioutil.ReadDir(utils.GetMachineDir())
+	dir, err := ioutil.ReadDir(s.getMachinesDir())
 	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
@@ -104,7 +120,7 @@ func (s Filestore) List() ([]*Host, error) {
 }
 
 func (s Filestore) Exists(name string) (bool, error) {
-	_, err := os.Stat(filepath.Join(utils.GetMachineDir(), name))
+	_, err := os.Stat(filepath.Join(s.getMachinesDir(), name))
 
 	if os.IsNotExist(err) {
 		return false, nil
@@ -126,6 +142,7 @@ func (s Filestore) GetActive() (*Host, error) {
 	}
 
 	hostListItems := GetHostListItems(hosts)
+	CloseHosts(hosts)
 
 	for _, item := range hostListItems {
 		if item.Active {
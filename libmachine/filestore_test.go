@@ -1,6 +1,7 @@
 package libmachine
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -232,3 +233,72 @@ func TestStoreGetSetActive(t *testing.T) {
 		t.Fatalf("Active host is not '%s', got %s", hostTestName, host.Name)
 	}
 }
+
+func TestStoreIndependentRoots(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherPath, err := ioutil.TempDir("", "machine-test-other-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(otherPath)
+
+	otherStore := NewFilestore(otherPath, hostTestCaCert, hostTestPrivateKey)
+
+	// the environment points to the first store
+	os.Setenv("MACHINE_STORAGE_PATH", store.GetPath())
+
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := otherStore.Save(host); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(otherPath, "machines", host.Name)); err != nil {
+		t.Fatalf("expected host to be saved in its own store: %s", err)
+	}
+
+	exists, err := store.Exists(host.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("expected host not to exist in the first store")
+	}
+
+	hosts, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 0 {
+		t.Fatalf("expected no hosts in the first store; received %d", len(hosts))
+	}
+
+	hosts, err = otherStore.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != host.Name {
+		t.Fatalf("expected host %s in the other store; received %v", host.Name, hosts)
+	}
+
+	if err := otherStore.Remove(host.Name, false); err != nil {
+		t.Fatal(err)
+	}
+
+	exists, err = otherStore.Exists(host.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("expected host to be removed from the other store")
+	}
+}
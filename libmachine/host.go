@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/docker/machine/drivers"
@@ -17,10 +19,12 @@ import (
 	"github.com/docker/machine/libmachine/provision/pkgaction"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
 	"github.com/docker/machine/ssh"
 	"github.com/docker/machine/state"
 	"github.com/docker/machine/utils"
 	"github.com/docker/machine/version"
+	"golang.org/x/net/context"
 )
 
 var (
@@ -37,6 +41,16 @@ type Host struct {
 	HostOptions   *HostOptions
 	Name          string `json:"-"`
 	StorePath     string
+
+	// CreateState is set while the machine is being created, and records
+	// the phases of the creation which are done.
+	CreateState *CreateState `json:",omitempty"`
+
+	// secretKey encrypts the credentials of the driver in config.json
+	secretKey *SecretKey
+
+	// journal records the events of the host in the store it is kept in
+	journal *EventJournal
 }
 
 type HostOptions struct {
@@ -46,6 +60,55 @@ type HostOptions struct {
 	EngineOptions *engine.EngineOptions
 	SwarmOptions  *swarm.SwarmOptions
 	AuthOptions   *auth.AuthOptions
+
+	// Labels are user defined key/value pairs for grouping machines.
+	Labels map[string]string `json:",omitempty"`
+}
+
+// The phases of creating a machine, in the order they are done.  They are
+// followed by the phases of provisioning it, see provision.PhaseHostname.
+const (
+	CreatePhaseInstance    = "instance-created"
+	CreatePhaseRunning     = "running"
+	CreatePhaseSSH         = "ssh-reachable"
+	CreatePhaseProvisioner = "provisioner-detected"
+)
+
+// CreateState records the progress of the creation of a machine, so that
+// it can be resumed from the first phase which is not done.
+type CreateState struct {
+	Phases []string
+}
+
+func (s *CreateState) Done(phase string) bool {
+	for _, p := range s.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// createCheckpoints records the phases of the creation of a host in its
+// config as they are done.
+type createCheckpoints struct {
+	host *Host
+}
+
+func (c createCheckpoints) Done(phase string) bool {
+	return c.host.CreateState.Done(phase)
+}
+
+func (c createCheckpoints) Progress() progress.Reporter {
+	return drivers.GetProgress(c.host.Driver)
+}
+
+func (c createCheckpoints) Complete(phase string) error {
+	state := c.host.CreateState
+	if !state.Done(phase) {
+		state.Phases = append(state.Phases, phase)
+	}
+	return c.host.SaveConfig()
 }
 
 type HostMetadata struct {
@@ -71,9 +134,11 @@ func (e ErrSavingConfig) Error() string {
 	return fmt.Sprintf("Error saving config: %s", e.wrappedErr)
 }
 
+// NewHost returns a new host whose files are kept in the directory set as
+// the StorePath of its auth options.
 func NewHost(name, driverName string, hostOptions *HostOptions) (*Host, error) {
 	authOptions := hostOptions.AuthOptions
-	storePath := filepath.Join(utils.GetMachineDir(), name)
+	storePath := authOptions.StorePath
 	driver, err := drivers.NewDriver(driverName, name, storePath, authOptions.CaCertPath, authOptions.PrivateKeyPath)
 	if err != nil {
 		return nil, err
@@ -106,36 +171,71 @@ func ValidateHostName(name string) bool {
 }
 
 func (h *Host) Create(name string) error {
-	// create the instance
-	if err := h.Driver.Create(); err != nil {
-		return err
+	return h.CreateContext(context.Background(), name)
+}
+
+// CreateContext creates the machine at the provider and provisions it.
+// Once ctx is done, no further step is started.  The phases which are done
+// are recorded in the config of the host, and skipped if the creation of
+// the host is resumed by calling CreateContext again.
+func (h *Host) CreateContext(ctx context.Context, name string) error {
+	return h.recordEvent("create", h.create(ctx))
+}
+
+func (h *Host) create(ctx context.Context) error {
+	driver := drivers.NewContextDriver(h.Driver)
+
+	if h.CreateState == nil {
+		h.CreateState = &CreateState{}
 	}
+	checkpoints := createCheckpoints{h}
 
-	// save to store
-	if err := h.SaveConfig(); err != nil {
+	// create the instance and save it to the store
+	if err := provision.RunPhase(checkpoints, CreatePhaseInstance, func() error {
+		return driver.CreateContext(ctx)
+	}); err != nil {
 		return err
 	}
 
 	// TODO: Not really a fan of just checking "none" here.
 	if h.Driver.DriverName() != "none" {
-		if err := utils.WaitFor(drivers.MachineInState(h.Driver, state.Running)); err != nil {
+		if err := provision.RunPhase(checkpoints, CreatePhaseRunning, func() error {
+			return utils.WaitForContext(ctx, drivers.MachineInState(h.Driver, state.Running))
+		}); err != nil {
 			return err
 		}
 
-		if err := WaitForSSH(h); err != nil {
+		if err := provision.RunPhase(checkpoints, CreatePhaseSSH, func() error {
+			return drivers.WaitForSSHContext(ctx, h.Driver)
+		}); err != nil {
 			return err
 		}
 
-		provisioner, err := provision.DetectProvisioner(h.Driver)
-		if err != nil {
+		// The provisioner is detected again when resuming, as it is
+		// not kept in the config.
+		var provisioner provision.Provisioner
+		if err := checkpoints.Progress().Phase(CreatePhaseProvisioner, func() error {
+			var err error
+			if provisioner, err = provision.DetectProvisioner(h.Driver); err != nil {
+				return err
+			}
+			return checkpoints.Complete(CreatePhaseProvisioner)
+		}); err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
 			return err
 		}
 
+		provisioner.SetCheckpoints(checkpoints)
 		if err := provisioner.Provision(*h.HostOptions.SwarmOptions, *h.HostOptions.AuthOptions, *h.HostOptions.EngineOptions); err != nil {
 			return err
 		}
 	}
 
+	h.CreateState = nil
+
 	return nil
 }
 
@@ -161,6 +261,22 @@ func (h *Host) CreateSSHClient() (ssh.Client, error) {
 	return ssh.NewClient(h.Driver.GetSSHUsername(), addr, port, auth)
 }
 
+// GetSSHAddress returns the host:port address of the SSH server of the
+// machine.
+func (h *Host) GetSSHAddress() (string, error) {
+	hostname, err := h.Driver.GetSSHHostname()
+	if err != nil {
+		return "", err
+	}
+
+	port, err := h.Driver.GetSSHPort()
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(hostname, strconv.Itoa(port)), nil
+}
+
 func (h *Host) CreateSSHShell() error {
 	client, err := h.CreateSSHClient()
 	if err != nil {
@@ -170,13 +286,13 @@ func (h *Host) CreateSSHShell() error {
 	return client.Shell()
 }
 
-func (h *Host) runActionForState(action func() error, desiredState state.State) error {
+func (h *Host) runActionForState(ctx context.Context, action func(context.Context) error, desiredState state.State) error {
 	if drivers.MachineInState(h.Driver, desiredState)() {
 		log.Debug("Machine already in state %s, returning", desiredState)
 		return nil
 	}
 
-	if err := action(); err != nil {
+	if err := action(ctx); err != nil {
 		return err
 	}
 
@@ -184,37 +300,72 @@ func (h *Host) runActionForState(action func() error, desiredState state.State)
 		return err
 	}
 
-	return utils.WaitFor(drivers.MachineInState(h.Driver, desiredState))
+	return utils.WaitForContext(ctx, drivers.MachineInState(h.Driver, desiredState))
 }
 
 func (h *Host) Start() error {
-	return h.runActionForState(h.Driver.Start, state.Running)
+	return h.StartContext(context.Background())
+}
+
+// StartContext starts the machine.  Paused and suspended machines are
+// resumed, if their driver can resume them.
+func (h *Host) StartContext(ctx context.Context) error {
+	if machineState, err := h.Driver.GetState(); err == nil && (machineState == state.Paused || machineState == state.Saved) {
+		if _, ok := h.Driver.(resumer); ok {
+			return h.recordEvent("start", h.resume(ctx))
+		}
+	}
+
+	driver := drivers.NewContextDriver(h.Driver)
+	return h.recordEvent("start", h.runActionForState(ctx, driver.StartContext, state.Running))
 }
 
 func (h *Host) Stop() error {
-	return h.runActionForState(h.Driver.Stop, state.Stopped)
+	return h.StopContext(context.Background())
+}
+
+func (h *Host) StopContext(ctx context.Context) error {
+	driver := drivers.NewContextDriver(h.Driver)
+	return h.recordEvent("stop", h.runActionForState(ctx, driver.StopContext, state.Stopped))
 }
 
 func (h *Host) Kill() error {
-	return h.runActionForState(h.Driver.Kill, state.Stopped)
+	return h.KillContext(context.Background())
+}
+
+func (h *Host) KillContext(ctx context.Context) error {
+	driver := drivers.NewContextDriver(h.Driver)
+	return h.recordEvent("kill", h.runActionForState(ctx, driver.KillContext, state.Stopped))
 }
 
 func (h *Host) Restart() error {
+	return h.RestartContext(context.Background())
+}
+
+func (h *Host) RestartContext(ctx context.Context) error {
+	return h.recordEvent("restart", h.restart(ctx))
+}
+
+// restart stops and starts the host without recording them as events of
+// their own.
+func (h *Host) restart(ctx context.Context) error {
+	driver := drivers.NewContextDriver(h.Driver)
+
 	if drivers.MachineInState(h.Driver, state.Running)() {
-		if err := h.Stop(); err != nil {
+		if err := h.runActionForState(ctx, driver.StopContext, state.Stopped); err != nil {
 			return err
 		}
 
-		if err := utils.WaitFor(drivers.MachineInState(h.Driver, state.Stopped)); err != nil {
+		if err := utils.WaitForContext(ctx, drivers.MachineInState(h.Driver, state.Stopped)); err != nil {
 			return err
 		}
 	}
 
-	if err := h.Start(); err != nil {
+	if err := h.runActionForState(ctx, driver.StartContext, state.Running); err != nil {
 		return err
 	}
 
-	if err := utils.WaitFor(drivers.MachineInState(h.Driver, state.Running)); err != nil {
+	if err := utils.WaitForContext(ctx, drivers.MachineInState(h.Driver, state.Running)); err != nil {
 		return err
 	}
 
@@ -226,13 +377,17 @@ func (h *Host) Restart() error {
 }
 
 func (h *Host) Upgrade() error {
+	return h.recordEvent("upgrade", h.upgrade())
+}
+
+func (h *Host) upgrade() error {
 	machineState, err := h.Driver.GetState()
 	if err != nil {
 		return err
 	}
 
 	if machineState != state.Running {
-		log.Fatal(errMachineMustBeRunningForUpgrade)
+		return errMachineMustBeRunningForUpgrade
 	}
 
 	provisioner, err := provision.DetectProvisioner(h.Driver)
@@ -240,6 +395,11 @@ func (h *Host) Upgrade() error {
 		return err
 	}
 
+	// The ISO based provisioners keep the new ISO in the machine directory.
+	if h.HostOptions != nil && h.HostOptions.AuthOptions != nil {
+		provisioner.SetAuthOptions(*h.HostOptions.AuthOptions)
+	}
+
 	if err := provisioner.Package("docker", pkgaction.Upgrade); err != nil {
 		return err
 	}
@@ -251,7 +411,15 @@ func (h *Host) Upgrade() error {
 }
 
 func (h *Host) Remove(force bool) error {
-	if err := h.Driver.Remove(); err != nil {
+	return h.RemoveContext(context.Background(), force)
+}
+
+func (h *Host) RemoveContext(ctx context.Context, force bool) error {
+	return h.recordEvent("remove", h.remove(ctx, force))
+}
+
+func (h *Host) remove(ctx context.Context, force bool) error {
+	if err := drivers.NewContextDriver(h.Driver).RemoveContext(ctx); err != nil {
 		if !force {
 			return err
 		}
@@ -275,6 +443,24 @@ func (h *Host) removeStorePath() error {
 	return os.RemoveAll(h.StorePath)
 }
 
+// Close releases what the driver of the host holds on to, such as the
+// process of a driver plugin.  The host must not be used afterwards.
+func (h *Host) Close() error {
+	if h.Driver == nil {
+		return nil
+	}
+	return drivers.CloseDriver(h.Driver)
+}
+
+// CloseHosts closes every host, e.g. once a list of hosts has been used.
+func CloseHosts(hosts []*Host) {
+	for _, host := range hosts {
+		if err := host.Close(); err != nil {
+			log.Debugf("Error closing host %s: %s", host.Name, err)
+		}
+	}
+}
+
 func (h *Host) GetURL() (string, error) {
 	return h.Driver.GetURL()
 }
@@ -303,7 +489,7 @@ func (h *Host) IsActive() (bool, error) {
 	dockerHost := os.Getenv("DOCKER_HOST")
 
 	notStopped := currentState != state.Stopped
-	correctURL := url == dockerHost
+	correctURL := url != "" && url == dockerHost
 
 	isActive := notStopped && correctURL
 
@@ -316,23 +502,69 @@ func (h *Host) LoadConfig() error {
 		return err
 	}
 
-	// Remember the machine name and store path so we don't have to pass it
-	// through each struct in the migration.
+	// Remember the machine name, store path and key so we don't have to
+	// pass them through each struct in the migration.
 	name := h.Name
 	storePath := h.StorePath
+	secretKey := h.secretKey
+	journal := h.journal
+
+	migrated, from, err := migrateConfig(name, data)
+	if err != nil {
+		return err
+	}
+
+	// If we end up performing a migration, we should save afterwards so
+	// we don't have to do it again on subsequent invocations.  The
+	// original config is backed up first.
+	migrationPerformed := from != version.ConfigVersion
+	if migrationPerformed {
+		if err := backupConfig(storePath, from, data); err != nil {
+			return fmt.Errorf("Error backing up config before migration: %s", err)
+		}
+	}
 
-	// If we end up performing a migration, we should save afterwards so we don't have to do it again on subsequent invocations.
-	migrationPerformed := h.ConfigVersion != version.ConfigVersion
+	// Reloading the config of a host loads a new driver, so the previous
+	// one has to be closed.
+	previousDriver := h.Driver
 
-	migratedHost, err := MigrateHost(h, data)
+	migratedHost, err := unmarshalHost(h, migrated)
 	if err != nil {
 		return fmt.Errorf("Error getting migrated host: %s", err)
 	}
 
+	if previousDriver != nil && previousDriver != migratedHost.Driver {
+		drivers.CloseDriver(previousDriver)
+	}
+
 	*h = *migratedHost
 
 	h.Name = name
 	h.StorePath = storePath
+	h.secretKey = secretKey
+	h.journal = journal
+
+	if h.Driver != nil {
+		driver, plaintext, err := decryptSecrets(h.Driver, secretKey)
+		if err != nil {
+			drivers.CloseDriver(h.Driver)
+			return fmt.Errorf("Error decrypting the credentials of machine %s: %s", name, err)
+		}
+		h.Driver = driver.(drivers.Driver)
+		registerSecrets(h.Driver)
+
+		// Credentials which were stored in plaintext get encrypted by
+		// saving the config again.
+		if plaintext && secretKey != nil {
+			migrationPerformed = true
+		}
+	}
+
+	// The auth options must follow the host if its directory was moved,
+	// as the provisioners copy the client certificates into it.
+	if h.HostOptions != nil && h.HostOptions.AuthOptions != nil {
+		h.HostOptions.AuthOptions.StorePath = storePath
+	}
 
 	if migrationPerformed {
 		if err := h.SaveConfig(); err != nil {
@@ -344,6 +576,10 @@ func (h *Host) LoadConfig() error {
 }
 
 func (h *Host) ConfigureAuth() error {
+	return h.recordEvent("configure-auth", h.configureAuth())
+}
+
+func (h *Host) configureAuth() error {
 	if err := h.LoadConfig(); err != nil {
 		return err
 	}
@@ -365,18 +601,65 @@ func (h *Host) ConfigureAuth() error {
 	return nil
 }
 
+// configureName sets the hostname of a running machine to its name and
+// regenerates its server certificate.  Stopped machines are left alone, as
+// regenerating their certificates sets both up once they are started.
+func (h *Host) configureName() error {
+	machineState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+
+	if machineState != state.Running {
+		drivers.GetProgress(h.Driver).Infof("Machine %s is not running; run 'docker-machine regenerate-certs %s' once it is started to update its hostname and certificates.", h.Name, h.Name)
+		return nil
+	}
+
+	provisioner, err := provision.DetectProvisioner(h.Driver)
+	if err != nil {
+		return err
+	}
+
+	if err := provisioner.SetHostname(h.Name); err != nil {
+		return err
+	}
+
+	// As in ConfigureAuth, provisioning again regenerates the server
+	// certificate with provision.ConfigureAuth and restarts the daemon
+	// and swarm with it.
+	return provisioner.Provision(*h.HostOptions.SwarmOptions, *h.HostOptions.AuthOptions, *h.HostOptions.EngineOptions)
+}
+
 func (h *Host) SaveConfig() error {
-	data, err := json.Marshal(h)
+	data, err := marshalHost(h, h.secretKey)
 	if err != nil {
 		return err
 	}
 
-	if err := ioutil.WriteFile(filepath.Join(h.StorePath, "config.json"), data, 0600); err != nil {
+	if err := utils.WriteFileAtomic(filepath.Join(h.StorePath, "config.json"), data, 0600); err != nil {
 		return err
 	}
 	return nil
 }
 
+// marshalHost returns the config of the host with the credentials of its
+// driver encrypted with key.  If key is nil, they are left in plaintext.
+func marshalHost(h *Host, key *SecretKey) ([]byte, error) {
+	if key == nil || h.Driver == nil {
+		return json.Marshal(h)
+	}
+
+	driver, err := encryptSecrets(h.Driver, key)
+	if err != nil {
+		return nil, fmt.Errorf("Error encrypting the credentials of machine %s: %s", h.Name, err)
+	}
+
+	encrypted := *h
+	encrypted.Driver = driver.(drivers.Driver)
+
+	return json.Marshal(&encrypted)
+}
+
 func (h *Host) PrintIP() error {
 	if ip, err := h.Driver.GetIP(); err != nil {
 		return err
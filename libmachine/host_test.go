@@ -6,9 +6,11 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/docker/machine/drivers"
 	"github.com/docker/machine/drivers/fakedriver"
 	_ "github.com/docker/machine/drivers/none"
 	"github.com/docker/machine/libmachine/auth"
@@ -79,6 +81,7 @@ func getDefaultTestHost() (*Host, error) {
 			Address:   "",
 		},
 		AuthOptions: &auth.AuthOptions{
+			StorePath:      filepath.Join(hostTestStorePath, "machines", hostTestName),
 			CaCertPath:     hostTestCaCert,
 			PrivateKeyPath: hostTestPrivateKey,
 		},
@@ -96,6 +99,22 @@ func getDefaultTestHost() (*Host, error) {
 	return host, nil
 }
 
+// getFakeTestHost returns a host of a fake driver, kept in a new test
+// store.
+func getFakeTestHost(t *testing.T, driver drivers.Driver) *Host {
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Host{
+		Name:       hostTestName,
+		DriverName: "fakedriver",
+		Driver:     driver,
+		StorePath:  store.GetPath(),
+	}
+}
+
 func TestLoadHostDoesNotExist(t *testing.T) {
 	_, err := LoadHost("nope-not-here", "/nope/doesnotexist")
 	if err == nil {
@@ -313,3 +332,17 @@ func TestGetHostListItems(t *testing.T) {
 		}
 	}
 }
+
+func TestHostUpgradeNotRunning(t *testing.T) {
+	defer cleanup()
+
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	host.Driver = &fakedriver.FakeDriver{MockState: state.Stopped}
+
+	if err := host.Upgrade(); err != errMachineMustBeRunningForUpgrade {
+		t.Fatalf("expected %q; received %v", errMachineMustBeRunningForUpgrade, err)
+	}
+}
@@ -0,0 +1,201 @@
+package libmachine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/progress"
+	"golang.org/x/net/context"
+)
+
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+
+	// jobRetention is how long finished jobs are kept
+	jobRetention = time.Hour
+)
+
+// Job is an operation on a machine run in the background by an APIServer.
+type Job struct {
+	ID       string
+	Machine  string
+	Action   string
+	State    JobState
+	Error    string `json:",omitempty"`
+	Started  time.Time
+	Finished time.Time
+}
+
+func (j Job) Done() bool {
+	return j.State == JobSucceeded || j.State == JobFailed
+}
+
+// jobRecord is a job along with its log, the progress events of the
+// operation.  changed is closed, and replaced, when the job changes.
+type jobRecord struct {
+	job     Job
+	log     []progress.Event
+	cancel  context.CancelFunc
+	changed chan struct{}
+}
+
+// notify wakes up the followers of the log of the job.  m.mu is held.
+func (r *jobRecord) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// jobManager runs the jobs of an APIServer.
+type jobManager struct {
+	mu     sync.Mutex
+	nextID int
+	jobs   map[string]*jobRecord
+}
+
+func newJobManager() *jobManager {
+	return &jobManager{
+		jobs: make(map[string]*jobRecord),
+	}
+}
+
+// Start runs fn in the background as the job "action" on the machine
+// "machine".  fn is passed a context which is done when the job is
+// cancelled.
+func (m *jobManager) Start(machine, action string, fn func(context.Context) error) Job {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	m.prune()
+	m.nextID++
+	record := &jobRecord{
+		job: Job{
+			ID:      fmt.Sprintf("%d", m.nextID),
+			Machine: machine,
+			Action:  action,
+			State:   JobRunning,
+			Started: time.Now(),
+		},
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+	m.jobs[record.job.ID] = record
+	job := record.job
+	m.mu.Unlock()
+
+	go func() {
+		err := fn(ctx)
+		cancel()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		record.job.Finished = time.Now()
+		if err != nil {
+			record.job.State = JobFailed
+			record.job.Error = err.Error()
+		} else {
+			record.job.State = JobSucceeded
+		}
+		record.notify()
+	}()
+
+	return job
+}
+
+// prune removes the jobs which finished more than jobRetention ago.  m.mu
+// is held.
+func (m *jobManager) prune() {
+	for id, record := range m.jobs {
+		if record.job.Done() && time.Since(record.job.Finished) > jobRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func (m *jobManager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return record.job, true
+}
+
+// List returns the jobs, oldest first.
+func (m *jobManager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := []Job{}
+	for _, record := range m.jobs {
+		jobs = append(jobs, record.job)
+	}
+	sort.Sort(jobsByStart(jobs))
+	return jobs
+}
+
+// Cancel cancels a running job.  The job fails once its operation returns.
+func (m *jobManager) Cancel(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	record.cancel()
+	return record.job, true
+}
+
+// Publish adds the event to the logs of the running jobs on its machine.
+func (m *jobManager) Publish(e progress.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, record := range m.jobs {
+		if record.job.Machine == e.Machine && !record.job.Done() {
+			record.log = append(record.log, e)
+			record.notify()
+		}
+	}
+}
+
+// Log returns the events of the log of a job from index "from" on.  done
+// is set once the job is finished and every event was returned; until
+// then, changed is closed when there is more to read.
+func (m *jobManager) Log(id string, from int) (events []progress.Event, done bool, changed <-chan struct{}, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.jobs[id]
+	if !ok {
+		return nil, false, nil, false
+	}
+
+	if from < len(record.log) {
+		events = append(events, record.log[from:]...)
+	}
+	done = record.job.Done() && from+len(events) >= len(record.log)
+	return events, done, record.changed, true
+}
+
+type jobsByStart []Job
+
+func (s jobsByStart) Len() int {
+	return len(s)
+}
+
+func (s jobsByStart) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s jobsByStart) Less(i, j int) bool {
+	return s[i].Started.Before(s[j].Started)
+}
@@ -0,0 +1,130 @@
+package libmachine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var validLabelKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-\._/]*$`)
+
+// ParseLabel parses a label of the form key=value.
+func ParseLabel(label string) (string, string, error) {
+	kv := strings.SplitN(label, "=", 2)
+	if len(kv) != 2 {
+		return "", "", fmt.Errorf("Invalid label %q, expected key=value", label)
+	}
+
+	if !validLabelKeyPattern.MatchString(kv[0]) {
+		return "", "", fmt.Errorf("Invalid label key %q", kv[0])
+	}
+
+	return kv[0], kv[1], nil
+}
+
+// ParseLabels parses labels of the form key=value.
+func ParseLabels(labels []string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	for _, label := range labels {
+		key, value, err := ParseLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		parsed[key] = value
+	}
+	return parsed, nil
+}
+
+// FormatLabels returns the labels as key=value pairs, sorted by key.
+func FormatLabels(labels map[string]string) []string {
+	formatted := []string{}
+	for key, value := range labels {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(formatted)
+	return formatted
+}
+
+type labelRequirement struct {
+	key      string
+	value    string
+	negated  bool
+	hasValue bool
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch {
+	case !r.hasValue:
+		return ok != r.negated
+	case r.negated:
+		return !ok || value != r.value
+	default:
+		return ok && value == r.value
+	}
+}
+
+// LabelSelector selects machines by their labels.
+type LabelSelector struct {
+	requirements []labelRequirement
+}
+
+// ParseLabelSelector parses a comma separated list of requirements a
+// machine must all meet to be selected: key=value, key!=value, key (the
+// label is set) or !key (the label is not set).
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	s := LabelSelector{}
+
+	for _, req := range strings.Split(selector, ",") {
+		req = strings.TrimSpace(req)
+		if req == "" {
+			continue
+		}
+
+		r := labelRequirement{}
+		switch {
+		case strings.Contains(req, "!="):
+			kv := strings.SplitN(req, "!=", 2)
+			r.key, r.value, r.negated, r.hasValue = kv[0], kv[1], true, true
+		case strings.Contains(req, "="):
+			kv := strings.SplitN(req, "=", 2)
+			r.key, r.value, r.hasValue = kv[0], kv[1], true
+		case strings.HasPrefix(req, "!"):
+			r.key, r.negated = req[1:], true
+		default:
+			r.key = req
+		}
+
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+
+		if !validLabelKeyPattern.MatchString(r.key) {
+			return s, fmt.Errorf("Invalid label selector %q", req)
+		}
+
+		s.requirements = append(s.requirements, r)
+	}
+
+	if len(s.requirements) == 0 {
+		return s, fmt.Errorf("Empty label selector")
+	}
+
+	return s, nil
+}
+
+// Matches returns whether the host meets all the requirements of the
+// selector.
+func (s LabelSelector) Matches(h *Host) bool {
+	var labels map[string]string
+	if h.HostOptions != nil {
+		labels = h.HostOptions.Labels
+	}
+
+	for _, r := range s.requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package libmachine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"team=web", "purpose=ci=nightly", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"team": "web", "purpose": "ci=nightly", "empty": ""}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("expected %v; received %v", expected, labels)
+	}
+
+	for _, invalid := range []string{"team", "=web", "te am=web"} {
+		if _, err := ParseLabels([]string{invalid}); err == nil {
+			t.Fatalf("expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	formatted := FormatLabels(map[string]string{"team": "web", "env": "dev"})
+
+	expected := []string{"env=dev", "team=web"}
+	if !reflect.DeepEqual(formatted, expected) {
+		t.Fatalf("expected %v; received %v", expected, formatted)
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	host := &Host{
+		HostOptions: &HostOptions{
+			Labels: map[string]string{"team": "web", "env": "dev"},
+		},
+	}
+
+	matching := []string{"team=web", "team=web,env=dev", "env!=prod", "team", "!project", " team = web "}
+	for _, s := range matching {
+		selector, err := ParseLabelSelector(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !selector.Matches(host) {
+			t.Fatalf("expected %q to match", s)
+		}
+	}
+
+	notMatching := []string{"team=db", "team=web,env=prod", "env!=dev", "project", "!team"}
+	for _, s := range notMatching {
+		selector, err := ParseLabelSelector(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if selector.Matches(host) {
+			t.Fatalf("expected %q not to match", s)
+		}
+	}
+
+	for _, invalid := range []string{"", ",", "=web", "!"} {
+		if _, err := ParseLabelSelector(invalid); err == nil {
+			t.Fatalf("expected an error parsing %q", invalid)
+		}
+	}
+}
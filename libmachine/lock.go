@@ -0,0 +1,148 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/machine/log"
+)
+
+const (
+	hostLockFile = ".lock"
+
+	// a lock file which can not be parsed is only considered stale after
+	// this grace period, as its owner may still be writing it
+	lockWriteGracePeriod = 5 * time.Second
+)
+
+// HostLock is an advisory lock on a host directory.  It is held by
+// operations which change a machine, so that two of them can not run
+// against the same machine at the same time.
+type HostLock struct {
+	path string
+}
+
+type hostLockInfo struct {
+	Pid     int
+	Command string
+	Created time.Time
+}
+
+// LockHost takes the lock for the host stored in hostPath on behalf of
+// command.  If the lock is held by a running process, ErrHostLocked is
+// returned.  Locks left behind by processes which no longer exist are
+// removed.
+func LockHost(hostPath, command string) (*HostLock, error) {
+	lockPath := filepath.Join(hostPath, hostLockFile)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			info := hostLockInfo{
+				Pid:     os.Getpid(),
+				Command: command,
+				Created: time.Now(),
+			}
+			if err := json.NewEncoder(f).Encode(info); err != nil {
+				f.Close()
+				os.Remove(lockPath)
+				return nil, err
+			}
+			if err := f.Close(); err != nil {
+				os.Remove(lockPath)
+				return nil, err
+			}
+			return &HostLock{path: lockPath}, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		stale, lockErr := checkHostLock(lockPath)
+		if !stale {
+			return nil, lockErr
+		}
+
+		if err := removeStaleLock(lockPath); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// removeStaleLock removes the lock file at lockPath, which was found to be
+// stale.  Another process may have removed it and taken the lock since, so
+// the file is moved aside first, which only one process can do, and
+// checked again.  A live lock which was moved aside is put back.
+func removeStaleLock(lockPath string) error {
+	stalePath := fmt.Sprintf("%s.%d.%d.stale", lockPath, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(lockPath, stalePath); err != nil {
+		if os.IsNotExist(err) {
+			// removed by someone else in the meantime
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(stalePath)
+
+	if stale, _ := checkHostLock(stalePath); stale {
+		log.Debugf("Removed stale lock %s", lockPath)
+		return nil
+	}
+
+	// Linking fails if the lock was taken again in the meantime, in which
+	// case the lock which was moved aside is lost to its owner.
+	if err := os.Link(stalePath, lockPath); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+// checkHostLock returns whether the existing lock file is stale, and the
+// error describing the owner of the lock if it is not.
+func checkHostLock(lockPath string) (bool, error) {
+	name := filepath.Base(filepath.Dir(lockPath))
+
+	fi, err := os.Stat(lockPath)
+	if err != nil {
+		// the lock went away in the meantime
+		return os.IsNotExist(err), err
+	}
+
+	data, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return os.IsNotExist(err), err
+	}
+
+	var info hostLockInfo
+	if err := json.Unmarshal(data, &info); err != nil || info.Pid == 0 {
+		if time.Since(fi.ModTime()) > lockWriteGracePeriod {
+			return true, nil
+		}
+		return false, ErrHostLocked{
+			Name: name,
+		}
+	}
+
+	if !processExists(info.Pid) {
+		return true, nil
+	}
+
+	return false, ErrHostLocked{
+		Name:    name,
+		Pid:     info.Pid,
+		Command: info.Command,
+	}
+}
+
+// Unlock releases the lock.
+func (l *HostLock) Unlock() error {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
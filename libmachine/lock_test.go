@@ -0,0 +1,136 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func getTestHostDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "machine-lock-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLockHost(t *testing.T) {
+	dir := getTestHostDir(t)
+	defer os.RemoveAll(dir)
+
+	lock, err := LockHost(dir, "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LockHost(dir, "start")
+	lockErr, ok := err.(ErrHostLocked)
+	if !ok {
+		t.Fatalf("expected ErrHostLocked; received %v", err)
+	}
+	if lockErr.Pid != os.Getpid() {
+		t.Fatalf("expected pid %d; received %d", os.Getpid(), lockErr.Pid)
+	}
+	if lockErr.Command != "create" {
+		t.Fatalf("expected command create; received %s", lockErr.Command)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err = LockHost(dir, "start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockHostStale(t *testing.T) {
+	dir := getTestHostDir(t)
+	defer os.RemoveAll(dir)
+
+	// a pid which does not belong to any process
+	data, err := json.Marshal(hostLockInfo{
+		Pid:     0x7fffffff,
+		Command: "create",
+		Created: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, hostLockFile), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := LockHost(dir, "start")
+	if err != nil {
+		t.Fatalf("expected the stale lock to be taken over; received %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockHostUnreadable(t *testing.T) {
+	dir := getTestHostDir(t)
+	defer os.RemoveAll(dir)
+
+	lockPath := filepath.Join(dir, hostLockFile)
+	if err := ioutil.WriteFile(lockPath, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the owner might still be writing the lock
+	if _, err := LockHost(dir, "start"); err == nil {
+		t.Fatal("expected a fresh unreadable lock to be respected")
+	}
+
+	old := time.Now().Add(-2 * lockWriteGracePeriod)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := LockHost(dir, "start")
+	if err != nil {
+		t.Fatalf("expected the old unreadable lock to be taken over; received %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveStaleLockLive(t *testing.T) {
+	dir := getTestHostDir(t)
+	defer os.RemoveAll(dir)
+
+	lock, err := LockHost(dir, "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+
+	// another process found the lock which has been replaced by this one
+	// to be stale
+	if err := removeStaleLock(filepath.Join(dir, hostLockFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LockHost(dir, "start"); err == nil {
+		t.Fatal("expected the live lock to be kept")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected only the lock file to be left; found %d files", len(files))
+	}
+}
@@ -0,0 +1,119 @@
+package libmachine
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/libmachine/swarm"
+	"golang.org/x/net/context"
+)
+
+// MachineAPI is the surface shared by a Provider, on the machines of its
+// store, and by the client of the machine API in libmachine/client, on the
+// machines of a server, so that programs can use either.  The operations
+// return once they are done.  Machines are created with Provider.Create,
+// which takes the options of the driver, or with the client, which takes a
+// CreateRequest.
+type MachineAPI interface {
+	ListMachines() ([]MachineInfo, error)
+	GetMachine(name string) (MachineInfo, error)
+	Exists(name string) (bool, error)
+	RemoveContext(ctx context.Context, name string, force bool) error
+
+	// RunAction runs start, stop, restart, kill, pause, suspend, resume,
+	// upgrade or regenerate-certs on a machine.
+	RunAction(ctx context.Context, name, action string) error
+
+	UpdateLabels(name string, set map[string]string, remove []string) error
+	Rename(oldName, newName string) error
+	Resize(name string, opts drivers.ResizeOptions) error
+
+	ListSnapshots(name string) ([]drivers.Snapshot, error)
+	TakeSnapshot(name, snapshot string) error
+	RestoreSnapshot(name, snapshot string) error
+	RemoveSnapshot(name, snapshot string) error
+}
+
+// MachineInfo is the wire representation of a machine in the machine API.
+type MachineInfo struct {
+	Name         string
+	DriverName   string
+	State        string
+	URL          string              `json:",omitempty"`
+	Labels       map[string]string   `json:",omitempty"`
+	SwarmOptions *swarm.SwarmOptions `json:",omitempty"`
+}
+
+// hostActions are the operations RunAction runs on a machine, by name.
+var hostActions = map[string]func(*Host, context.Context) error{
+	"start":            (*Host).StartContext,
+	"stop":             (*Host).StopContext,
+	"restart":          (*Host).RestartContext,
+	"kill":             (*Host).KillContext,
+	"pause":            (*Host).PauseContext,
+	"suspend":          (*Host).SuspendContext,
+	"resume":           (*Host).ResumeContext,
+	"upgrade":          func(h *Host, ctx context.Context) error { return h.Upgrade() },
+	"regenerate-certs": func(h *Host, ctx context.Context) error { return h.ConfigureAuth() },
+}
+
+// ListMachines returns the machines of the store along with their state.
+func (provider *Provider) ListMachines() ([]MachineInfo, error) {
+	hosts, err := provider.List()
+	if err != nil {
+		return nil, err
+	}
+	defer CloseHosts(hosts)
+
+	return getMachineInfos(hosts), nil
+}
+
+// GetMachine returns the machine "name" along with its state.
+func (provider *Provider) GetMachine(name string) (MachineInfo, error) {
+	host, err := provider.Get(name)
+	if err != nil {
+		return MachineInfo{}, err
+	}
+	defer host.Close()
+
+	return getMachineInfos([]*Host{host})[0], nil
+}
+
+// RunAction runs "action" on the machine "name" while it is locked, and
+// saves the machine afterwards.
+func (provider *Provider) RunAction(ctx context.Context, name, action string) error {
+	hostAction, ok := hostActions[action]
+	if !ok {
+		return fmt.Errorf("Unknown action %s", action)
+	}
+
+	return provider.withLockedHost(name, action, func(host *Host) error {
+		return hostAction(host, ctx)
+	})
+}
+
+// getMachineInfos returns the machines along with their state, which is
+// queried concurrently.
+func getMachineInfos(hosts []*Host) []MachineInfo {
+	items := make(map[string]HostListItem)
+	for _, item := range GetHostListItems(hosts) {
+		items[item.Name] = item
+	}
+
+	infos := []MachineInfo{}
+	for _, host := range hosts {
+		item := items[host.Name]
+		info := MachineInfo{
+			Name:       host.Name,
+			DriverName: host.DriverName,
+			State:      item.State.String(),
+			URL:        item.URL,
+		}
+		if host.HostOptions != nil {
+			info.Labels = host.HostOptions.Labels
+			info.SwarmOptions = host.HostOptions.SwarmOptions
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
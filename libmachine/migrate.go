@@ -3,36 +3,181 @@ package libmachine
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
 	"github.com/docker/machine/version"
 )
 
-func getMigratedHostMetadata(data []byte) (*HostMetadata, error) {
-	// HostMetadata is for a "first pass" so we can then load the driver
-	var (
-		hostMetadata *HostMetadataV0
-	)
+// configBackupFormat is the name of the backup of a config, by the version
+// it had, which is kept in the machine directory before it is migrated.
+const configBackupFormat = "config.json.v%d.bak"
+
+// A MigrateFunc migrates a JSON encoded config to the next config version.
+type MigrateFunc func(data []byte) ([]byte, error)
+
+type migration struct {
+	description string
+	migrate     MigrateFunc
+}
+
+var (
+	migrations       = make(map[int]migration)
+	driverMigrations = make(map[string]map[int]MigrateFunc)
+)
+
+// RegisterMigration registers the migration of host configs from version
+// "from" to the next version.  Configs are migrated one version at a time,
+// and the config version is set by the caller.
+func RegisterMigration(from int, description string, fn MigrateFunc) {
+	migrations[from] = migration{
+		description: description,
+		migrate:     fn,
+	}
+}
+
+// RegisterDriverMigration registers a hook which migrates the config of the
+// driver "driverName" from version "from" to the next version.  It is given
+// the JSON encoded config of the driver, after the host config was migrated.
+func RegisterDriverMigration(driverName string, from int, fn MigrateFunc) {
+	if driverMigrations[driverName] == nil {
+		driverMigrations[driverName] = make(map[int]MigrateFunc)
+	}
+	driverMigrations[driverName][from] = fn
+}
+
+// HostMigration is the migration of the config of a host to the current
+// config version.  The configs are indented JSON.
+type HostMigration struct {
+	Name        string
+	FromVersion int
+	ToVersion   int
+	Original    []byte
+	Migrated    []byte
+	Err         error
+}
+
+func getConfigVersion(data []byte) (int, error) {
+	var config struct {
+		ConfigVersion int
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return 0, err
+	}
+	return config.ConfigVersion, nil
+}
+
+// setConfigField sets a top level field of a JSON encoded config.
+func setConfigField(data []byte, name string, value interface{}) ([]byte, error) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	field, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	config[name] = json.RawMessage(field)
 
-	if err := json.Unmarshal(data, &hostMetadata); err != nil {
-		return &HostMetadata{}, err
+	return json.Marshal(config)
+}
+
+// migrateConfig migrates the JSON encoded config of the host "name" to the
+// current config version, and returns the version it was migrated from.
+// Configs written by a newer version of machine are refused.
+func migrateConfig(name string, data []byte) ([]byte, int, error) {
+	from, err := getConfigVersion(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error reading the config version of machine %s: %s", name, err)
+	}
+
+	if from > version.ConfigVersion {
+		return nil, from, ErrConfigVersionTooNew{
+			Name:    name,
+			Version: from,
+		}
 	}
 
-	migratedHostMetadata := MigrateHostMetadataV0ToHostMetadataV1(hostMetadata)
+	for v := from; v < version.ConfigVersion; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return nil, from, fmt.Errorf("No migration of machine %s from config version %d", name, v)
+		}
+
+		log.Debugf("Migrating machine %s from config version %d: %s", name, v, m.description)
+		if data, err = m.migrate(data); err != nil {
+			return nil, from, fmt.Errorf("Error migrating machine %s from config version %d: %s", name, v, err)
+		}
+
+		if data, err = migrateDriverConfig(data, v); err != nil {
+			return nil, from, fmt.Errorf("Error migrating the driver of machine %s from config version %d: %s", name, v, err)
+		}
+
+		if data, err = setConfigField(data, "ConfigVersion", v+1); err != nil {
+			return nil, from, err
+		}
+	}
+
+	return data, from, nil
+}
+
+// migrateDriverConfig runs the hook the driver of the host registered for
+// the migration from version "from", if any.
+func migrateDriverConfig(data []byte, from int) ([]byte, error) {
+	var config struct {
+		DriverName string
+		Driver     json.RawMessage
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	fn, ok := driverMigrations[config.DriverName][from]
+	if !ok {
+		return data, nil
+	}
+
+	driverConfig, err := fn(config.Driver)
+	if err != nil {
+		return nil, err
+	}
 
-	return migratedHostMetadata, nil
+	return setConfigField(data, "Driver", json.RawMessage(driverConfig))
 }
 
+// MigrateHost returns the host in the JSON encoded config, migrated to the
+// current config version.
 func MigrateHost(h *Host, data []byte) (*Host, error) {
-	migratedHostMetadata, err := getMigratedHostMetadata(data)
+	migrated, _, err := migrateConfig(h.Name, data)
 	if err != nil {
 		return &Host{}, err
 	}
 
-	authOptions := migratedHostMetadata.HostOptions.AuthOptions
+	return unmarshalHost(h, migrated)
+}
+
+// unmarshalHost decodes a config at the current config version into h,
+// along with the driver it names.
+func unmarshalHost(h *Host, data []byte) (*Host, error) {
+	// a first pass to find out which driver to load the config with
+	var metadata HostMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return &Host{}, err
+	}
+
+	authOptions := metadata.HostOptions.AuthOptions
+	if authOptions == nil {
+		return &Host{}, fmt.Errorf("The config of machine %s has no auth options", h.Name)
+	}
 
 	driver, err := drivers.NewDriver(
-		migratedHostMetadata.DriverName,
+		metadata.DriverName,
 		h.Name,
 		h.StorePath,
 		authOptions.CaCertPath,
@@ -42,24 +187,103 @@ func MigrateHost(h *Host, data []byte) (*Host, error) {
 		return &Host{}, err
 	}
 
-	for h.ConfigVersion = migratedHostMetadata.ConfigVersion; h.ConfigVersion < version.ConfigVersion; h.ConfigVersion++ {
-		switch h.ConfigVersion {
-		case 0:
-			hostV0 := &HostV0{
-				Driver: driver,
-			}
-			if err := json.Unmarshal(data, &hostV0); err != nil {
-				return &Host{}, fmt.Errorf("Error unmarshalling host config version 0: %s", err)
-			}
-			h = MigrateHostV0ToHostV1(hostV0)
-		default:
-		}
-	}
-
 	h.Driver = driver
 	if err := json.Unmarshal(data, &h); err != nil {
+		drivers.CloseDriver(driver)
 		return &Host{}, fmt.Errorf("Error unmarshalling most recent host version: %s", err)
 	}
 
 	return h, nil
 }
+
+// backupConfig keeps a copy of the config of a host as it was at version
+// "from", before it is migrated.  An existing backup of that version is
+// left as is.
+func backupConfig(hostPath string, from int, data []byte) error {
+	backupPath := filepath.Join(hostPath, fmt.Sprintf(configBackupFormat, from))
+	if _, err := os.Stat(backupPath); err == nil {
+		return nil
+	}
+
+	log.Debugf("Backing up the config of %s to %s", filepath.Base(hostPath), backupPath)
+	return utils.WriteFileAtomic(backupPath, data, 0600)
+}
+
+// PlanMigrations returns the migrations the configs of the hosts in the
+// store need, without performing them or loading the hosts.  A host whose
+// config can not be migrated has Err set.  The credentials of the drivers
+// are registered with the log package, so that they can be redacted.
+func (s Filestore) PlanMigrations() ([]HostMigration, error) {
+	dir, err := ioutil.ReadDir(s.getMachinesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	plan := []HostMigration{}
+
+	for _, file := range dir {
+		if !file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		m, err := s.planMigration(file.Name())
+		if err != nil {
+			m.Err = err
+		}
+		if m.Err != nil || m.FromVersion != m.ToVersion {
+			plan = append(plan, m)
+		}
+	}
+
+	return plan, nil
+}
+
+func (s Filestore) planMigration(name string) (HostMigration, error) {
+	m := HostMigration{
+		Name:      name,
+		ToVersion: version.ConfigVersion,
+	}
+
+	hostPath := filepath.Join(s.getMachinesDir(), name)
+	data, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json"))
+	if err != nil {
+		return m, err
+	}
+
+	if m.Original, err = indentConfig(data); err != nil {
+		return m, err
+	}
+
+	migrated, from, err := migrateConfig(name, data)
+	m.FromVersion = from
+	if err != nil {
+		return m, err
+	}
+	if from == version.ConfigVersion {
+		return m, nil
+	}
+
+	host, err := unmarshalHost(&Host{Name: name, StorePath: hostPath}, migrated)
+	if err != nil {
+		return m, err
+	}
+	defer host.Close()
+	registerSecrets(host.Driver)
+
+	// The credentials of the driver are not decrypted, so they are
+	// written back as they are.
+	if data, err = json.Marshal(host); err != nil {
+		return m, err
+	}
+	m.Migrated, err = indentConfig(data)
+
+	return m, err
+}
+
+func indentConfig(data []byte) ([]byte, error) {
+	var config interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(config, "", "    ")
+}
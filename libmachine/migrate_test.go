@@ -0,0 +1,162 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/version"
+)
+
+const migrateTestConfigV0 = `{
+	"DriverName": "none",
+	"Driver": {"URL": "tcp://1.2.3.4:2376", "MachineName": "test-host"},
+	"CaCertPath": "/tmp/certs/ca.pem",
+	"PrivateKeyPath": "/tmp/certs/ca-key.pem",
+	"SwarmDiscovery": "token://foobar"
+}`
+
+func writeTestConfig(t *testing.T, store Store, config string) string {
+	hostPath := filepath.Join(store.GetPath(), "machines", hostTestName)
+	if err := os.MkdirAll(hostPath, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(hostPath, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return hostPath
+}
+
+func TestLoadConfigMigratesWithBackup(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostPath := writeTestConfig(t, store, migrateTestConfigV0)
+
+	host, err := store.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host.ConfigVersion != version.ConfigVersion {
+		t.Fatalf("expected config version %d; received %d", version.ConfigVersion, host.ConfigVersion)
+	}
+	if host.HostOptions.SwarmOptions.Discovery != "token://foobar" {
+		t.Fatalf("expected the swarm options to be migrated; received %+v", host.HostOptions.SwarmOptions)
+	}
+
+	backup, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json.v0.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != migrateTestConfigV0 {
+		t.Fatalf("expected the original config to be backed up; received %s", backup)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := getConfigVersion(data); err != nil || v != version.ConfigVersion {
+		t.Fatalf("expected the migrated config to be saved; received version %d: %v", v, err)
+	}
+}
+
+func TestLoadConfigNewerVersion(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := fmt.Sprintf(`{"ConfigVersion": %d, "DriverName": "none"}`, version.ConfigVersion+1)
+	hostPath := writeTestConfig(t, store, config)
+
+	_, err = store.Get(hostTestName)
+	if _, ok := err.(ErrConfigVersionTooNew); !ok {
+		t.Fatalf("expected ErrConfigVersionTooNew; received %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != config {
+		t.Fatal("expected the config to be left untouched")
+	}
+}
+
+func TestDriverMigration(t *testing.T) {
+	RegisterDriverMigration("none", 0, func(data []byte) ([]byte, error) {
+		var driver map[string]interface{}
+		if err := json.Unmarshal(data, &driver); err != nil {
+			return nil, err
+		}
+		driver["URL"] = "tcp://5.6.7.8:2376"
+		return json.Marshal(driver)
+	})
+	defer delete(driverMigrations, "none")
+
+	host, err := MigrateHost(&Host{Name: hostTestName}, []byte(migrateTestConfigV0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := host.Driver.GetURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "tcp://5.6.7.8:2376" {
+		t.Fatalf("expected the driver hook to migrate the URL; received %s", url)
+	}
+}
+
+func TestPlanMigrations(t *testing.T) {
+	defer cleanup()
+
+	s, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := s.(*Filestore)
+
+	hostPath := writeTestConfig(t, store, migrateTestConfigV0)
+
+	plan, err := store.PlanMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 {
+		t.Fatalf("expected a migration; received %+v", plan)
+	}
+
+	m := plan[0]
+	if m.Err != nil {
+		t.Fatal(m.Err)
+	}
+	if m.Name != hostTestName || m.FromVersion != 0 || m.ToVersion != version.ConfigVersion {
+		t.Fatalf("unexpected migration %+v", m)
+	}
+	if !strings.Contains(string(m.Migrated), "token://foobar") || !strings.Contains(string(m.Migrated), "HostOptions") {
+		t.Fatalf("expected the migrated config to have nested options; received %s", m.Migrated)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != migrateTestConfigV0 {
+		t.Fatal("expected the config to be left untouched")
+	}
+	if _, err := os.Stat(filepath.Join(hostPath, "config.json.v0.bak")); !os.IsNotExist(err) {
+		t.Fatal("expected no backup without a migration")
+	}
+}
@@ -1,12 +1,12 @@
 package libmachine
 
 import (
+	"encoding/json"
 	"path/filepath"
 
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/swarm"
-	"github.com/docker/machine/utils"
 )
 
 // In the 0.0.1 => 0.0.2 transition, the JSON representation of
@@ -16,6 +16,25 @@ import (
 // have been introduced.  They preserve backwards compat at the expense
 // of some duplicated information.
 
+func init() {
+	RegisterMigration(0, "nest the engine, swarm and auth options", migrateConfigV0ToV1)
+}
+
+func migrateConfigV0ToV1(data []byte) ([]byte, error) {
+	// The driver is left as it is, so it is not decoded.
+	var config struct {
+		HostV0
+		Driver json.RawMessage
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	host := MigrateHostV0ToHostV1(&config.HostV0)
+
+	return setConfigField(data, "HostOptions", host.HostOptions)
+}
+
 // validates host config and modifies if needed
 // this is used for configuration updates
 func MigrateHostV0ToHostV1(hostV0 *HostV0) *Host {
@@ -78,28 +97,35 @@ func getCertInfoFromHost(h *HostV0) CertPathInfo {
 	serverCertPath := h.ServerCertPath
 	serverKeyPath := h.ServerKeyPath
 
-	if caCertPath == "" {
-		caCertPath = filepath.Join(utils.GetMachineCertDir(), "ca.pem")
-	}
+	// the certificates of version 0 hosts live in the store the host
+	// belongs to, next to its machines directory; without a store path
+	// the paths which are not set can not be found, and are left empty
+	if h.StorePath != "" {
+		certDir := filepath.Join(filepath.Dir(filepath.Dir(h.StorePath)), "certs")
 
-	if caKeyPath == "" {
-		caKeyPath = filepath.Join(utils.GetMachineCertDir(), "ca-key.pem")
-	}
+		if caCertPath == "" {
+			caCertPath = filepath.Join(certDir, "ca.pem")
+		}
 
-	if clientCertPath == "" {
-		clientCertPath = filepath.Join(utils.GetMachineCertDir(), "cert.pem")
-	}
+		if caKeyPath == "" {
+			caKeyPath = filepath.Join(certDir, "ca-key.pem")
+		}
 
-	if clientKeyPath == "" {
-		clientKeyPath = filepath.Join(utils.GetMachineCertDir(), "key.pem")
-	}
+		if clientCertPath == "" {
+			clientCertPath = filepath.Join(certDir, "cert.pem")
+		}
 
-	if serverCertPath == "" {
-		serverCertPath = filepath.Join(utils.GetMachineCertDir(), "server.pem")
-	}
+		if clientKeyPath == "" {
+			clientKeyPath = filepath.Join(certDir, "key.pem")
+		}
+
+		if serverCertPath == "" {
+			serverCertPath = filepath.Join(certDir, "server.pem")
+		}
 
-	if serverKeyPath == "" {
-		serverKeyPath = filepath.Join(utils.GetMachineCertDir(), "server-key.pem")
+		if serverKeyPath == "" {
+			serverKeyPath = filepath.Join(certDir, "server-key.pem")
+		}
 	}
 
 	return CertPathInfo{
@@ -11,9 +11,9 @@ import (
 )
 
 func TestMigrateHostV0ToV1(t *testing.T) {
-	os.Setenv("MACHINE_STORAGE_PATH", "/tmp/migration")
 	originalHost := &HostV0{
 		HostOptions:    nil,
+		StorePath:      "/tmp/migration/machines/foo",
 		SwarmDiscovery: "token://foobar",
 		SwarmHost:      "1.2.3.4:2376",
 		SwarmMaster:    true,
@@ -31,6 +31,7 @@ func TestMigrateHostV0ToV1(t *testing.T) {
 			Host:      "1.2.3.4:2376",
 		},
 		AuthOptions: &auth.AuthOptions{
+			StorePath:      "/tmp/migration/machines/foo",
 			CaCertPath:     "/tmp/migration/certs/ca.pem",
 			PrivateKeyPath: "/tmp/migration/certs/ca-key.pem",
 			ClientCertPath: "/tmp/migration/certs/cert.pem",
@@ -88,8 +89,8 @@ func TestMigrateHostMetadataV0ToV1(t *testing.T) {
 // Tests a function which "prefills" certificate information for a host
 // due to a schema migration from "flat" to a "nested" structure.
 func TestGetCertInfoFromHost(t *testing.T) {
-	os.Setenv("MACHINE_STORAGE_PATH", "/tmp/migration")
 	host := &HostV0{
+		StorePath:      "/tmp/migration/machines/foo",
 		CaCertPath:     "",
 		PrivateKeyPath: "",
 		ClientCertPath: "",
@@ -111,3 +112,19 @@ func TestGetCertInfoFromHost(t *testing.T) {
 		t.Fatal("Expected these structs to be equal, they were different")
 	}
 }
+
+func TestGetCertInfoFromHostStorePath(t *testing.T) {
+	os.Setenv("MACHINE_STORAGE_PATH", "/tmp/migration")
+	host := &HostV0{
+		StorePath: "/tmp/other-store/machines/foo",
+	}
+	certInfo := getCertInfoFromHost(host)
+	if certInfo.CaCertPath != "/tmp/other-store/certs/ca.pem" {
+		t.Fatalf("expected ca cert path in the store of the host; received %s", certInfo.CaCertPath)
+	}
+
+	certInfo = getCertInfoFromHost(&HostV0{})
+	if certInfo.CaCertPath != "" {
+		t.Fatalf("expected no ca cert path without the store of the host; received %s", certInfo.CaCertPath)
+	}
+}
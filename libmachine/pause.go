@@ -0,0 +1,89 @@
+package libmachine
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/state"
+	"golang.org/x/net/context"
+)
+
+// resumer is implemented by drivers.Pauser and drivers.Suspender.
+type resumer interface {
+	Resume() error
+}
+
+func (h *Host) Pause() error {
+	return h.PauseContext(context.Background())
+}
+
+// PauseContext pauses the machine, keeping its memory.  Machines whose
+// driver can suspend them, but not pause them, are suspended.
+func (h *Host) PauseContext(ctx context.Context) error {
+	if !drivers.HasCapability(h.Driver, drivers.CapabilityPause) {
+		if drivers.HasCapability(h.Driver, drivers.CapabilitySuspend) {
+			return h.SuspendContext(ctx)
+		}
+		return h.recordEvent("pause", ErrNotSupported{Name: h.Name, DriverName: h.DriverName, Operation: "pausing"})
+	}
+	pauser := h.Driver.(drivers.Pauser)
+	return h.recordEvent("pause", h.runActionForState(ctx, withoutContext(pauser.Pause), state.Paused))
+}
+
+func (h *Host) Suspend() error {
+	return h.SuspendContext(context.Background())
+}
+
+// SuspendContext suspends the machine, saving its memory to disk.
+func (h *Host) SuspendContext(ctx context.Context) error {
+	if !drivers.HasCapability(h.Driver, drivers.CapabilitySuspend) {
+		return h.recordEvent("suspend", ErrNotSupported{Name: h.Name, DriverName: h.DriverName, Operation: "suspending"})
+	}
+	suspender := h.Driver.(drivers.Suspender)
+	return h.recordEvent("suspend", h.runActionForState(ctx, withoutContext(suspender.Suspend), state.Saved))
+}
+
+func (h *Host) Resume() error {
+	return h.ResumeContext(context.Background())
+}
+
+// ResumeContext resumes a paused or suspended machine.
+func (h *Host) ResumeContext(ctx context.Context) error {
+	return h.recordEvent("resume", h.resume(ctx))
+}
+
+func (h *Host) resume(ctx context.Context) error {
+	machineState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+
+	switch machineState {
+	case state.Running:
+		return nil
+	case state.Paused, state.Saved:
+	default:
+		return fmt.Errorf("Machine %s is not paused or suspended; it is %s", h.Name, machineState)
+	}
+
+	if !drivers.HasCapability(h.Driver, drivers.CapabilityPause) && !drivers.HasCapability(h.Driver, drivers.CapabilitySuspend) {
+		return ErrNotSupported{Name: h.Name, DriverName: h.DriverName, Operation: "resuming"}
+	}
+	resumer := h.Driver.(resumer)
+
+	return h.runActionForState(ctx, withoutContext(resumer.Resume), state.Running)
+}
+
+// withoutContext adapts a driver operation which can not be cancelled to
+// runActionForState, like the operations of drivers.NewContextDriver.
+func withoutContext(action func() error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := action(); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+}
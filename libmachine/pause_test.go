@@ -0,0 +1,89 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/state"
+)
+
+type pauseTestDriver struct {
+	*fakedriver.FakeDriver
+}
+
+func (d *pauseTestDriver) Pause() error {
+	d.MockState = state.Paused
+	return nil
+}
+
+func (d *pauseTestDriver) Suspend() error {
+	d.MockState = state.Saved
+	return nil
+}
+
+func (d *pauseTestDriver) Resume() error {
+	d.MockState = state.Running
+	return nil
+}
+
+func TestHostPauseResume(t *testing.T) {
+	defer cleanup()
+
+	driver := &pauseTestDriver{FakeDriver: &fakedriver.FakeDriver{MockState: state.Running}}
+	host := getFakeTestHost(t, driver)
+
+	if err := host.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.MockState != state.Paused {
+		t.Fatalf("expected the machine to be paused; it is %s", driver.MockState)
+	}
+
+	if err := host.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.MockState != state.Running {
+		t.Fatalf("expected the machine to be running; it is %s", driver.MockState)
+	}
+
+	if err := host.Suspend(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.MockState != state.Saved {
+		t.Fatalf("expected the machine to be saved; it is %s", driver.MockState)
+	}
+}
+
+func TestHostStartResumes(t *testing.T) {
+	defer cleanup()
+
+	driver := &pauseTestDriver{FakeDriver: &fakedriver.FakeDriver{MockState: state.Saved}}
+	host := getFakeTestHost(t, driver)
+
+	if err := host.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if driver.MockState != state.Running {
+		t.Fatalf("expected the machine to be resumed; it is %s", driver.MockState)
+	}
+}
+
+func TestHostResumeStopped(t *testing.T) {
+	defer cleanup()
+
+	host := getFakeTestHost(t, &pauseTestDriver{FakeDriver: &fakedriver.FakeDriver{MockState: state.Stopped}})
+
+	if err := host.Resume(); err == nil {
+		t.Fatal("expected an error resuming a stopped machine")
+	}
+}
+
+func TestHostPauseNotSupported(t *testing.T) {
+	defer cleanup()
+
+	host := getFakeTestHost(t, &fakedriver.FakeDriver{MockState: state.Running})
+
+	if _, ok := host.Pause().(ErrNotSupported); !ok {
+		t.Fatal("expected ErrNotSupported pausing with a driver which can not pause")
+	}
+}
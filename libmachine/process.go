@@ -0,0 +1,11 @@
+// +build !windows
+
+package libmachine
+
+import "syscall"
+
+// processExists returns whether a process with the given pid is running.
+func processExists(pid int) bool {
+	err := syscall.Kill(pid, syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
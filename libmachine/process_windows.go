@@ -0,0 +1,14 @@
+package libmachine
+
+import "os"
+
+// processExists returns whether a process with the given pid is running.
+// On Windows, finding a process fails if it does not exist.
+func processExists(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -2,15 +2,34 @@ package libmachine
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
 	"github.com/docker/machine/utils"
+	"golang.org/x/net/context"
 )
 
+// secretKeyStore is implemented by the stores which encrypt the
+// credentials of the drivers.
+type secretKeyStore interface {
+	getSecretKey() *SecretKey
+}
+
+// machineNameSetter is implemented by the drivers which embed
+// drivers.BaseDriver, and lets a machine be renamed.
+type machineNameSetter interface {
+	SetMachineName(name string)
+}
+
 type Provider struct {
-	store Store
+	store         Store
+	keepOnFailure bool
+	progressSink  progress.Sink
 }
 
 func New(store Store) (*Provider, error) {
@@ -19,7 +38,36 @@ func New(store Store) (*Provider, error) {
 	}, nil
 }
 
+// rollbackTimeout bounds the removal of a machine whose creation failed,
+// which can not use the context of the creation as it may be done.
+const rollbackTimeout = 5 * time.Minute
+
+// Store returns the store the machines of the provider are kept in.
+func (provider *Provider) Store() Store {
+	return provider.store
+}
+
+// SetKeepOnFailure makes Create keep the machines it fails to create, for
+// debugging, instead of removing them from the provider and the store.
+func (provider *Provider) SetKeepOnFailure(keep bool) {
+	provider.keepOnFailure = keep
+}
+
+// SetProgressSink sets the sink which receives the progress of the
+// operations on the machines of the provider.  Without a sink, the progress
+// is logged.
+func (provider *Provider) SetProgressSink(sink progress.Sink) {
+	provider.progressSink = sink
+}
+
 func (provider *Provider) Create(name string, driverName string, hostOptions *HostOptions, driverConfig drivers.DriverOptions) (*Host, error) {
+	return provider.CreateContext(context.Background(), name, driverName, hostOptions, driverConfig)
+}
+
+// CreateContext creates a machine.  If the creation fails, including when
+// ctx is done before it completes, the machine is removed from the
+// provider and the store again, and an ErrCreateFailed is returned.
+func (provider *Provider) CreateContext(ctx context.Context, name string, driverName string, hostOptions *HostOptions, driverConfig drivers.DriverOptions) (*Host, error) {
 	validName := ValidateHostName(name)
 	if !validName {
 		return nil, ErrInvalidHostname
@@ -32,32 +80,61 @@ func (provider *Provider) Create(name string, driverName string, hostOptions *Ho
 		return nil, fmt.Errorf("Machine %s already exists", name)
 	}
 
-	hostPath := filepath.Join(utils.GetMachineDir(), name)
+	hostPath := filepath.Join(provider.store.GetPath(), "machines", name)
+
+	authOptions := hostOptions.AuthOptions
+	authOptions.StorePath = hostPath
+	if authOptions.ServerCertPath == "" {
+		authOptions.ServerCertPath = filepath.Join(hostPath, "server.pem")
+	}
+	if authOptions.ServerKeyPath == "" {
+		authOptions.ServerKeyPath = filepath.Join(hostPath, "server-key.pem")
+	}
 
 	host, err := NewHost(name, driverName, hostOptions)
 	if err != nil {
 		return host, err
 	}
+	if store, ok := provider.store.(secretKeyStore); ok {
+		host.secretKey = store.getSecretKey()
+	}
+	host.journal = newStoreEventJournal(provider.store)
+	provider.setProgressSink(host)
 	if driverConfig != nil {
 		if err := host.Driver.SetConfigFromFlags(driverConfig); err != nil {
 			return host, err
 		}
+		registerSecrets(host.Driver)
 	}
 
 	if err := host.Driver.PreCreateCheck(); err != nil {
+		host.Close()
 		return nil, err
 	}
 
 	if err := os.MkdirAll(hostPath, 0700); err != nil {
+		host.Close()
 		return nil, err
 	}
 
+	lock, err := LockHost(hostPath, "create")
+	if err != nil {
+		host.Close()
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	host.CreateState = &CreateState{}
 	if err := host.SaveConfig(); err != nil {
-		return host, err
+		return host, provider.rollbackCreate(host, err, false)
 	}
 
-	if err := host.Create(name); err != nil {
-		return host, err
+	if err := host.CreateContext(ctx, name); err != nil {
+		return host, provider.rollbackCreate(host, err, true)
+	}
+
+	if err := provider.store.Save(host); err != nil {
+		return host, provider.rollbackCreate(host, err, true)
 	}
 
 	return host, nil
@@ -68,26 +145,377 @@ func (provider *Provider) Exists(name string) (bool, error) {
 }
 
 func (provider *Provider) GetActive() (*Host, error) {
-	return provider.store.GetActive()
+	host, err := provider.store.GetActive()
+	if err != nil {
+		return host, err
+	}
+	provider.setProgressSink(host)
+	return host, nil
 }
 
 func (provider *Provider) List() ([]*Host, error) {
-	return provider.store.List()
+	hosts, err := provider.store.List()
+	if err != nil {
+		return hosts, err
+	}
+	provider.setProgressSink(hosts...)
+	return hosts, nil
 }
 
 func (provider *Provider) Get(name string) (*Host, error) {
-	return provider.store.Get(name)
+	host, err := provider.store.Get(name)
+	if err != nil {
+		return host, err
+	}
+	provider.setProgressSink(host)
+	return host, nil
+}
+
+// setProgressSink makes the drivers of the hosts publish their progress to
+// the sink of the provider.
+func (provider *Provider) setProgressSink(hosts ...*Host) {
+	if provider.progressSink == nil {
+		return
+	}
+	for _, host := range hosts {
+		if host == nil {
+			continue
+		}
+		if p, ok := host.Driver.(drivers.ProgressPublisher); ok {
+			p.SetProgressSink(provider.progressSink)
+		}
+	}
+}
+
+func (provider *Provider) Save(host *Host) error {
+	return provider.store.Save(host)
+}
+
+func (provider *Provider) ResumeCreate(name string) (*Host, error) {
+	return provider.ResumeCreateContext(context.Background(), name)
+}
+
+// ResumeCreateContext continues the creation of the machine "name" from
+// the first phase which is not done, e.g. after the creation failed on a
+// provider which keeps failed machines, or the process creating it was
+// killed.  If it fails again, the machine is kept so that its creation
+// can be resumed once more.
+func (provider *Provider) ResumeCreateContext(ctx context.Context, name string) (*Host, error) {
+	host, lock, err := provider.getLockedHost(name, "create")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	if host.CreateState == nil {
+		return host, fmt.Errorf("Machine %s is created already, there is nothing to resume", name)
+	}
+
+	if err := host.CreateContext(ctx, name); err != nil {
+		return host, err
+	}
+
+	if err := provider.store.Save(host); err != nil {
+		return host, err
+	}
+
+	return host, nil
 }
 
 func (provider *Provider) Remove(name string, force bool) error {
-	host, err := provider.store.Get(name)
+	return provider.RemoveContext(context.Background(), name, force)
+}
+
+func (provider *Provider) RemoveContext(ctx context.Context, name string, force bool) error {
+	host, lock, err := provider.getLockedHost(name, "rm")
 	if err != nil {
 		return err
 	}
-	if err := host.Remove(force); err != nil {
+	defer lock.Unlock()
+	defer host.Close()
+
+	if err := host.RemoveContext(ctx, force); err != nil {
 		if !force {
 			return err
 		}
 	}
 	return provider.store.Remove(name, force)
 }
+
+// rollbackCreate removes a machine whose creation failed with err, unless
+// the provider keeps such machines.  The resources at the provider are
+// only removed if the driver was asked to create them.
+func (provider *Provider) rollbackCreate(host *Host, err error, removeDriver bool) error {
+	createErr := ErrCreateFailed{
+		Name: host.Name,
+		Err:  err,
+	}
+
+	if provider.keepOnFailure {
+		createErr.Kept = true
+		return createErr
+	}
+
+	reporter := drivers.GetProgress(host.Driver)
+	reporter.Infof("Rolling back the creation of %s...", host.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	if removeDriver {
+		if err := drivers.NewContextDriver(host.Driver).RemoveContext(ctx); err != nil {
+			createErr.RollbackErr = fmt.Errorf("Error removing the machine from %s: %s", host.DriverName, err)
+			log.Error(createErr.RollbackErr)
+		} else {
+			createErr.Cleaned = append(createErr.Cleaned, fmt.Sprintf("the machine at %s", host.DriverName))
+		}
+	}
+
+	// The machine directory is left behind if the provider could not
+	// be cleaned up, as it holds the details needed to do it by hand.
+	if createErr.RollbackErr == nil {
+		if err := os.RemoveAll(host.StorePath); err != nil {
+			createErr.RollbackErr = fmt.Errorf("Error removing the machine directory %s: %s", host.StorePath, err)
+			log.Error(createErr.RollbackErr)
+		} else {
+			createErr.Cleaned = append(createErr.Cleaned, fmt.Sprintf("the machine directory %s", host.StorePath))
+		}
+
+		// stores which keep the machine elsewhere as well
+		if err := provider.store.Remove(host.Name, true); err != nil {
+			log.Debugf("Error removing machine %s from the store: %s", host.Name, err)
+		}
+	}
+
+	for _, cleaned := range createErr.Cleaned {
+		reporter.Infof("Removed %s", cleaned)
+	}
+
+	host.recordEvent("rollback", createErr.RollbackErr)
+
+	return createErr
+}
+
+// getLockedHost locks the machine "name" on behalf of "command", and loads
+// it once the lock is held, so that it has the changes made by whoever
+// held the lock before.
+func (provider *Provider) getLockedHost(name, command string) (*Host, *HostLock, error) {
+	host, err := provider.Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	storePath := host.StorePath
+	host.Close()
+
+	lock, err := LockHost(storePath, command)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	host, err = provider.Get(name)
+	if err != nil {
+		lock.Unlock()
+		return nil, nil, err
+	}
+
+	return host, lock, nil
+}
+
+// withLockedHost runs fn on the machine "name" while it is locked by
+// "command", and saves the machine afterwards.
+func (provider *Provider) withLockedHost(name, command string, fn func(*Host) error) error {
+	host, lock, err := provider.getLockedHost(name, command)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	defer host.Close()
+
+	if err := fn(host); err != nil {
+		return err
+	}
+
+	return provider.store.Save(host)
+}
+
+// UpdateLabels sets the labels in "set" on the machine "name", and removes
+// the labels with the keys in "remove".
+func (provider *Provider) UpdateLabels(name string, set map[string]string, remove []string) error {
+	host, lock, err := provider.getLockedHost(name, "label")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	defer host.Close()
+
+	if host.HostOptions == nil {
+		host.HostOptions = &HostOptions{}
+	}
+
+	labels := host.HostOptions.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for key, value := range set {
+		labels[key] = value
+	}
+	for _, key := range remove {
+		delete(labels, key)
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+	host.HostOptions.Labels = labels
+
+	return provider.store.Save(host)
+}
+
+// Rename renames the machine "oldName" to "newName".  The machine directory
+// is moved and the paths in its config rewritten.  Drivers which implement
+// drivers.Renamer also rename the machine at the provider.  If the machine
+// is running, its hostname is changed and its server certificate, which is
+// issued to the machine name, regenerated.
+func (provider *Provider) Rename(oldName, newName string) error {
+	renamed, err := provider.rename(oldName, newName)
+	if renamed != nil {
+		renamed.Close()
+	}
+	return err
+}
+
+// rename renames the machine, and returns it under its new name once it
+// has been moved, even if it could not be renamed at the provider.
+func (provider *Provider) rename(oldName, newName string) (*Host, error) {
+	if !ValidateHostName(newName) {
+		return nil, ErrInvalidHostname
+	}
+	exists, err := provider.store.Exists(newName)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("Machine %s already exists", newName)
+	}
+
+	host, lock, err := provider.getLockedHost(oldName, "rename")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+	// The machine is loaded again under its new name.
+	defer host.Close()
+
+	if _, ok := host.Driver.(machineNameSetter); !ok {
+		return nil, fmt.Errorf("Driver %s does not support renaming machines", host.DriverName)
+	}
+
+	oldPath := host.StorePath
+	newPath := filepath.Join(provider.store.GetPath(), "machines", newName)
+	if _, err := os.Stat(newPath); err == nil {
+		return nil, fmt.Errorf("Machine directory %s already exists", newPath)
+	}
+
+	var renamer drivers.Renamer
+	hasRenamer := drivers.HasCapability(host.Driver, drivers.CapabilityRename)
+	if hasRenamer {
+		renamer = host.Driver.(drivers.Renamer)
+		if err := renamer.PrepareRename(newName); err != nil {
+			return nil, fmt.Errorf("Error renaming machine at the provider: %s", err)
+		}
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		if hasRenamer {
+			// finish the rename at the provider under the old name
+			if err := renamer.Rename(oldName); err != nil {
+				log.Errorf("Error restoring machine %s at the provider: %s", oldName, err)
+			}
+		}
+		return nil, err
+	}
+	lock.path = filepath.Join(newPath, hostLockFile)
+
+	// undo moves the machine back under its old name, at the provider
+	// too, once it could not be renamed after its directory was moved.
+	undo := func(err error) error {
+		if moveErr := os.Rename(newPath, oldPath); moveErr != nil {
+			log.Errorf("Error moving machine %s back to %s: %s", oldName, oldPath, moveErr)
+			return err
+		}
+		lock.path = filepath.Join(oldPath, hostLockFile)
+
+		if hasRenamer {
+			if renameErr := renamer.Rename(oldName); renameErr != nil {
+				log.Errorf("Error restoring machine %s at the provider: %s", oldName, renameErr)
+			}
+		}
+		if saveErr := provider.store.Save(host); saveErr != nil {
+			log.Errorf("Error restoring the config of machine %s: %s", oldName, saveErr)
+		}
+		if removeErr := provider.store.Remove(newName, false); removeErr != nil {
+			log.Errorf("Error removing machine %s: %s", newName, removeErr)
+		}
+		return err
+	}
+
+	renamed, err := moveHostConfig(host, newName, oldPath, newPath)
+	if err != nil {
+		return nil, undo(err)
+	}
+
+	if err := provider.store.Save(renamed); err != nil {
+		renamed.Close()
+		return nil, undo(err)
+	}
+
+	// The directory has been moved already; this removes the machine from
+	// stores which keep it somewhere else too.
+	if err := provider.store.Remove(oldName, false); err != nil {
+		renamed.Close()
+		return nil, undo(err)
+	}
+
+	if hasRenamer {
+		if err := renamed.Driver.(drivers.Renamer).Rename(oldName); err != nil {
+			return renamed, fmt.Errorf("Machine renamed to %s, but not at the provider: %s", newName, err)
+		}
+		if err := provider.store.Save(renamed); err != nil {
+			return renamed, err
+		}
+	}
+
+	if err := renamed.configureName(); err != nil {
+		return renamed, err
+	}
+
+	return renamed, nil
+}
+
+// moveHostConfig rewrites the config of the host after its directory was
+// moved from oldPath to newPath, and loads it as newName.
+func moveHostConfig(host *Host, newName, oldPath, newPath string) (*Host, error) {
+	configPath := filepath.Join(newPath, "config.json")
+
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	data = replaceStorePath(data, oldPath, newPath)
+	if err := utils.WriteFileAtomic(configPath, data, 0600); err != nil {
+		return nil, err
+	}
+
+	renamed := &Host{
+		Name:      newName,
+		StorePath: newPath,
+		secretKey: host.secretKey,
+		journal:   host.journal,
+	}
+	if err := renamed.LoadConfig(); err != nil {
+		return nil, err
+	}
+
+	renamed.Driver.(machineNameSetter).SetMachineName(newName)
+
+	return renamed, nil
+}
@@ -1 +1,399 @@
 package libmachine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/progress"
+	"golang.org/x/net/context"
+)
+
+func getTestProviderHost(t *testing.T, provider *Provider, name string) *Host {
+	hostOptions := &HostOptions{
+		EngineOptions: &engine.EngineOptions{},
+		SwarmOptions:  &swarm.SwarmOptions{},
+		AuthOptions: &auth.AuthOptions{
+			CaCertPath:     hostTestCaCert,
+			PrivateKeyPath: hostTestPrivateKey,
+		},
+	}
+
+	host, err := provider.Create(name, hostTestDriverName, hostOptions, getTestDriverFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host
+}
+
+func TestProviderRename(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := getTestProviderHost(t, provider, "old")
+
+	renamed, err := provider.rename("old", "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newPath := filepath.Join(store.GetPath(), "machines", "new")
+	if renamed.Name != "new" || renamed.StorePath != newPath {
+		t.Fatalf("expected machine new in %s; received %s in %s", newPath, renamed.Name, renamed.StorePath)
+	}
+
+	if _, err := os.Stat(host.StorePath); !os.IsNotExist(err) {
+		t.Fatal("expected the old machine directory to be moved")
+	}
+
+	loaded, err := store.Get("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := loaded.Driver.(*none.Driver).MachineName; name != "new" {
+		t.Fatalf("expected the driver to be renamed; received %s", name)
+	}
+
+	authOptions := loaded.HostOptions.AuthOptions
+	if authOptions.ServerCertPath != filepath.Join(newPath, "server.pem") {
+		t.Fatalf("expected the server cert in the new machine directory; received %s", authOptions.ServerCertPath)
+	}
+
+	if _, err := os.Stat(filepath.Join(newPath, hostLockFile)); !os.IsNotExist(err) {
+		t.Fatal("expected the lock to be released")
+	}
+
+	exists, err := store.Exists("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("expected the old machine not to exist")
+	}
+}
+
+// renameTestStore fails to remove machines, which is the last step of
+// renaming them.
+type renameTestStore struct {
+	Store
+}
+
+func (s renameTestStore) Remove(name string, force bool) error {
+	return errors.New("remove failed")
+}
+
+func TestProviderRenameUndo(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(renameTestStore{store})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := getTestProviderHost(t, provider, "old")
+
+	if _, err := provider.rename("old", "new"); err == nil {
+		t.Fatal("expected an error renaming the machine")
+	}
+
+	newPath := filepath.Join(store.GetPath(), "machines", "new")
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		t.Fatal("expected the machine directory to be moved back")
+	}
+
+	loaded, err := store.Get("old")
+	if err != nil {
+		t.Fatalf("expected the machine to be kept under its old name: %s", err)
+	}
+
+	if name := loaded.Driver.(*none.Driver).MachineName; name != "old" {
+		t.Fatalf("expected the driver to keep its name; received %s", name)
+	}
+
+	authOptions := loaded.HostOptions.AuthOptions
+	if authOptions.ServerCertPath != filepath.Join(host.StorePath, "server.pem") {
+		t.Fatalf("expected the server cert in the old machine directory; received %s", authOptions.ServerCertPath)
+	}
+
+	if _, err := os.Stat(filepath.Join(host.StorePath, hostLockFile)); !os.IsNotExist(err) {
+		t.Fatal("expected the lock to be released")
+	}
+}
+
+func TestProviderRenameExisting(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getTestProviderHost(t, provider, "a")
+	getTestProviderHost(t, provider, "b")
+
+	if err := provider.Rename("a", "b"); err == nil {
+		t.Fatal("expected an error renaming to an existing machine")
+	}
+
+	if err := provider.Rename("a", "in valid"); err != ErrInvalidHostname {
+		t.Fatalf("expected ErrInvalidHostname; received %v", err)
+	}
+
+	if _, err := store.Get("a"); err != nil {
+		t.Fatalf("expected the machine to be left alone: %s", err)
+	}
+}
+
+func TestProviderCreateCancelled(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hostOptions := &HostOptions{
+		EngineOptions: &engine.EngineOptions{},
+		SwarmOptions:  &swarm.SwarmOptions{},
+		AuthOptions:   &auth.AuthOptions{},
+	}
+
+	_, err = provider.CreateContext(ctx, hostTestName, hostTestDriverName, hostOptions, getTestDriverFlags())
+	createErr, ok := err.(ErrCreateFailed)
+	if !ok || createErr.Err != context.Canceled {
+		t.Fatalf("expected context.Canceled; received %v", err)
+	}
+	if createErr.Kept || createErr.RollbackErr != nil || len(createErr.Cleaned) == 0 {
+		t.Fatalf("expected the machine to be rolled back; received %+v", createErr)
+	}
+
+	if _, err := os.Stat(filepath.Join(store.GetPath(), "machines", hostTestName)); !os.IsNotExist(err) {
+		t.Fatal("expected the cancelled machine to be removed from the store")
+	}
+}
+
+func TestProviderCreateKeepOnFailure(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.SetKeepOnFailure(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	hostOptions := &HostOptions{
+		EngineOptions: &engine.EngineOptions{},
+		SwarmOptions:  &swarm.SwarmOptions{},
+		AuthOptions:   &auth.AuthOptions{},
+	}
+
+	_, err = provider.CreateContext(ctx, hostTestName, hostTestDriverName, hostOptions, getTestDriverFlags())
+	createErr, ok := err.(ErrCreateFailed)
+	if !ok || !createErr.Kept {
+		t.Fatalf("expected the machine to be kept; received %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(store.GetPath(), "machines", hostTestName)); err != nil {
+		t.Fatalf("expected the machine to be kept in the store: %s", err)
+	}
+}
+
+func TestProviderResumeCreate(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := getTestProviderHost(t, provider, hostTestName)
+	if host.CreateState != nil {
+		t.Fatalf("expected a created machine to have no create state; received %+v", host.CreateState)
+	}
+
+	host.CreateState = &CreateState{
+		Phases: []string{CreatePhaseInstance},
+	}
+	if err := store.Save(host); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := provider.ResumeCreate(host.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.CreateState != nil {
+		t.Fatalf("expected the creation to be complete; received %+v", resumed.CreateState)
+	}
+
+	loaded, err := store.Get(host.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CreateState != nil {
+		t.Fatal("expected the complete creation to be saved")
+	}
+
+	if _, err := provider.ResumeCreate(host.Name); err == nil {
+		t.Fatal("expected an error resuming the creation of a machine which is created")
+	}
+}
+
+func TestProviderUpdateLabels(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getTestProviderHost(t, provider, hostTestName)
+
+	if err := provider.UpdateLabels(hostTestName, map[string]string{"team": "web", "env": "dev"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.UpdateLabels(hostTestName, nil, []string{"env"}); err != nil {
+		t.Fatal(err)
+	}
+
+	host, err := store.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labels := host.HostOptions.Labels; len(labels) != 1 || labels["team"] != "web" {
+		t.Fatalf("expected the label team=web; received %v", labels)
+	}
+}
+
+func TestProviderGetLockedHost(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getTestProviderHost(t, provider, hostTestName)
+
+	host, lock, err := provider.getLockedHost(hostTestName, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+
+	if err := provider.UpdateLabels(hostTestName, map[string]string{"env": "dev"}, nil); err == nil {
+		t.Fatal("expected an error changing a locked machine")
+	} else if _, ok := err.(ErrHostLocked); !ok {
+		t.Fatalf("expected ErrHostLocked; received %v", err)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := provider.UpdateLabels(hostTestName, map[string]string{"env": "dev"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := provider.getLockedHost("missing", "test"); err == nil {
+		t.Fatal("expected an error locking a missing machine")
+	}
+}
+
+func TestProviderProgressSink(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []progress.Event{}
+	provider.SetProgressSink(progress.SinkFunc(func(e progress.Event) {
+		events = append(events, e)
+	}))
+
+	getTestProviderHost(t, provider, hostTestName)
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events; received %d", len(events))
+	}
+	started, finished := events[0], events[1]
+	if started.Type != progress.PhaseStarted || started.Phase != CreatePhaseInstance || started.Machine != hostTestName {
+		t.Fatalf("unexpected start event: %+v", started)
+	}
+	if finished.Type != progress.PhaseFinished || finished.Phase != CreatePhaseInstance || finished.Error != "" {
+		t.Fatalf("unexpected finish event: %+v", finished)
+	}
+
+	host, err := provider.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drivers.GetProgress(host.Driver).Info("loaded")
+	if len(events) != 3 || events[2].Message != "loaded" {
+		t.Fatal("expected the loaded machine to publish to the sink")
+	}
+}
@@ -34,6 +34,16 @@ type Boot2DockerProvisioner struct {
 	AuthOptions   auth.AuthOptions
 	EngineOptions engine.EngineOptions
 	SwarmOptions  swarm.SwarmOptions
+
+	checkpoints Checkpoints
+}
+
+func (provisioner *Boot2DockerProvisioner) SetCheckpoints(checkpoints Checkpoints) {
+	provisioner.checkpoints = checkpoints
+}
+
+func (provisioner *Boot2DockerProvisioner) SetAuthOptions(authOptions auth.AuthOptions) {
+	provisioner.AuthOptions = authOptions
 }
 
 func (provisioner *Boot2DockerProvisioner) Service(name string, action pkgaction.ServiceAction) error {
@@ -63,7 +73,8 @@ func (provisioner *Boot2DockerProvisioner) upgradeIso() error {
 
 	log.Infof("Upgrading machine %s...", machineName)
 
-	b2dutils := utils.NewB2dUtils("", "")
+	b2dutils := utils.NewB2dUtils("", "", provisioner.AuthOptions.StorePath)
+	b2dutils.Progress = drivers.GetProgress(provisioner.Driver)
 
 	// Usually we call this implicitly, but call it here explicitly to get
 	// the latest boot2docker ISO.
@@ -72,7 +83,7 @@ func (provisioner *Boot2DockerProvisioner) upgradeIso() error {
 	}
 
 	// Copy the latest version of boot2docker ISO to the machine's directory
-	if err := b2dutils.CopyIsoToMachineDir("", machineName); err != nil {
+	if err := b2dutils.CopyIsoToMachineDir(""); err != nil {
 		return err
 	}
 
@@ -185,7 +196,9 @@ func (provisioner *Boot2DockerProvisioner) Provision(swarmOptions swarm.SwarmOpt
 		provisioner.EngineOptions.StorageDriver = "aufs"
 	}
 
-	if err := provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseHostname, func() error {
+		return provisioner.SetHostname(provisioner.Driver.GetMachineName())
+	}); err != nil {
 		return err
 	}
 
@@ -206,11 +219,15 @@ func (provisioner *Boot2DockerProvisioner) Provision(swarmOptions swarm.SwarmOpt
 
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
-	if err := ConfigureAuth(provisioner); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseAuth, func() error {
+		return ConfigureAuth(provisioner)
+	}); err != nil {
 		return err
 	}
 
-	if err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseSwarm, func() error {
+		return configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions)
+	}); err != nil {
 		return err
 	}
 
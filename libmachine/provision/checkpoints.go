@@ -0,0 +1,57 @@
+package provision
+
+import (
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
+)
+
+// The phases of provisioning a machine, in the order they are done.  Not
+// every provisioner goes through all of them.
+const (
+	PhaseHostname = "hostname-set"
+	PhaseEngine   = "engine-installed"
+	PhaseAuth     = "tls-configured"
+	PhaseSwarm    = "swarm-configured"
+)
+
+// Checkpoints records the phases of provisioning which are done, so that
+// provisioning can be resumed after it was interrupted.
+type Checkpoints interface {
+	// Done returns whether the phase is done.
+	Done(phase string) bool
+
+	// Complete records the phase as done.
+	Complete(phase string) error
+}
+
+// progressCheckpoints are the Checkpoints which publish when the phases
+// start and finish.
+type progressCheckpoints interface {
+	Checkpoints
+	Progress() progress.Reporter
+}
+
+// RunPhase runs fn unless the phase is done according to checkpoints, and
+// records it as done once fn succeeds.  Without checkpoints, fn always runs.
+func RunPhase(checkpoints Checkpoints, phase string, fn func() error) error {
+	if checkpoints == nil {
+		return fn()
+	}
+
+	if checkpoints.Done(phase) {
+		log.Debugf("Skipping phase %s, which is done", phase)
+		return nil
+	}
+
+	run := func() error {
+		if err := fn(); err != nil {
+			return err
+		}
+		return checkpoints.Complete(phase)
+	}
+
+	if p, ok := checkpoints.(progressCheckpoints); ok {
+		return p.Progress().Phase(phase, run)
+	}
+	return run()
+}
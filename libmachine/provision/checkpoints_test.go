@@ -0,0 +1,60 @@
+package provision
+
+import (
+	"errors"
+	"testing"
+)
+
+type testCheckpoints map[string]bool
+
+func (c testCheckpoints) Done(phase string) bool {
+	return c[phase]
+}
+
+func (c testCheckpoints) Complete(phase string) error {
+	c[phase] = true
+	return nil
+}
+
+func TestRunPhase(t *testing.T) {
+	checkpoints := testCheckpoints{}
+
+	runs := 0
+	fn := func() error {
+		runs++
+		return nil
+	}
+
+	if err := RunPhase(checkpoints, PhaseHostname, fn); err != nil {
+		t.Fatal(err)
+	}
+	if !checkpoints[PhaseHostname] {
+		t.Fatal("expected the phase to be recorded as done")
+	}
+
+	if err := RunPhase(checkpoints, PhaseHostname, fn); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 1 {
+		t.Fatalf("expected a phase which is done to be skipped; ran %d times", runs)
+	}
+
+	if err := RunPhase(nil, PhaseHostname, fn); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 {
+		t.Fatal("expected the phase to run without checkpoints")
+	}
+}
+
+func TestRunPhaseFailure(t *testing.T) {
+	checkpoints := testCheckpoints{}
+
+	phaseErr := errors.New("phase failed")
+	if err := RunPhase(checkpoints, PhaseAuth, func() error { return phaseErr }); err != phaseErr {
+		t.Fatalf("expected %s; received %v", phaseErr, err)
+	}
+	if checkpoints[PhaseAuth] {
+		t.Fatal("expected a failed phase not to be recorded as done")
+	}
+}
@@ -137,7 +137,9 @@ func (provisioner *CoreOSProvisioner) Provision(swarmOptions swarm.SwarmOptions,
 	provisioner.AuthOptions = authOptions
 	provisioner.EngineOptions = engineOptions
 
-	if err := provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseHostname, func() error {
+		return provisioner.SetHostname(provisioner.Driver.GetMachineName())
+	}); err != nil {
 		return err
 	}
 
@@ -149,7 +151,9 @@ func (provisioner *CoreOSProvisioner) Provision(swarmOptions swarm.SwarmOptions,
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debugf("Setting up certificates")
-	if err := ConfigureAuth(provisioner); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseAuth, func() error {
+		return ConfigureAuth(provisioner)
+	}); err != nil {
 		return err
 	}
 
@@ -136,19 +136,23 @@ func (provisioner *DebianProvisioner) Provision(swarmOptions swarm.SwarmOptions,
 	}
 
 	log.Debug("setting hostname")
-	if err := provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseHostname, func() error {
+		return provisioner.SetHostname(provisioner.Driver.GetMachineName())
+	}); err != nil {
 		return err
 	}
 
-	log.Debug("installing base packages")
-	for _, pkg := range provisioner.Packages {
-		if err := provisioner.Package(pkg, pkgaction.Install); err != nil {
-			return err
+	if err := RunPhase(provisioner.checkpoints, PhaseEngine, func() error {
+		log.Debug("installing base packages")
+		for _, pkg := range provisioner.Packages {
+			if err := provisioner.Package(pkg, pkgaction.Install); err != nil {
+				return err
+			}
 		}
-	}
 
-	log.Debug("installing docker")
-	if err := installDockerGeneric(provisioner, engineOptions.InstallURL); err != nil {
+		log.Debug("installing docker")
+		return installDockerGeneric(provisioner, engineOptions.InstallURL)
+	}); err != nil {
 		return err
 	}
 
@@ -160,12 +164,16 @@ func (provisioner *DebianProvisioner) Provision(swarmOptions swarm.SwarmOptions,
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debug("configuring auth")
-	if err := ConfigureAuth(provisioner); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseAuth, func() error {
+		return ConfigureAuth(provisioner)
+	}); err != nil {
 		return err
 	}
 
 	log.Debug("configuring swarm")
-	if err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseSwarm, func() error {
+		return configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions)
+	}); err != nil {
 		return err
 	}
 
@@ -21,6 +21,16 @@ type GenericProvisioner struct {
 	AuthOptions       auth.AuthOptions
 	EngineOptions     engine.EngineOptions
 	SwarmOptions      swarm.SwarmOptions
+
+	checkpoints Checkpoints
+}
+
+func (provisioner *GenericProvisioner) SetCheckpoints(checkpoints Checkpoints) {
+	provisioner.checkpoints = checkpoints
+}
+
+func (provisioner *GenericProvisioner) SetAuthOptions(authOptions auth.AuthOptions) {
+	provisioner.AuthOptions = authOptions
 }
 
 func (provisioner *GenericProvisioner) Hostname() (string, error) {
@@ -44,6 +44,14 @@ type Provisioner interface {
 	//     5. Configure / activate swarm if applicable.
 	Provision(swarmOptions swarm.SwarmOptions, authOptions auth.AuthOptions, engineOptions engine.EngineOptions) error
 
+	// Set the checkpoints which record the phases of provisioning that
+	// are done, so that Provision skips them.
+	SetCheckpoints(checkpoints Checkpoints)
+
+	// Set the auth options of the machine, which Provision sets as well,
+	// for the operations which do not provision it.
+	SetAuthOptions(authOptions auth.AuthOptions)
+
 	// Perform action on a named service e.g. stop
 	Service(name string, action pkgaction.ServiceAction) error
 
@@ -97,27 +97,38 @@ func (provisioner *RancherProvisioner) Provision(swarmOptions swarm.SwarmOptions
 	}
 
 	log.Debugf("Setting hostname %s", provisioner.Driver.GetMachineName())
-	if err := provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseHostname, func() error {
+		return provisioner.SetHostname(provisioner.Driver.GetMachineName())
+	}); err != nil {
 		return err
 	}
 
-	for _, pkg := range provisioner.Packages {
-		log.Debugf("Installing package %s", pkg)
-		if err := provisioner.Package(pkg, pkgaction.Install); err != nil {
-			return err
+	if err := RunPhase(provisioner.checkpoints, PhaseEngine, func() error {
+		for _, pkg := range provisioner.Packages {
+			log.Debugf("Installing package %s", pkg)
+			if err := provisioner.Package(pkg, pkgaction.Install); err != nil {
+				return err
+			}
 		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	log.Debugf("Preparing certificates")
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debugf("Setting up certificates")
-	if err := ConfigureAuth(provisioner); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseAuth, func() error {
+		return ConfigureAuth(provisioner)
+	}); err != nil {
 		return err
 	}
 
 	log.Debugf("Configuring swarm")
-	if err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseSwarm, func() error {
+		return configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions)
+	}); err != nil {
 		return err
 	}
 
@@ -175,7 +186,8 @@ func (provisioner *RancherProvisioner) upgradeIso() error {
 
 	log.Infof("Upgrading machine %s...", machineName)
 
-	b2dutils := utils.NewB2dUtils("", "")
+	b2dutils := utils.NewB2dUtils("", "", provisioner.AuthOptions.StorePath)
+	b2dutils.Progress = drivers.GetProgress(provisioner.Driver)
 
 	url, err := provisioner.getLatestISOURL()
 	if err != nil {
@@ -187,7 +199,7 @@ func (provisioner *RancherProvisioner) upgradeIso() error {
 	}
 
 	// Copy the latest version of boot2docker ISO to the machine's directory
-	if err := b2dutils.CopyIsoToMachineDir("", machineName); err != nil {
+	if err := b2dutils.CopyIsoToMachineDir(""); err != nil {
 		return err
 	}
 
@@ -203,24 +203,28 @@ func (provisioner *RedHatProvisioner) Provision(swarmOptions swarm.SwarmOptions,
 		provisioner.EngineOptions.StorageDriver = "devicemapper"
 	}
 
-	if err := provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseHostname, func() error {
+		return provisioner.SetHostname(provisioner.Driver.GetMachineName())
+	}); err != nil {
 		return err
 	}
 
-	for _, pkg := range provisioner.Packages {
-		log.Debugf("installing base package: name=%s", pkg)
-		if err := provisioner.Package(pkg, pkgaction.Install); err != nil {
-			return err
+	if err := RunPhase(provisioner.checkpoints, PhaseEngine, func() error {
+		for _, pkg := range provisioner.Packages {
+			log.Debugf("installing base package: name=%s", pkg)
+			if err := provisioner.Package(pkg, pkgaction.Install); err != nil {
+				return err
+			}
 		}
-	}
 
-	// update OS -- this is needed for libdevicemapper and the docker install
-	if _, err := provisioner.SSHCommand("sudo yum -y update"); err != nil {
-		return err
-	}
+		// update OS -- this is needed for libdevicemapper and the docker install
+		if _, err := provisioner.SSHCommand("sudo yum -y update"); err != nil {
+			return err
+		}
 
-	// install docker
-	if err := installDocker(provisioner); err != nil {
+		// install docker
+		return installDocker(provisioner)
+	}); err != nil {
 		return err
 	}
 
@@ -234,11 +238,15 @@ func (provisioner *RedHatProvisioner) Provision(swarmOptions swarm.SwarmOptions,
 
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
-	if err := ConfigureAuth(provisioner); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseAuth, func() error {
+		return ConfigureAuth(provisioner)
+	}); err != nil {
 		return err
 	}
 
-	if err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions); err != nil {
+	if err := RunPhase(provisioner.checkpoints, PhaseSwarm, func() error {
+		return configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions)
+	}); err != nil {
 		return err
 	}
 
@@ -68,18 +68,18 @@ func ConfigureAuth(p Provisioner) error {
 	}
 
 	// copy certs to client dir for docker client
-	machineDir := filepath.Join(utils.GetMachineDir(), machineName)
+	machineDir := authOptions.StorePath
 
 	if err := utils.CopyFile(authOptions.CaCertPath, filepath.Join(machineDir, "ca.pem")); err != nil {
-		log.Fatalf("Error copying ca.pem to machine dir: %s", err)
+		return fmt.Errorf("Error copying ca.pem to machine dir: %s", err)
 	}
 
 	if err := utils.CopyFile(authOptions.ClientCertPath, filepath.Join(machineDir, "cert.pem")); err != nil {
-		log.Fatalf("Error copying cert.pem to machine dir: %s", err)
+		return fmt.Errorf("Error copying cert.pem to machine dir: %s", err)
 	}
 
 	if err := utils.CopyFile(authOptions.ClientKeyPath, filepath.Join(machineDir, "key.pem")); err != nil {
-		log.Fatalf("Error copying key.pem to machine dir: %s", err)
+		return fmt.Errorf("Error copying key.pem to machine dir: %s", err)
 	}
 
 	log.Debugf("generating server cert: %s ca-key=%s private-key=%s org=%s",
@@ -124,6 +124,9 @@ func ConfigureAuth(p Provisioner) error {
 		return err
 	}
 
+	// the key is sent in the command, which shows up in the debug log
+	log.RegisterSecret(string(serverKey))
+
 	// printf will choke if we don't pass a format string because of the
 	// dashes, so that's the reason for the '%%s'
 	certTransferCmdFmt := "printf '%%s' '%s' | sudo tee %s"
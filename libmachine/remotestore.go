@@ -0,0 +1,300 @@
+package libmachine
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/log"
+)
+
+// RemoteStore is a Store backed by a store server (see StoreServer).
+// Hosts are mirrored into a local directory, which acts as the store path,
+// so that SSH keys and certificates are available to the client.
+type RemoteStore struct {
+	url            string
+	path           string
+	caCertPath     string
+	privateKeyPath string
+	secretKey      *SecretKey
+	client         *http.Client
+}
+
+// IsRemoteStorePath returns whether the storage path refers to a store
+// server rather than a local directory.
+func IsRemoteStorePath(storagePath string) bool {
+	return strings.HasPrefix(storagePath, "https://")
+}
+
+// GetRemoteStoreCachePath returns the local directory a remote store is
+// mirrored into.
+func GetRemoteStoreCachePath(baseDir, storeURL string) (string, error) {
+	u, err := url.Parse(storeURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("Invalid store URL: %s", storeURL)
+	}
+	return filepath.Join(baseDir, "remote", strings.Replace(u.Host, ":", "_", -1)), nil
+}
+
+func NewRemoteStore(storeURL, rootPath, caCert, privateKey string, tlsConfig *tls.Config) *RemoteStore {
+	return &RemoteStore{
+		url:            strings.TrimRight(storeURL, "/"),
+		path:           rootPath,
+		caCertPath:     caCert,
+		privateKeyPath: privateKey,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}
+}
+
+// SetSecretKey sets the key the credentials of the drivers are encrypted
+// with before they are sent to the store server.  Every client of the
+// server needs to use the same key.
+func (s *RemoteStore) SetSecretKey(key *SecretKey) {
+	s.secretKey = key
+}
+
+func (s RemoteStore) getSecretKey() *SecretKey {
+	return s.secretKey
+}
+
+// getJournalPath returns the local cache of the store, so that its events
+// are kept apart from those of the local store.
+func (s RemoteStore) getJournalPath() string {
+	cachePath, err := GetRemoteStoreCachePath(s.path, s.url)
+	if err != nil {
+		return s.path
+	}
+	return cachePath
+}
+
+func (s RemoteStore) hostURL(name string) string {
+	return s.url + storeAPIPrefix + "/" + name
+}
+
+func (s RemoteStore) hostPath(name string) string {
+	return filepath.Join(s.path, "machines", name)
+}
+
+func (s RemoteStore) do(method, url string, body interface{}) (*http.Response, error) {
+	var data []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error contacting store at %s: %s", s.url, err)
+	}
+	return resp, nil
+}
+
+func checkStoreResponse(resp *http.Response, name string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	if resp.StatusCode == http.StatusNotFound && name != "" {
+		return ErrHostDoesNotExist{
+			Name: name,
+		}
+	}
+
+	msg, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("Store returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func (s RemoteStore) GetPath() string {
+	return s.path
+}
+
+func (s RemoteStore) GetCACertificatePath() (string, error) {
+	return s.caCertPath, nil
+}
+
+func (s RemoteStore) GetPrivateKeyPath() (string, error) {
+	return s.privateKeyPath, nil
+}
+
+func (s RemoteStore) Save(host *Host) error {
+	data, err := marshalHost(host, s.secretKey)
+	if err != nil {
+		return err
+	}
+
+	files, err := readHostFiles(host.StorePath)
+	if err != nil {
+		return err
+	}
+
+	remoteHost := RemoteHost{
+		Name:   host.Name,
+		Config: replaceStorePath(data, s.path, remoteStorePathPlaceholder),
+		Files:  files,
+	}
+
+	resp, err := s.do("PUT", s.hostURL(host.Name), remoteHost)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkStoreResponse(resp, host.Name)
+}
+
+func (s RemoteStore) Remove(name string, force bool) error {
+	resp, err := s.do("DELETE", s.hostURL(name), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStoreResponse(resp, name); err != nil {
+		if _, ok := err.(ErrHostDoesNotExist); !ok || !force {
+			return err
+		}
+	}
+
+	return os.RemoveAll(s.hostPath(name))
+}
+
+func (s RemoteStore) List() ([]*Host, error) {
+	resp, err := s.do("GET", s.url+storeAPIPrefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStoreResponse(resp, ""); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := json.NewDecoder(resp.Body).Decode(&names); err != nil {
+		return nil, err
+	}
+
+	hosts := []*Host{}
+
+	for _, name := range names {
+		host, err := s.Get(name)
+		if err != nil {
+			log.Errorf("error loading host %q: %s", name, err)
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+func (s RemoteStore) Exists(name string) (bool, error) {
+	resp, err := s.do("GET", s.hostURL(name), nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStoreResponse(resp, name); err != nil {
+		if _, ok := err.(ErrHostDoesNotExist); ok {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Get fetches the host from the store server, mirrors it into the local
+// store path and loads it from there.
+func (s RemoteStore) Get(name string) (*Host, error) {
+	resp, err := s.do("GET", s.hostURL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStoreResponse(resp, name); err != nil {
+		return nil, err
+	}
+
+	var remoteHost RemoteHost
+	if err := json.NewDecoder(resp.Body).Decode(&remoteHost); err != nil {
+		return nil, fmt.Errorf("Error decoding host %q: %s", name, err)
+	}
+
+	hostPath := s.hostPath(name)
+	config := replaceStorePath(remoteHost.Config, remoteStorePathPlaceholder, s.path)
+
+	if err := writeHostFiles(hostPath, config, remoteHost.Files); err != nil {
+		return nil, err
+	}
+
+	host := &Host{
+		Name:      name,
+		StorePath: hostPath,
+		secretKey: s.secretKey,
+		journal:   NewEventJournal(s.getJournalPath()),
+	}
+	if err := host.LoadConfig(); err != nil {
+		return nil, err
+	}
+
+	return host, nil
+}
+
+func (s RemoteStore) GetActive() (*Host, error) {
+	hosts, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var active *Host
+	for _, item := range GetHostListItems(hosts) {
+		if item.Active {
+			for _, host := range hosts {
+				if host.Name == item.Name {
+					active = host
+				}
+			}
+			break
+		}
+	}
+
+	for _, host := range hosts {
+		if host != active {
+			host.Close()
+		}
+	}
+
+	if active == nil {
+		return nil, errors.New("Active host not found")
+	}
+	return active, nil
+}
@@ -0,0 +1,236 @@
+package libmachine
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func getTestRemoteStore(t *testing.T) (*Filestore, *RemoteStore, func()) {
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(NewStoreServer(store.(*Filestore)))
+
+	clientDir, err := ioutil.TempDir("", "machine-test-client-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remoteStore := NewRemoteStore(server.URL, clientDir, hostTestCaCert, hostTestPrivateKey, nil)
+
+	return store.(*Filestore), remoteStore, func() {
+		server.Close()
+		os.RemoveAll(clientDir)
+		cleanup()
+	}
+}
+
+func saveTestHostWithFiles(t *testing.T, store Store) *Host {
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host.HostOptions.AuthOptions.ServerCertPath = filepath.Join(host.StorePath, "server.pem")
+
+	if err := store.Save(host); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(host.StorePath, "id_rsa"), []byte("private key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return host
+}
+
+func TestRemoteStoreGet(t *testing.T) {
+	_, remoteStore, cleanupRemote := getTestRemoteStore(t)
+	defer cleanupRemote()
+
+	saveTestHostWithFiles(t, NewFilestore(hostTestStorePath, hostTestCaCert, hostTestPrivateKey))
+
+	host, err := remoteStore.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPath := filepath.Join(remoteStore.GetPath(), "machines", hostTestName)
+	if host.StorePath != expectedPath {
+		t.Fatalf("expected store path %s; received %s", expectedPath, host.StorePath)
+	}
+
+	expectedCertPath := filepath.Join(expectedPath, "server.pem")
+	if host.HostOptions.AuthOptions.ServerCertPath != expectedCertPath {
+		t.Fatalf("expected server cert path %s; received %s", expectedCertPath, host.HostOptions.AuthOptions.ServerCertPath)
+	}
+
+	key, err := ioutil.ReadFile(filepath.Join(expectedPath, "id_rsa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != "private key" {
+		t.Fatalf("unexpected SSH key contents: %s", key)
+	}
+
+	if _, err := remoteStore.Get("nope-not-here"); err == nil {
+		t.Fatal("expected an error for a non-existent host")
+	} else if _, ok := err.(ErrHostDoesNotExist); !ok {
+		t.Fatalf("expected ErrHostDoesNotExist; received %v", err)
+	}
+}
+
+func TestRemoteStoreJournal(t *testing.T) {
+	_, remoteStore, cleanupRemote := getTestRemoteStore(t)
+	defer cleanupRemote()
+
+	saveTestHostWithFiles(t, NewFilestore(hostTestStorePath, hostTestCaCert, hostTestPrivateKey))
+
+	host, err := remoteStore.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer host.Close()
+
+	host.recordEvent("start", nil)
+
+	cachePath, err := GetRemoteStoreCachePath(remoteStore.GetPath(), remoteStore.url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := NewEventJournal(cachePath).Read(EventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Machine != hostTestName {
+		t.Fatalf("expected the event of %s in the journal of the remote store; received %v", hostTestName, events)
+	}
+
+	events, err = NewEventJournal(remoteStore.GetPath()).Read(EventFilter{})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events in the journal of the local store; received %v", events)
+	}
+}
+
+func TestRemoteStoreSave(t *testing.T) {
+	fileStore, remoteStore, cleanupRemote := getTestRemoteStore(t)
+	defer cleanupRemote()
+
+	saveTestHostWithFiles(t, fileStore)
+
+	host, err := remoteStore.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host.HostOptions.Memory = 2048
+	if err := remoteStore.Save(host); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(fileStore.GetPath(), "machines", hostTestName, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), remoteStore.GetPath()) {
+		t.Fatalf("client store path leaked into the server config: %s", data)
+	}
+
+	serverHost, err := fileStore.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if serverHost.HostOptions.Memory != 2048 {
+		t.Fatalf("expected memory 2048; received %d", serverHost.HostOptions.Memory)
+	}
+
+	expectedCertPath := filepath.Join(fileStore.GetPath(), "machines", hostTestName, "server.pem")
+	if serverHost.HostOptions.AuthOptions.ServerCertPath != expectedCertPath {
+		t.Fatalf("expected server cert path %s; received %s", expectedCertPath, serverHost.HostOptions.AuthOptions.ServerCertPath)
+	}
+}
+
+func TestRemoteStoreListExistsRemove(t *testing.T) {
+	fileStore, remoteStore, cleanupRemote := getTestRemoteStore(t)
+	defer cleanupRemote()
+
+	exists, err := remoteStore.Exists(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("Exists returned true when it should have been false")
+	}
+
+	saveTestHostWithFiles(t, fileStore)
+
+	exists, err = remoteStore.Exists(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatal("Exists returned false when it should have been true")
+	}
+
+	hosts, err := remoteStore.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Name != hostTestName {
+		t.Fatalf("unexpected hosts listed: %v", hosts)
+	}
+
+	if err := remoteStore.Remove(hostTestName, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(fileStore.GetPath(), "machines", hostTestName)); !os.IsNotExist(err) {
+		t.Fatal("host still exists in the server store after remove")
+	}
+
+	if _, err := os.Stat(filepath.Join(remoteStore.GetPath(), "machines", hostTestName)); !os.IsNotExist(err) {
+		t.Fatal("host still exists in the client store after remove")
+	}
+}
+
+func TestRemoteStoreEncryptsSecrets(t *testing.T) {
+	_, remoteStore, cleanupRemote := getTestRemoteStore(t)
+	defer cleanupRemote()
+
+	key, err := GenerateSecretKey(remoteStore.GetPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteStore.SetSecretKey(key)
+
+	getTestSecretHost(t, remoteStore)
+
+	// the config as it is kept by the server
+	data, err := ioutil.ReadFile(filepath.Join(hostTestStorePath, "machines", hostTestName, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secretTestToken) {
+		t.Fatal("expected the token not to be sent in plaintext")
+	}
+
+	loaded, err := remoteStore.Get(hostTestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := loaded.Driver.(*secretTestDriver).Token; token != secretTestToken {
+		t.Fatalf("expected the token to be decrypted; received %s", token)
+	}
+}
@@ -0,0 +1,84 @@
+package libmachine
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/state"
+	"golang.org/x/net/context"
+)
+
+// Resize changes the resources of the machine.  A running machine is
+// stopped, resized and started again, after which its certificates are
+// checked, as it may have a new IP address.
+func (h *Host) Resize(opts drivers.ResizeOptions) error {
+	return h.recordEvent("resize", h.resize(opts))
+}
+
+func (h *Host) resize(opts drivers.ResizeOptions) error {
+	if !drivers.HasCapability(h.Driver, drivers.CapabilityResize) {
+		return ErrNotSupported{Name: h.Name, DriverName: h.DriverName, Operation: "resizing"}
+	}
+	resizer := h.Driver.(drivers.Resizer)
+
+	if opts == (drivers.ResizeOptions{}) {
+		return fmt.Errorf("No resources to resize machine %s to", h.Name)
+	}
+
+	machineState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+
+	driver := drivers.NewContextDriver(h.Driver)
+	ctx := context.Background()
+
+	switch machineState {
+	case state.Running:
+		if err := h.runActionForState(ctx, driver.StopContext, state.Stopped); err != nil {
+			return err
+		}
+	case state.Stopped:
+	default:
+		return fmt.Errorf("Machine %s must be running or stopped to be resized; it is %s", h.Name, machineState)
+	}
+
+	resizeErr := resizer.Resize(opts)
+
+	// The driver may have applied some of the options before failing, so
+	// its config is saved either way.
+	if err := h.SaveConfig(); err != nil {
+		if resizeErr != nil {
+			log.Errorf("Error saving machine %s: %s", h.Name, err)
+		} else {
+			resizeErr = err
+		}
+	}
+
+	if machineState != state.Running {
+		return resizeErr
+	}
+
+	// A machine which was running is started again even if it could not be
+	// resized.
+	if err := h.runActionForState(ctx, driver.StartContext, state.Running); err != nil {
+		if resizeErr != nil {
+			return fmt.Errorf("%s; machine %s could not be started again: %s", resizeErr, h.Name, err)
+		}
+		return err
+	}
+
+	if resizeErr != nil {
+		return resizeErr
+	}
+
+	return h.checkCertificates()
+}
+
+// Resize changes the resources of the machine "name", see Host.Resize.
+func (provider *Provider) Resize(name string, opts drivers.ResizeOptions) error {
+	return provider.withLockedHost(name, "resize", func(host *Host) error {
+		return host.Resize(opts)
+	})
+}
@@ -0,0 +1,129 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/state"
+)
+
+type resizeTestDriver struct {
+	*fakedriver.FakeDriver
+	Resized      drivers.ResizeOptions
+	StateResized state.State
+	err          error
+}
+
+// Resize fails after resizing the memory if err is set.
+func (d *resizeTestDriver) Resize(opts drivers.ResizeOptions) error {
+	d.StateResized = d.MockState
+	if d.err != nil {
+		d.Resized.Memory = opts.Memory
+		return d.err
+	}
+	d.Resized = opts
+	return nil
+}
+
+func TestHostResizeRunning(t *testing.T) {
+	defer cleanup()
+
+	driver := &resizeTestDriver{FakeDriver: &fakedriver.FakeDriver{MockState: state.Running}}
+	host := getFakeTestHost(t, driver)
+
+	opts := drivers.ResizeOptions{CPU: 2, Memory: 2048}
+	if err := host.Resize(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if driver.Resized != opts {
+		t.Fatalf("expected the machine to be resized to %v; received %v", opts, driver.Resized)
+	}
+	if driver.StateResized != state.Stopped {
+		t.Fatalf("expected the machine to be stopped while resized; it was %s", driver.StateResized)
+	}
+	if driver.MockState != state.Running {
+		t.Fatalf("expected the machine to be started again; it is %s", driver.MockState)
+	}
+}
+
+func TestHostResizeFailure(t *testing.T) {
+	defer cleanup()
+
+	driver := &resizeTestDriver{FakeDriver: &fakedriver.FakeDriver{MockState: state.Running}}
+	host := getFakeTestHost(t, driver)
+	driver.err = errors.New("too many CPUs")
+
+	if err := host.Resize(drivers.ResizeOptions{CPU: 64, Memory: 2048}); err != driver.err {
+		t.Fatalf("expected the error of the driver; received %v", err)
+	}
+
+	if driver.MockState != state.Running {
+		t.Fatalf("expected the machine to be started again; it is %s", driver.MockState)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(host.StorePath, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Driver resizeTestDriver
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Driver.Resized.Memory != 2048 {
+		t.Fatalf("expected the memory which was resized to be saved; received %+v", config.Driver.Resized)
+	}
+}
+
+func TestHostResizeStopped(t *testing.T) {
+	defer cleanup()
+
+	driver := &resizeTestDriver{FakeDriver: &fakedriver.FakeDriver{MockState: state.Stopped}}
+	host := getFakeTestHost(t, driver)
+
+	if err := host.Resize(drivers.ResizeOptions{Memory: 2048}); err != nil {
+		t.Fatal(err)
+	}
+
+	if driver.MockState != state.Stopped {
+		t.Fatalf("expected the machine to be left stopped; it is %s", driver.MockState)
+	}
+}
+
+func TestHostResizeNoOptions(t *testing.T) {
+	defer cleanup()
+
+	host := getFakeTestHost(t, &resizeTestDriver{FakeDriver: &fakedriver.FakeDriver{MockState: state.Running}})
+
+	if err := host.Resize(drivers.ResizeOptions{}); err == nil {
+		t.Fatal("expected an error resizing without options")
+	}
+}
+
+func TestProviderResizeNotSupported(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getTestProviderHost(t, provider, hostTestName)
+
+	err = provider.Resize(hostTestName, drivers.ResizeOptions{Memory: 2048})
+	if _, ok := err.(ErrNotSupported); !ok {
+		t.Fatalf("expected ErrNotSupported; received %v", err)
+	}
+}
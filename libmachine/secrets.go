@@ -0,0 +1,342 @@
+package libmachine
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// SecretKeyFile and SecretSaltFile live in the root of a store.  The
+	// key file holds a random key; the salt file is used to derive the
+	// key from a passphrase instead.
+	SecretKeyFile  = "secret.key"
+	SecretSaltFile = "secret.salt"
+
+	// a key which is being rolled out by a rekey; hosts may already be
+	// encrypted with it if the rekey was interrupted
+	pendingKeySuffix = ".new"
+
+	secretPrefix     = "encrypted:v1:"
+	secretKeyLength  = 32
+	secretSaltLength = 16
+	secretKDFRounds  = 100000
+)
+
+var (
+	ErrSecretKeyMissing = errors.New("the machine has encrypted credentials, but no store key is configured")
+	ErrSecretDecrypt    = errors.New("unable to decrypt the credentials of the machine with the store key")
+)
+
+// SecretKey encrypts the driver fields tagged as secret (see
+// utils.SecretTag) when a host config is saved, and decrypts them when it
+// is loaded.
+type SecretKey struct {
+	// keys[0] is used for encryption, all of them for decryption
+	keys [][]byte
+}
+
+func NewSecretKey(key []byte) (*SecretKey, error) {
+	if len(key) != secretKeyLength {
+		return nil, fmt.Errorf("invalid secret key length %d; expected %d bytes", len(key), secretKeyLength)
+	}
+	return &SecretKey{
+		keys: [][]byte{key},
+	}, nil
+}
+
+// LoadSecretKey returns the key of the store at storePath.  If passphrase
+// is set, the key is derived from it, otherwise the key file of the store
+// is used.  When the store has no key, nil is returned and credentials are
+// stored in plaintext.
+func LoadSecretKey(storePath, passphrase string) (*SecretKey, error) {
+	if passphrase != "" {
+		salt, err := ioutil.ReadFile(filepath.Join(storePath, SecretSaltFile))
+		if os.IsNotExist(err) {
+			salt, err = generateSecretSalt(filepath.Join(storePath, SecretSaltFile))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return NewSecretKey(deriveSecretKey(passphrase, salt))
+	}
+
+	keyPath := filepath.Join(storePath, SecretKeyFile)
+	key, err := readSecretKeyFile(keyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	secretKey, err := NewSecretKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", keyPath, err)
+	}
+
+	if pending, err := readSecretKeyFile(keyPath + pendingKeySuffix); err == nil && len(pending) == secretKeyLength {
+		secretKey.keys = append(secretKey.keys, pending)
+	}
+
+	return secretKey, nil
+}
+
+// GenerateSecretKey creates a new random key file for the store at
+// storePath.
+func GenerateSecretKey(storePath string) (*SecretKey, error) {
+	key, err := newRandomBytes(secretKeyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(storePath, 0700); err != nil {
+		return nil, err
+	}
+
+	if err := writeSecretKeyFile(filepath.Join(storePath, SecretKeyFile), key); err != nil {
+		return nil, err
+	}
+
+	return NewSecretKey(key)
+}
+
+// RekeyStore encrypts the credentials of every host in store with a new
+// key.  If passphrase is set, the new key is derived from it with a new
+// salt, otherwise a new random key file is created.  The store must be
+// able to load every host with its current key.
+func RekeyStore(store *Filestore, passphrase string) (*SecretKey, error) {
+	// List skips the hosts it can not load, which would lose their
+	// credentials, so load every one of them here instead.
+	dir, err := ioutil.ReadDir(store.getMachinesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	hosts := []*Host{}
+	defer func() {
+		CloseHosts(hosts)
+	}()
+
+	for _, file := range dir {
+		if file.IsDir() && !strings.HasPrefix(file.Name(), ".") {
+			host, err := store.Get(file.Name())
+			if err != nil {
+				return nil, fmt.Errorf("Error loading host %q: %s", file.Name(), err)
+			}
+			hosts = append(hosts, host)
+		}
+	}
+
+	var (
+		newKey      []byte
+		pendingPath string
+		finalPath   string
+	)
+
+	if passphrase != "" {
+		finalPath = filepath.Join(store.GetPath(), SecretSaltFile)
+		pendingPath = finalPath + pendingKeySuffix
+		salt, err := generateSecretSalt(pendingPath)
+		if err != nil {
+			return nil, err
+		}
+		newKey = deriveSecretKey(passphrase, salt)
+	} else {
+		finalPath = filepath.Join(store.GetPath(), SecretKeyFile)
+		pendingPath = finalPath + pendingKeySuffix
+		newKey, err = newRandomBytes(secretKeyLength)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeSecretKeyFile(pendingPath, newKey); err != nil {
+			return nil, err
+		}
+	}
+
+	secretKey, err := NewSecretKey(newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	store.SetSecretKey(secretKey)
+
+	for _, host := range hosts {
+		host.secretKey = secretKey
+		if err := host.SaveConfig(); err != nil {
+			return nil, fmt.Errorf("Error saving host %q: %s", host.Name, err)
+		}
+	}
+
+	if err := os.Rename(pendingPath, finalPath); err != nil {
+		return nil, err
+	}
+
+	// a passphrase takes precedence over the key file, so only keep the
+	// one which is in use
+	stale := filepath.Join(store.GetPath(), SecretSaltFile)
+	if passphrase != "" {
+		stale = filepath.Join(store.GetPath(), SecretKeyFile)
+	}
+	if err := os.Remove(stale); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return secretKey, nil
+}
+
+func (k *SecretKey) encrypt(plaintext string) (string, error) {
+	gcm, err := newSecretCipher(k.keys[0])
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := newRandomBytes(gcm.NonceSize())
+	if err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return secretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (k *SecretKey) decrypt(value string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, secretPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	for _, key := range k.keys {
+		gcm, err := newSecretCipher(key)
+		if err != nil {
+			return "", err
+		}
+		if len(sealed) < gcm.NonceSize() {
+			return "", ErrSecretDecrypt
+		}
+		nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+		if plaintext, err := gcm.Open(nil, nonce, ciphertext, nil); err == nil {
+			return string(plaintext), nil
+		}
+	}
+
+	return "", ErrSecretDecrypt
+}
+
+func isEncryptedSecret(value string) bool {
+	return strings.HasPrefix(value, secretPrefix)
+}
+
+// encryptSecrets returns a copy of the driver with its secret fields
+// encrypted.
+func encryptSecrets(driver interface{}, key *SecretKey) (interface{}, error) {
+	return utils.CopySecrets(driver, func(value string) (string, error) {
+		if isEncryptedSecret(value) {
+			return value, nil
+		}
+		return key.encrypt(value)
+	})
+}
+
+// decryptSecrets returns a copy of the driver with its secret fields
+// decrypted, and whether any of them were stored in plaintext.  key may be
+// nil as long as none of the fields are encrypted.
+func decryptSecrets(driver interface{}, key *SecretKey) (interface{}, bool, error) {
+	plaintext := false
+	decrypted, err := utils.CopySecrets(driver, func(value string) (string, error) {
+		if !isEncryptedSecret(value) {
+			plaintext = true
+			return value, nil
+		}
+		if key == nil {
+			return "", ErrSecretKeyMissing
+		}
+		return key.decrypt(value)
+	})
+	return decrypted, plaintext, err
+}
+
+// registerSecrets makes the log redact the credentials of the driver.
+func registerSecrets(driver interface{}) {
+	utils.CopySecrets(driver, func(value string) (string, error) {
+		log.RegisterSecret(value)
+		return value, nil
+	})
+}
+
+// RedactSecrets returns a copy of the host for showing to the user, in
+// which the credentials of the driver are redacted unless the user asked
+// to be shown secrets.
+func RedactSecrets(h *Host) *Host {
+	if h.Driver == nil {
+		return h
+	}
+
+	redacted := *h
+	if driver, ok := utils.RedactSecrets(h.Driver).(drivers.Driver); ok {
+		redacted.Driver = driver
+	}
+	return &redacted
+}
+
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func newRandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func readSecretKeyFile(path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimSpace(string(data)))
+}
+
+func writeSecretKeyFile(path string, key []byte) error {
+	return utils.WriteFileAtomic(path, []byte(hex.EncodeToString(key)+"\n"), 0600)
+}
+
+func generateSecretSalt(path string) ([]byte, error) {
+	salt, err := newRandomBytes(secretSaltLength)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := utils.WriteFileAtomic(path, salt, 0600); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// deriveSecretKey derives a key from a passphrase with PBKDF2-HMAC-SHA256.
+func deriveSecretKey(passphrase string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(passphrase), salt, secretKDFRounds, secretKeyLength, sha256.New)
+}
@@ -0,0 +1,262 @@
+package libmachine
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/log"
+)
+
+const secretTestToken = "very-secret-token"
+
+type secretTestDriver struct {
+	*none.Driver
+	Token string `secret:"true"`
+}
+
+func init() {
+	drivers.Register("secrettest", &drivers.RegisteredDriver{
+		New: func(machineName string, storePath string, caCert string, privateKey string) (drivers.Driver, error) {
+			d, err := none.NewDriver(machineName, storePath, caCert, privateKey)
+			if err != nil {
+				return nil, err
+			}
+			return &secretTestDriver{Driver: d.(*none.Driver)}, nil
+		},
+		GetCreateFlags: none.GetCreateFlags,
+	})
+}
+
+func getTestSecretHost(t *testing.T, store Store) *Host {
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driver, err := drivers.NewDriver("secrettest", host.Name, host.StorePath, hostTestCaCert, hostTestPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	driver.(*secretTestDriver).Token = secretTestToken
+
+	host.Driver = driver
+	host.DriverName = "secrettest"
+
+	if err := store.Save(host); err != nil {
+		t.Fatal(err)
+	}
+
+	return host
+}
+
+func readTestConfig(t *testing.T, host *Host) []byte {
+	data, err := ioutil.ReadFile(filepath.Join(host.StorePath, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDeriveSecretKey(t *testing.T) {
+	// stores encrypted with a passphrase depend on the key staying the same
+	key := deriveSecretKey("passphrase", []byte("salt"))
+	if hex.EncodeToString(key) != "a2c6527b0e3f8d38ae38f9893327b9fd6996066448d610c3495c49471d429fc9" {
+		t.Fatalf("unexpected key: %x", key)
+	}
+}
+
+func TestSecretKeyEncryptDecrypt(t *testing.T) {
+	key, err := NewSecretKey(bytes.Repeat([]byte{1}, secretKeyLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := key.encrypt("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncryptedSecret(encrypted) {
+		t.Fatalf("expected an encrypted value; received %s", encrypted)
+	}
+
+	decrypted, err := key.decrypt(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != "secret" {
+		t.Fatalf("expected secret; received %s", decrypted)
+	}
+
+	otherKey, err := NewSecretKey(bytes.Repeat([]byte{2}, secretKeyLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otherKey.decrypt(encrypted); err != ErrSecretDecrypt {
+		t.Fatalf("expected ErrSecretDecrypt; received %v", err)
+	}
+}
+
+func TestStoreEncryptsSecrets(t *testing.T) {
+	defer cleanup()
+
+	s, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := s.(*Filestore)
+
+	key, err := GenerateSecretKey(store.GetPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetSecretKey(key)
+
+	host := getTestSecretHost(t, store)
+
+	if bytes.Contains(readTestConfig(t, host), []byte(secretTestToken)) {
+		t.Fatal("expected the token not to be stored in plaintext")
+	}
+
+	if host.Driver.(*secretTestDriver).Token != secretTestToken {
+		t.Fatal("expected the driver of the saved host to be left untouched")
+	}
+
+	loaded, err := store.Get(host.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := loaded.Driver.(*secretTestDriver).Token; token != secretTestToken {
+		t.Fatalf("expected the token to be decrypted; received %s", token)
+	}
+
+	// a store without the key can not load the host
+	if _, err := NewFilestore(store.GetPath(), hostTestCaCert, hostTestPrivateKey).Get(host.Name); err == nil {
+		t.Fatal("expected an error loading the host without a key")
+	}
+}
+
+func TestStoreMigratesPlaintextSecrets(t *testing.T) {
+	defer cleanup()
+
+	s, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := s.(*Filestore)
+
+	host := getTestSecretHost(t, store)
+
+	if !bytes.Contains(readTestConfig(t, host), []byte(secretTestToken)) {
+		t.Fatal("expected the token to be stored in plaintext without a key")
+	}
+
+	key, err := GenerateSecretKey(store.GetPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetSecretKey(key)
+
+	loaded, err := store.Get(host.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := loaded.Driver.(*secretTestDriver).Token; token != secretTestToken {
+		t.Fatalf("expected token %s; received %s", secretTestToken, token)
+	}
+
+	if bytes.Contains(readTestConfig(t, host), []byte(secretTestToken)) {
+		t.Fatal("expected the token to be encrypted after loading the host")
+	}
+}
+
+func TestRekeyStore(t *testing.T) {
+	defer cleanup()
+
+	s, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := s.(*Filestore)
+
+	oldKey, err := GenerateSecretKey(store.GetPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SetSecretKey(oldKey)
+
+	host := getTestSecretHost(t, store)
+
+	if _, err := RekeyStore(store, "passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(store.GetPath(), SecretKeyFile)); !os.IsNotExist(err) {
+		t.Fatal("expected the key file to be removed after rekeying with a passphrase")
+	}
+
+	newKey, err := LoadSecretKey(store.GetPath(), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rekeyed := NewFilestore(store.GetPath(), hostTestCaCert, hostTestPrivateKey)
+	rekeyed.SetSecretKey(newKey)
+
+	loaded, err := rekeyed.Get(host.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token := loaded.Driver.(*secretTestDriver).Token; token != secretTestToken {
+		t.Fatalf("expected token %s; received %s", secretTestToken, token)
+	}
+
+	store.SetSecretKey(oldKey)
+	if _, err := store.Get(host.Name); err == nil {
+		t.Fatal("expected an error loading the host with the old key")
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	defer cleanup()
+
+	s, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := getTestSecretHost(t, s.(*Filestore))
+
+	data, err := json.Marshal(RedactSecrets(host))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(data, []byte(secretTestToken)) {
+		t.Fatal("expected the token to be redacted")
+	}
+	if token := RedactSecrets(host).Driver.(*secretTestDriver).Token; token != log.Redacted {
+		t.Fatalf("expected %s; received %s", log.Redacted, token)
+	}
+
+	if host.Driver.(*secretTestDriver).Token != secretTestToken {
+		t.Fatal("expected the host to be left untouched")
+	}
+
+	log.SetShowSecrets(true)
+	defer log.SetShowSecrets(false)
+
+	data, err = json.Marshal(RedactSecrets(host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(secretTestToken)) {
+		t.Fatal("expected the token to be shown")
+	}
+}
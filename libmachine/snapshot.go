@@ -0,0 +1,153 @@
+package libmachine
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/state"
+	"github.com/docker/machine/utils"
+	"golang.org/x/net/context"
+)
+
+func (h *Host) getSnapshotter() (drivers.Snapshotter, error) {
+	if !drivers.HasCapability(h.Driver, drivers.CapabilitySnapshot) {
+		return nil, ErrNotSupported{Name: h.Name, DriverName: h.DriverName, Operation: "snapshots"}
+	}
+	return h.Driver.(drivers.Snapshotter), nil
+}
+
+func (h *Host) ListSnapshots() ([]drivers.Snapshot, error) {
+	snapshotter, err := h.getSnapshotter()
+	if err != nil {
+		return nil, err
+	}
+	return snapshotter.ListSnapshots()
+}
+
+func (h *Host) TakeSnapshot(name string) error {
+	return h.recordEvent("snapshot", h.takeSnapshot(name))
+}
+
+func (h *Host) takeSnapshot(name string) error {
+	if !ValidateHostName(name) {
+		return fmt.Errorf("Invalid snapshot name %q", name)
+	}
+
+	snapshotter, err := h.getSnapshotter()
+	if err != nil {
+		return err
+	}
+	return snapshotter.TakeSnapshot(name)
+}
+
+// RestoreSnapshot restores the machine to the snapshot "name" and starts
+// it.  The machine may have had another IP address when the snapshot was
+// taken, so its certificates are checked and regenerated if they are not
+// valid for its address anymore.
+func (h *Host) RestoreSnapshot(name string) error {
+	return h.recordEvent("snapshot-restore", h.restoreSnapshot(name))
+}
+
+func (h *Host) restoreSnapshot(name string) error {
+	snapshotter, err := h.getSnapshotter()
+	if err != nil {
+		return err
+	}
+
+	if err := snapshotter.RestoreSnapshot(name); err != nil {
+		return err
+	}
+
+	if err := h.SaveConfig(); err != nil {
+		return err
+	}
+
+	driver := drivers.NewContextDriver(h.Driver)
+	if err := h.runActionForState(context.Background(), driver.StartContext, state.Running); err != nil {
+		return err
+	}
+
+	return h.checkCertificates()
+}
+
+func (h *Host) RemoveSnapshot(name string) error {
+	return h.recordEvent("snapshot-remove", h.removeSnapshot(name))
+}
+
+func (h *Host) removeSnapshot(name string) error {
+	snapshotter, err := h.getSnapshotter()
+	if err != nil {
+		return err
+	}
+	return snapshotter.RemoveSnapshot(name)
+}
+
+// checkCertificates regenerates the certificates of a running machine if
+// its server certificate is not valid for its address.
+func (h *Host) checkCertificates() error {
+	machineURL, err := h.GetURL()
+	if err != nil || machineURL == "" {
+		return err
+	}
+
+	u, err := url.Parse(machineURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme == "unix" || h.HostOptions == nil || h.HostOptions.AuthOptions == nil {
+		return nil
+	}
+
+	authOptions := h.HostOptions.AuthOptions
+	valid, err := utils.ValidateCertificate(
+		u.Host,
+		authOptions.CaCertPath,
+		authOptions.ServerCertPath,
+		authOptions.ServerKeyPath,
+	)
+	if err != nil {
+		return err
+	}
+
+	if valid {
+		return nil
+	}
+
+	drivers.GetProgress(h.Driver).Infof("Regenerating the certificates of %s for %s", h.Name, u.Host)
+	return h.configureAuth()
+}
+
+// TakeSnapshot takes the snapshot "snapshot" of the machine "name".
+func (provider *Provider) TakeSnapshot(name, snapshot string) error {
+	return provider.withLockedHost(name, "snapshot create", func(host *Host) error {
+		return host.TakeSnapshot(snapshot)
+	})
+}
+
+// ListSnapshots returns the snapshots of the machine "name".
+func (provider *Provider) ListSnapshots(name string) ([]drivers.Snapshot, error) {
+	host, err := provider.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	defer host.Close()
+
+	return host.ListSnapshots()
+}
+
+// RestoreSnapshot restores the machine "name" to the snapshot "snapshot",
+// see Host.RestoreSnapshot.
+func (provider *Provider) RestoreSnapshot(name, snapshot string) error {
+	return provider.withLockedHost(name, "snapshot restore", func(host *Host) error {
+		return host.RestoreSnapshot(snapshot)
+	})
+}
+
+// RemoveSnapshot removes the snapshot "snapshot" of the machine "name".
+func (provider *Provider) RemoveSnapshot(name, snapshot string) error {
+	return provider.withLockedHost(name, "snapshot rm", func(host *Host) error {
+		return host.RemoveSnapshot(snapshot)
+	})
+}
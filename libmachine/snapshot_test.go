@@ -0,0 +1,93 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/state"
+)
+
+type snapshotTestDriver struct {
+	*fakedriver.FakeDriver
+	Snapshots []drivers.Snapshot
+}
+
+func (d *snapshotTestDriver) ListSnapshots() ([]drivers.Snapshot, error) {
+	return d.Snapshots, nil
+}
+
+func (d *snapshotTestDriver) TakeSnapshot(name string) error {
+	d.Snapshots = append(d.Snapshots, drivers.Snapshot{Name: name})
+	return nil
+}
+
+func (d *snapshotTestDriver) RestoreSnapshot(name string) error {
+	if _, ok := drivers.FindSnapshot(d.Snapshots, name); !ok {
+		return drivers.ErrSnapshotDoesNotExist{Name: name}
+	}
+	d.MockState = state.Stopped
+	return nil
+}
+
+func (d *snapshotTestDriver) RemoveSnapshot(name string) error {
+	d.Snapshots = nil
+	return nil
+}
+
+func TestHostSnapshots(t *testing.T) {
+	defer cleanup()
+
+	driver := &snapshotTestDriver{
+		FakeDriver: &fakedriver.FakeDriver{MockState: state.Running},
+	}
+	host := getFakeTestHost(t, driver)
+
+	if err := host.TakeSnapshot("in valid"); err == nil {
+		t.Fatal("expected an error for an invalid snapshot name")
+	}
+
+	if err := host.TakeSnapshot("clean"); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := host.ListSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != "clean" {
+		t.Fatalf("expected the snapshot clean; received %v", snapshots)
+	}
+
+	if err := host.RestoreSnapshot("other"); err == nil {
+		t.Fatal("expected an error restoring a snapshot which does not exist")
+	}
+
+	if err := host.RestoreSnapshot("clean"); err != nil {
+		t.Fatal(err)
+	}
+	if driver.MockState != state.Running {
+		t.Fatalf("expected the machine to be started after the restore; received %s", driver.MockState)
+	}
+}
+
+func TestProviderSnapshotsNotSupported(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getTestProviderHost(t, provider, hostTestName)
+
+	err = provider.TakeSnapshot(hostTestName, "clean")
+	if _, ok := err.(ErrNotSupported); !ok {
+		t.Fatalf("expected ErrNotSupported; received %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package libmachine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/log"
+	"github.com/docker/machine/utils"
+)
+
+const (
+	storeAPIPrefix = "/v1/machines"
+
+	// remoteStorePathPlaceholder replaces the root of the store in the
+	// host configs which are sent over the wire, so that each side can
+	// rewrite the paths to its own store root.
+	remoteStorePathPlaceholder = "$MACHINE_STORAGE_PATH"
+)
+
+// hostFiles are the files of a machine directory which are copied along
+// with its config, to a remote store or into an export bundle.  Disk
+// images and the like are left out on purpose.
+var hostFiles = []string{
+	"id_rsa",
+	"id_rsa.pub",
+	"ca.pem",
+	"cert.pem",
+	"key.pem",
+	"server.pem",
+	"server-key.pem",
+}
+
+// RemoteHost is the wire representation of a host in the store API.
+type RemoteHost struct {
+	Name   string
+	Config []byte
+	Files  map[string][]byte
+}
+
+// StoreServer serves the hosts of a Filestore over HTTP for use with a
+// RemoteStore.
+type StoreServer struct {
+	store *Filestore
+}
+
+func NewStoreServer(store *Filestore) *StoreServer {
+	return &StoreServer{
+		store: store,
+	}
+}
+
+func (s *StoreServer) machineDir() string {
+	return filepath.Join(s.store.GetPath(), "machines")
+}
+
+func (s *StoreServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("store server: %s %s", r.Method, r.URL.Path)
+
+	if r.URL.Path == storeAPIPrefix {
+		if r.Method != "GET" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.listHosts(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, storeAPIPrefix+"/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, storeAPIPrefix+"/")
+	// names starting with a dot are valid hostnames, but would allow
+	// escaping the machine directory
+	if !ValidateHostName(name) || strings.HasPrefix(name, ".") {
+		http.Error(w, ErrInvalidHostname.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		s.getHost(w, r, name)
+	case "PUT":
+		s.saveHost(w, r, name)
+	case "DELETE":
+		s.removeHost(w, r, name)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *StoreServer) listHosts(w http.ResponseWriter, r *http.Request) {
+	dir, err := ioutil.ReadDir(s.machineDir())
+	if err != nil && !os.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := []string{}
+	for _, file := range dir {
+		if file.IsDir() && !strings.HasPrefix(file.Name(), ".") {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+
+	writeJSON(w, names)
+}
+
+func (s *StoreServer) getHost(w http.ResponseWriter, r *http.Request, name string) {
+	hostPath := filepath.Join(s.machineDir(), name)
+
+	config, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, ErrHostDoesNotExist{Name: name}.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	files, err := readHostFiles(hostPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, RemoteHost{
+		Name:   name,
+		Config: replaceStorePath(config, s.store.GetPath(), remoteStorePathPlaceholder),
+		Files:  files,
+	})
+}
+
+func (s *StoreServer) saveHost(w http.ResponseWriter, r *http.Request, name string) {
+	var remoteHost RemoteHost
+	if err := json.NewDecoder(r.Body).Decode(&remoteHost); err != nil {
+		http.Error(w, fmt.Sprintf("Error decoding host: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	hostPath := filepath.Join(s.machineDir(), name)
+	config := replaceStorePath(remoteHost.Config, remoteStorePathPlaceholder, s.store.GetPath())
+
+	if err := writeHostFiles(hostPath, config, remoteHost.Files); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *StoreServer) removeHost(w http.ResponseWriter, r *http.Request, name string) {
+	hostPath := filepath.Join(s.machineDir(), name)
+	if _, err := os.Stat(hostPath); os.IsNotExist(err) {
+		http.Error(w, ErrHostDoesNotExist{Name: name}.Error(), http.StatusNotFound)
+		return
+	}
+
+	if err := os.RemoveAll(hostPath); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	writeJSONStatus(w, http.StatusOK, v)
+}
+
+func writeJSONStatus(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("Error encoding response: %s", err)
+	}
+}
+
+func readHostFiles(hostPath string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, name := range hostFiles {
+		data, err := ioutil.ReadFile(filepath.Join(hostPath, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+func writeHostFiles(hostPath string, config []byte, files map[string][]byte) error {
+	if err := os.MkdirAll(hostPath, 0700); err != nil {
+		return err
+	}
+
+	for _, name := range hostFiles {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(hostPath, name), data, 0600); err != nil {
+			return err
+		}
+	}
+
+	return utils.WriteFileAtomic(filepath.Join(hostPath, "config.json"), config, 0600)
+}
+
+// replaceStorePath replaces every occurrence of the path "old" in the JSON
+// document "data" with "new".
+func replaceStorePath(data []byte, old, new string) []byte {
+	return bytes.Replace(data, jsonEscape(old), jsonEscape(new), -1)
+}
+
+func jsonEscape(s string) []byte {
+	escaped, _ := json.Marshal(s)
+	return escaped[1 : len(escaped)-1]
+}
@@ -0,0 +1,50 @@
+package libmachine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReplaceStorePath(t *testing.T) {
+	data := []byte(`{"StorePath":"/home/user/.docker/machine/machines/dev","Name":"dev"}`)
+	expected := `{"StorePath":"$MACHINE_STORAGE_PATH/machines/dev","Name":"dev"}`
+
+	replaced := replaceStorePath(data, "/home/user/.docker/machine", remoteStorePathPlaceholder)
+	if string(replaced) != expected {
+		t.Fatalf("expected %s; received %s", expected, replaced)
+	}
+}
+
+func TestReplaceStorePathEscaped(t *testing.T) {
+	data := []byte(`{"StorePath":"C:\\Users\\user\\.docker\\machine\\machines\\dev"}`)
+	expected := `{"StorePath":"$MACHINE_STORAGE_PATH\\machines\\dev"}`
+
+	replaced := replaceStorePath(data, `C:\Users\user\.docker\machine`, remoteStorePathPlaceholder)
+	if string(replaced) != expected {
+		t.Fatalf("expected %s; received %s", expected, replaced)
+	}
+}
+
+func TestStoreServerRejectsInvalidName(t *testing.T) {
+	defer cleanup()
+
+	store, err := getTestStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewStoreServer(store.(*Filestore))
+
+	req, err := http.NewRequest("GET", storeAPIPrefix+"/..", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d; received %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -1,5 +1,11 @@
 package swarm
 
+import (
+	"fmt"
+	"net"
+	"net/url"
+)
+
 const (
 	DiscoveryServiceEndpoint = "https://discovery-stage.hub.docker.com/v1"
 )
@@ -20,3 +26,32 @@ type SwarmOptions struct {
 	TlsVerify      bool
 	ArbitraryFlags []string
 }
+
+// GetMasterURL returns the URL of the swarm master running on the machine
+// whose Docker URL is machineURL.  The address of the machine replaces the
+// one the master listens on, which may be 0.0.0.0.
+func GetMasterURL(machineURL string, options SwarmOptions) (string, error) {
+	if !options.Master {
+		return "", fmt.Errorf("The machine is not a swarm master")
+	}
+
+	swarmURL, err := url.Parse(options.Host)
+	if err != nil {
+		return "", err
+	}
+	_, swarmPort, err := net.SplitHostPort(swarmURL.Host)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(machineURL)
+	if err != nil {
+		return "", err
+	}
+	machineIP, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("tcp://%s", net.JoinHostPort(machineIP, swarmPort)), nil
+}
@@ -1 +1,23 @@
 package swarm
+
+import "testing"
+
+func TestGetMasterURL(t *testing.T) {
+	options := SwarmOptions{
+		Master: true,
+		Host:   "tcp://0.0.0.0:3376",
+	}
+
+	u, err := GetMasterURL("tcp://192.168.99.100:2376", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "tcp://192.168.99.100:3376" {
+		t.Fatalf("expected tcp://192.168.99.100:3376; received %s", u)
+	}
+
+	options.Master = false
+	if _, err := GetMasterURL("tcp://192.168.99.100:2376", options); err == nil {
+		t.Fatal("expected an error for a machine which is not a swarm master")
+	}
+}
@@ -0,0 +1,144 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/drivers"
+	"github.com/docker/machine/utils"
+)
+
+// Template is the driver and the options a machine was created with,
+// captured so that more machines can be created like it.  DriverOptions
+// are the values of the create flags of the driver, by flag name.  The
+// credentials of the driver and the auth options are not captured.
+type Template struct {
+	Name          string
+	DriverName    string
+	DriverOptions map[string]interface{}
+	HostOptions   *HostOptions
+}
+
+// NewTemplate captures the template "name" from a host.
+func NewTemplate(name string, h *Host) (*Template, error) {
+	if !ValidateHostName(name) {
+		return nil, fmt.Errorf("Invalid template name %q", name)
+	}
+
+	driverOptions, err := drivers.GetCreateFlagValues(h.Driver)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the driver options of %s: %s", h.Name, err)
+	}
+
+	t := &Template{
+		Name:          name,
+		DriverName:    h.DriverName,
+		DriverOptions: driverOptions,
+	}
+
+	if h.HostOptions != nil {
+		hostOptions := *h.HostOptions
+		hostOptions.AuthOptions = nil
+		t.HostOptions = &hostOptions
+	}
+
+	return t, nil
+}
+
+// TemplateStore keeps templates in the templates directory of the store,
+// next to the certs directory.
+type TemplateStore struct {
+	path string
+}
+
+func NewTemplateStore(rootPath string) *TemplateStore {
+	return &TemplateStore{
+		path: filepath.Join(rootPath, "templates"),
+	}
+}
+
+func (s *TemplateStore) templatePath(name string) string {
+	return filepath.Join(s.path, name+".json")
+}
+
+func (s *TemplateStore) Save(t *Template) error {
+	if err := os.MkdirAll(s.path, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(t, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(s.templatePath(t.Name), data, 0600)
+}
+
+func (s *TemplateStore) Get(name string) (*Template, error) {
+	if !ValidateHostName(name) {
+		return nil, fmt.Errorf("Invalid template name %q", name)
+	}
+
+	data, err := ioutil.ReadFile(s.templatePath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrTemplateDoesNotExist{Name: name}
+		}
+		return nil, err
+	}
+
+	t := &Template{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("Error reading template %s: %s", name, err)
+	}
+	t.Name = name
+
+	return t, nil
+}
+
+// List returns the templates, sorted by name.
+func (s *TemplateStore) List() ([]*Template, error) {
+	dir, err := ioutil.ReadDir(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, file := range dir {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") && !strings.HasPrefix(file.Name(), ".") {
+			names = append(names, strings.TrimSuffix(file.Name(), ".json"))
+		}
+	}
+	sort.Strings(names)
+
+	templates := []*Template{}
+	for _, name := range names {
+		t, err := s.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, nil
+}
+
+func (s *TemplateStore) Remove(name string) error {
+	if !ValidateHostName(name) {
+		return fmt.Errorf("Invalid template name %q", name)
+	}
+
+	if err := os.Remove(s.templatePath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrTemplateDoesNotExist{Name: name}
+		}
+		return err
+	}
+
+	return nil
+}
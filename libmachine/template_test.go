@@ -0,0 +1,97 @@
+package libmachine
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNewTemplate(t *testing.T) {
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	host.HostOptions.Labels = map[string]string{"team": "web"}
+
+	tmpl, err := NewTemplate("web", host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tmpl.DriverName != "none" {
+		t.Fatalf("expected driver none; received %s", tmpl.DriverName)
+	}
+
+	expected := map[string]interface{}{"url": "unix:///var/run/docker.sock"}
+	if !reflect.DeepEqual(tmpl.DriverOptions, expected) {
+		t.Fatalf("expected driver options %v; received %v", expected, tmpl.DriverOptions)
+	}
+
+	if tmpl.HostOptions.AuthOptions != nil {
+		t.Fatal("expected the auth options to be left out")
+	}
+
+	if host.HostOptions.AuthOptions == nil {
+		t.Fatal("expected the auth options of the host to be left as is")
+	}
+
+	if tmpl.HostOptions.Labels["team"] != "web" {
+		t.Fatalf("expected the labels of the host; received %v", tmpl.HostOptions.Labels)
+	}
+}
+
+func TestNewTemplateInvalidName(t *testing.T) {
+	host, err := getDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewTemplate("../web", host); err == nil {
+		t.Fatal("expected an error for an invalid template name")
+	}
+}
+
+func TestTemplateStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-template-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewTemplateStore(dir)
+
+	if _, err := store.Get("web"); err != (ErrTemplateDoesNotExist{Name: "web"}) {
+		t.Fatalf("expected ErrTemplateDoesNotExist; received %v", err)
+	}
+
+	for _, name := range []string{"web", "db"} {
+		if err := store.Save(&Template{Name: name, DriverName: "none"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 2 || templates[0].Name != "db" || templates[1].Name != "web" {
+		t.Fatalf("expected templates db and web; received %v", templates)
+	}
+
+	if err := store.Remove("db"); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Name != "web" {
+		t.Fatalf("expected template web; received %v", templates)
+	}
+
+	if err := store.Remove("db"); err != (ErrTemplateDoesNotExist{Name: "db"}) {
+		t.Fatalf("expected ErrTemplateDoesNotExist; received %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package log
+
+import (
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the secrets in the output meant for humans.
+const Redacted = "<redacted>"
+
+// secrets shorter than this would redact too much of the output to be
+// useful, and are hardly secret anyway
+const minSecretLength = 4
+
+var (
+	secretsMu   sync.RWMutex
+	secrets     []string
+	showSecrets bool
+)
+
+// RegisterSecret makes every following log message show Redacted instead
+// of secret.
+func RegisterSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// SetShowSecrets turns the redaction of secrets off (or back on).
+func SetShowSecrets(show bool) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+
+	showSecrets = show
+}
+
+// ShowSecrets returns whether secrets should be shown to the user.
+func ShowSecrets() bool {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	return showSecrets
+}
+
+// Redact replaces the registered secrets in s.
+func Redact(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+
+	if showSecrets {
+		return s
+	}
+
+	for _, secret := range secrets {
+		s = strings.Replace(s, secret, Redacted, -1)
+	}
+	return s
+}
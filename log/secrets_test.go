@@ -0,0 +1,20 @@
+package log
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	RegisterSecret("hunter2hunter2")
+	RegisterSecret("abc")
+
+	redacted := Redact("password=hunter2hunter2 abc")
+	if redacted != "password=<redacted> abc" {
+		t.Fatalf("unexpected redacted string %q", redacted)
+	}
+
+	SetShowSecrets(true)
+	defer SetShowSecrets(false)
+
+	if shown := Redact("password=hunter2hunter2"); shown != "password=hunter2hunter2" {
+		t.Fatalf("expected the secret to be shown; received %q", shown)
+	}
+}
@@ -12,26 +12,26 @@ type TerminalLogger struct {
 }
 
 func (t TerminalLogger) log(args ...interface{}) {
-	fmt.Print(args...)
-	fmt.Print(t.fieldOut, "\n")
+	fmt.Print(Redact(fmt.Sprint(args...)))
+	fmt.Print(Redact(t.fieldOut), "\n")
 	t.fieldOut = ""
 }
 
 func (t TerminalLogger) logf(fmtString string, args ...interface{}) {
-	fmt.Printf(fmtString, args...)
-	fmt.Print(t.fieldOut, "\n")
+	fmt.Print(Redact(fmt.Sprintf(fmtString, args...)))
+	fmt.Print(Redact(t.fieldOut), "\n")
 	t.fieldOut = ""
 }
 
 func (t TerminalLogger) err(args ...interface{}) {
-	fmt.Fprint(os.Stderr, args...)
-	fmt.Fprint(os.Stderr, t.fieldOut, "\n")
+	fmt.Fprint(os.Stderr, Redact(fmt.Sprint(args...)))
+	fmt.Fprint(os.Stderr, Redact(t.fieldOut), "\n")
 	t.fieldOut = ""
 }
 
 func (t TerminalLogger) errf(fmtString string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, fmtString, args...)
-	fmt.Fprint(os.Stderr, t.fieldOut, "\n")
+	fmt.Fprint(os.Stderr, Redact(fmt.Sprintf(fmtString, args...)))
+	fmt.Fprint(os.Stderr, Redact(t.fieldOut), "\n")
 	t.fieldOut = ""
 }
 
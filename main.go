@@ -3,10 +3,12 @@ package main
 import (
 	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/codegangsta/cli"
 
 	"github.com/docker/machine/commands"
+	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/log"
 	"github.com/docker/machine/ssh"
 	"github.com/docker/machine/utils"
@@ -58,11 +60,25 @@ func main() {
 	app.Author = "Docker Machine Contributors"
 	app.Email = "https://github.com/docker/machine"
 	app.Before = func(c *cli.Context) error {
-		os.Setenv("MACHINE_STORAGE_PATH", c.GlobalString("storage-path"))
+		storagePath := c.GlobalString("storage-path")
+		if libmachine.IsRemoteStorePath(storagePath) {
+			// A remote store is mirrored into a local directory, which
+			// is used as the storage path from here on.
+			cachePath, err := libmachine.GetRemoteStoreCachePath(filepath.Join(utils.GetDockerDir(), "machine"), storagePath)
+			if err != nil {
+				return err
+			}
+			storagePath = cachePath
+		}
+		os.Setenv("MACHINE_STORAGE_PATH", storagePath)
+		log.SetShowSecrets(c.GlobalBool("show-secrets"))
 		if c.GlobalBool("native-ssh") {
 			ssh.SetDefaultClient(ssh.Native)
 		}
-		return nil
+		if err := commands.CheckRemoteCommand(c); err != nil {
+			log.Fatal(err)
+		}
+		return commands.AddPluginCreateFlags(c)
 	}
 	app.Commands = commands.Commands
 	app.CommandNotFound = cmdNotFound
@@ -80,6 +96,12 @@ func main() {
 			Value:  utils.GetBaseDir(),
 			Usage:  "Configures storage path",
 		},
+		cli.StringFlag{
+			EnvVar: "MACHINE_HOST",
+			Name:   "host",
+			Usage:  "Run the commands against the machine server at this address (see `serve`)",
+			Value:  "",
+		},
 		cli.StringFlag{
 			EnvVar: "MACHINE_TLS_CA_CERT",
 			Name:   "tls-ca-cert",
@@ -109,6 +131,16 @@ func main() {
 			Name:   "native-ssh",
 			Usage:  "Use the native (Go-based) SSH implementation.",
 		},
+		cli.DurationFlag{
+			EnvVar: "MACHINE_TIMEOUT",
+			Name:   "timeout",
+			Usage:  "Give up on operations on machines after a duration (e.g. 10m)",
+		},
+		cli.BoolFlag{
+			EnvVar: "MACHINE_SHOW_SECRETS",
+			Name:   "show-secrets",
+			Usage:  "Show credentials in inspect and debug output instead of redacting them",
+		},
 	}
 
 	app.Run(os.Args)
@@ -0,0 +1,213 @@
+// Package progress publishes the progress of the operations on machines,
+// so that it can be shown in a terminal, a user interface or a service.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/machine/log"
+)
+
+type EventType string
+
+const (
+	// PhaseStarted and PhaseFinished are published around the phases of
+	// an operation, e.g. the phases of creating a machine.
+	PhaseStarted  EventType = "phase-started"
+	PhaseFinished EventType = "phase-finished"
+
+	// Download is published as a file, e.g. an ISO, is downloaded.
+	Download EventType = "download"
+
+	// WaitingForSSH is published for each attempt to reach a machine
+	// over SSH.
+	WaitingForSSH EventType = "waiting-for-ssh"
+
+	// Message is published for the steps which are only described.
+	Message EventType = "message"
+)
+
+// downloadInterval is the minimum time between the Download events of a
+// file, except for the last one.
+const downloadInterval = 500 * time.Millisecond
+
+// Event is a step in the progress of an operation on a machine.  The
+// fields which are set depend on its type.
+type Event struct {
+	Time    time.Time
+	Machine string
+	Type    EventType
+
+	// Phase is the phase which started or finished.  Duration is how long
+	// a finished phase took, and Error why it failed.
+	Phase    string        `json:",omitempty"`
+	Duration time.Duration `json:",omitempty"`
+	Error    string        `json:",omitempty"`
+
+	// Name is the file being downloaded, of which Bytes are done.  Total
+	// is -1 if the size of the file is unknown.
+	Name  string `json:",omitempty"`
+	Bytes int64  `json:",omitempty"`
+	Total int64  `json:",omitempty"`
+
+	// Attempt counts the attempts to reach a machine over SSH, from 1.
+	Attempt int `json:",omitempty"`
+
+	Message string `json:",omitempty"`
+}
+
+// Sink receives the events of the operations.  Publish is called from the
+// goroutine doing the operation, which waits for it to return.
+type Sink interface {
+	Publish(e Event)
+}
+
+// SinkFunc is a function used as a Sink.
+type SinkFunc func(e Event)
+
+func (f SinkFunc) Publish(e Event) {
+	f(e)
+}
+
+var (
+	// Discard drops the events.
+	Discard Sink = SinkFunc(func(Event) {})
+
+	// LogSink logs the messages at the info level, and the other events at
+	// the debug level.  It is used when no sink is set.
+	LogSink Sink = SinkFunc(logEvent)
+)
+
+func logEvent(e Event) {
+	switch e.Type {
+	case Message:
+		log.Info(e.Message)
+	case PhaseStarted:
+		log.Debugf("Starting phase %s of %s", e.Phase, e.Machine)
+	case PhaseFinished:
+		if e.Error != "" {
+			log.Debugf("Phase %s of %s failed after %s: %s", e.Phase, e.Machine, e.Duration, e.Error)
+		} else {
+			log.Debugf("Phase %s of %s done in %s", e.Phase, e.Machine, e.Duration)
+		}
+	case WaitingForSSH:
+		log.Debugf("Waiting for SSH on %s, attempt %d", e.Machine, e.Attempt)
+	case Download:
+		if e.Bytes == e.Total {
+			log.Debugf("Downloaded %s (%d bytes)", e.Name, e.Bytes)
+		}
+	}
+}
+
+// Reporter publishes the progress of the operations on a machine to a
+// sink.  The zero Reporter logs it, as LogSink does.
+type Reporter struct {
+	Sink    Sink
+	Machine string
+}
+
+func NewReporter(sink Sink, machine string) Reporter {
+	return Reporter{
+		Sink:    sink,
+		Machine: machine,
+	}
+}
+
+// Publish publishes an event, setting its time and machine if they are
+// not set.
+func (r Reporter) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Machine == "" {
+		e.Machine = r.Machine
+	}
+
+	sink := r.Sink
+	if sink == nil {
+		sink = LogSink
+	}
+	sink.Publish(e)
+}
+
+func (r Reporter) Info(args ...interface{}) {
+	r.Publish(Event{Type: Message, Message: fmt.Sprint(args...)})
+}
+
+func (r Reporter) Infof(format string, args ...interface{}) {
+	r.Publish(Event{Type: Message, Message: fmt.Sprintf(format, args...)})
+}
+
+// Phase runs fn as the phase "phase", publishing when it starts and when
+// it finishes.
+func (r Reporter) Phase(phase string, fn func() error) error {
+	start := time.Now()
+	r.Publish(Event{Type: PhaseStarted, Phase: phase})
+
+	err := fn()
+
+	finished := Event{
+		Type:     PhaseFinished,
+		Phase:    phase,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		finished.Error = err.Error()
+	}
+	r.Publish(finished)
+
+	return err
+}
+
+func (r Reporter) WaitingForSSH(attempt int) {
+	r.Publish(Event{Type: WaitingForSSH, Attempt: attempt})
+}
+
+// DownloadReader returns a reader of src which publishes how much of the
+// file "name", of total bytes or -1, has been read.
+func (r Reporter) DownloadReader(name string, src io.Reader, total int64) io.Reader {
+	return &downloadReader{
+		reporter: r,
+		src:      src,
+		name:     name,
+		total:    total,
+	}
+}
+
+type downloadReader struct {
+	reporter  Reporter
+	src       io.Reader
+	name      string
+	bytes     int64
+	total     int64
+	published time.Time
+	done      bool
+}
+
+func (d *downloadReader) Read(p []byte) (int, error) {
+	n, err := d.src.Read(p)
+	d.bytes += int64(n)
+
+	if err == io.EOF && !d.done {
+		d.done = true
+		// the size was unknown, or wrong
+		d.total = d.bytes
+		d.publish()
+	} else if n > 0 && time.Since(d.published) >= downloadInterval {
+		d.publish()
+	}
+
+	return n, err
+}
+
+func (d *downloadReader) publish() {
+	d.published = time.Now()
+	d.reporter.Publish(Event{
+		Type:  Download,
+		Name:  d.name,
+		Bytes: d.bytes,
+		Total: d.total,
+	})
+}
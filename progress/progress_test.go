@@ -0,0 +1,61 @@
+package progress
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+type testSink []Event
+
+func (s *testSink) Publish(e Event) {
+	*s = append(*s, e)
+}
+
+func TestReporterPhase(t *testing.T) {
+	sink := &testSink{}
+	reporter := NewReporter(sink, "dev")
+
+	phaseErr := errors.New("phase failed")
+	if err := reporter.Phase("running", func() error { return phaseErr }); err != phaseErr {
+		t.Fatalf("expected %q; received %v", phaseErr, err)
+	}
+
+	if len(*sink) != 2 {
+		t.Fatalf("expected 2 events; received %d", len(*sink))
+	}
+
+	started, finished := (*sink)[0], (*sink)[1]
+	if started.Type != PhaseStarted || started.Phase != "running" || started.Machine != "dev" {
+		t.Fatalf("unexpected start event: %+v", started)
+	}
+	if started.Time.IsZero() {
+		t.Fatal("expected the time of the event to be set")
+	}
+	if finished.Type != PhaseFinished || finished.Error != phaseErr.Error() {
+		t.Fatalf("unexpected finish event: %+v", finished)
+	}
+}
+
+func TestReporterDownloadReader(t *testing.T) {
+	sink := &testSink{}
+	reporter := NewReporter(sink, "dev")
+
+	content := strings.Repeat("x", 1024)
+	data, err := ioutil.ReadAll(reporter.DownloadReader("boot2docker.iso", strings.NewReader(content), -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Fatal("expected the content to be read unchanged")
+	}
+
+	last := (*sink)[len(*sink)-1]
+	if last.Type != Download || last.Name != "boot2docker.iso" {
+		t.Fatalf("unexpected download event: %+v", last)
+	}
+	if last.Bytes != 1024 || last.Total != 1024 {
+		t.Fatalf("expected 1024 of 1024 bytes; received %d of %d", last.Bytes, last.Total)
+	}
+}
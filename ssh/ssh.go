@@ -5,18 +5,39 @@ import (
 	"time"
 
 	"github.com/docker/machine/log"
+	"golang.org/x/net/context"
+)
+
+const (
+	tcpDialTimeout   = 2 * time.Second
+	tcpRetryInterval = 1 * time.Second
+
+	// WaitForTCP gives up after this long
+	defaultTCPWaitTimeout = 3 * time.Minute
 )
 
 func WaitForTCP(addr string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTCPWaitTimeout)
+	defer cancel()
+
+	return WaitForTCPContext(ctx, addr)
+}
+
+// WaitForTCPContext waits until addr accepts TCP connections, or returns
+// the error of ctx once it is done.
+func WaitForTCPContext(ctx context.Context, addr string) error {
 	for {
 		log.Debugf("Testing TCP connection to: %s", addr)
-		conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
-
-		if err != nil {
-			continue
+		conn, err := net.DialTimeout("tcp", addr, tcpDialTimeout)
+		if err == nil {
+			conn.Close()
+			return nil
 		}
 
-		defer conn.Close()
-		return nil
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(tcpRetryInterval):
+		}
 	}
 }
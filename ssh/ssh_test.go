@@ -2,9 +2,13 @@ package ssh
 
 import (
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"golang.org/x/net/context"
 )
 
 func TestGenerateSSHKey(t *testing.T) {
@@ -26,3 +30,24 @@ func TestGenerateSSHKey(t *testing.T) {
 	// cleanup
 	_ = os.RemoveAll(tmpDir)
 }
+
+func TestWaitForTCPContext(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+
+	if err := WaitForTCPContext(context.Background(), addr); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := WaitForTCPContext(ctx, addr); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded; received %v", err)
+	}
+}
@@ -12,7 +12,7 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/docker/machine/log"
+	"github.com/docker/machine/progress"
 )
 
 const (
@@ -41,14 +41,21 @@ type B2dUtils struct {
 	isoFilename      string
 	commonIsoPath    string
 	imgCachePath     string
+	machineDir       string
 	githubApiBaseUrl string
 	githubBaseUrl    string
+
+	// Progress receives the progress of the downloads
+	Progress progress.Reporter
 }
 
-func NewB2dUtils(githubApiBaseUrl, githubBaseUrl string) *B2dUtils {
+// NewB2dUtils returns the helpers to fetch the boot2docker ISO of the
+// machine stored in machineDir.  The ISO is cached in the "cache" directory
+// of the store, next to the "machines" directory holding machineDir.
+func NewB2dUtils(githubApiBaseUrl, githubBaseUrl, machineDir string) *B2dUtils {
 	defaultBaseApiUrl := "https://api.github.com"
 	defaultBaseUrl := "https://github.com"
-	imgCachePath := GetMachineCacheDir()
+	imgCachePath := filepath.Join(filepath.Dir(filepath.Dir(machineDir)), "cache")
 	isoFilename := "boot2docker.iso"
 
 	if githubApiBaseUrl == "" {
@@ -61,8 +68,9 @@ func NewB2dUtils(githubApiBaseUrl, githubBaseUrl string) *B2dUtils {
 
 	return &B2dUtils{
 		isoFilename:      isoFilename,
-		imgCachePath:     GetMachineCacheDir(),
+		imgCachePath:     imgCachePath,
 		commonIsoPath:    filepath.Join(imgCachePath, isoFilename),
+		machineDir:       machineDir,
 		githubApiBaseUrl: githubApiBaseUrl,
 		githubBaseUrl:    githubBaseUrl,
 	}
@@ -97,21 +105,25 @@ func (b *B2dUtils) GetLatestBoot2DockerReleaseURL() (string, error) {
 func removeFileIfExists(name string) error {
 	if _, err := os.Stat(name); err == nil {
 		if err := os.Remove(name); err != nil {
-			log.Fatalf("Error removing temporary download file: %s", err)
+			return fmt.Errorf("Error removing temporary download file: %s", err)
 		}
 	}
 	return nil
 }
 
 // Download boot2docker ISO image for the given tag and save it at dest.
-func (b *B2dUtils) DownloadISO(dir, file, isoUrl string) error {
+func (b *B2dUtils) DownloadISO(dir, file, isoUrl string) (err error) {
 	u, err := url.Parse(isoUrl)
 	var src io.ReadCloser
+	total := int64(-1)
 	if u.Scheme == "file" || u.Scheme == "" {
 		s, err := os.Open(u.Path)
 		if err != nil {
 			return err
 		}
+		if fi, err := s.Stat(); err == nil {
+			total = fi.Size()
+		}
 		src = s
 	} else {
 		client := getClient()
@@ -119,6 +131,7 @@ func (b *B2dUtils) DownloadISO(dir, file, isoUrl string) error {
 		if err != nil {
 			return err
 		}
+		total = s.ContentLength
 		src = s.Body
 	}
 
@@ -131,13 +144,12 @@ func (b *B2dUtils) DownloadISO(dir, file, isoUrl string) error {
 	}
 
 	defer func() {
-		if err := removeFileIfExists(f.Name()); err != nil {
-			log.Fatalf("Error removing file: %s", err)
+		if removeErr := removeFileIfExists(f.Name()); removeErr != nil && err == nil {
+			err = removeErr
 		}
 	}()
 
-	if _, err := io.Copy(f, src); err != nil {
-		// TODO: display download progress?
+	if _, err := io.Copy(f, b.Progress.DownloadReader(file, src, total)); err != nil {
 		return err
 	}
 
@@ -171,7 +183,7 @@ func (b *B2dUtils) DownloadLatestBoot2Docker() error {
 }
 
 func (b *B2dUtils) DownloadISOFromURL(latestReleaseUrl string) error {
-	log.Infof("Downloading %s to %s...", latestReleaseUrl, b.commonIsoPath)
+	b.Progress.Infof("Downloading %s to %s...", latestReleaseUrl, b.commonIsoPath)
 	if err := b.DownloadISO(b.imgCachePath, b.isoFilename, latestReleaseUrl); err != nil {
 		return err
 	}
@@ -179,14 +191,15 @@ func (b *B2dUtils) DownloadISOFromURL(latestReleaseUrl string) error {
 	return nil
 }
 
-func (b *B2dUtils) CopyIsoToMachineDir(isoURL, machineName string) error {
-	machinesDir := GetMachineDir()
-	machineIsoPath := filepath.Join(machinesDir, machineName, b.isoFilename)
+// CopyIsoToMachineDir copies the cached ISO into the machine directory, or
+// downloads the ISO at isoURL into it if set.
+func (b *B2dUtils) CopyIsoToMachineDir(isoURL string) error {
+	machineIsoPath := filepath.Join(b.machineDir, b.isoFilename)
 
 	// just in case the cache dir has been manually deleted,
 	// check for it and recreate it if it's gone
 	if _, err := os.Stat(b.imgCachePath); os.IsNotExist(err) {
-		log.Infof("Image cache does not exist, creating it at %s...", b.imgCachePath)
+		b.Progress.Infof("Image cache does not exist, creating it at %s...", b.imgCachePath)
 		if err := os.Mkdir(b.imgCachePath, 0700); err != nil {
 			return err
 		}
@@ -200,8 +213,8 @@ func (b *B2dUtils) CopyIsoToMachineDir(isoURL, machineName string) error {
 		}
 	} else {
 		// But if ISO is specified go get it directly
-		log.Infof("Downloading %s from %s...", b.isoFilename, isoURL)
-		if err := b.DownloadISO(filepath.Join(machinesDir, machineName), b.isoFilename, isoURL); err != nil {
+		b.Progress.Infof("Downloading %s from %s...", b.isoFilename, isoURL)
+		if err := b.DownloadISO(b.machineDir, b.isoFilename, isoURL); err != nil {
 			return err
 		}
 	}
@@ -211,7 +224,7 @@ func (b *B2dUtils) CopyIsoToMachineDir(isoURL, machineName string) error {
 
 func (b *B2dUtils) copyDefaultIsoToMachine(machineIsoPath string) error {
 	if _, err := os.Stat(b.commonIsoPath); os.IsNotExist(err) {
-		log.Info("No default boot2docker iso found locally, downloading the latest release...")
+		b.Progress.Info("No default boot2docker iso found locally, downloading the latest release...")
 		if err := b.DownloadLatestBoot2Docker(); err != nil {
 			return err
 		}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -16,7 +17,7 @@ func TestGetLatestBoot2DockerReleaseUrl(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	b := NewB2dUtils(ts.URL, ts.URL)
+	b := NewB2dUtils(ts.URL, ts.URL, "")
 	isoUrl, err := b.GetLatestBoot2DockerReleaseURL()
 	if err != nil {
 		t.Fatal(err)
@@ -42,7 +43,7 @@ func TestDownloadIso(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	b := NewB2dUtils(ts.URL, ts.URL)
+	b := NewB2dUtils(ts.URL, ts.URL, tmpDir)
 	if err := b.DownloadISO(tmpDir, filename, ts.URL); err != nil {
 		t.Fatal(err)
 	}
@@ -56,3 +57,35 @@ func TestDownloadIso(t *testing.T) {
 		t.Fatalf("expected data \"%s\"; received \"%s\"", testData, string(data))
 	}
 }
+
+func TestCopyIsoToMachineDir(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storePath)
+
+	machineDir := filepath.Join(storePath, "machines", "dev")
+	cacheDir := filepath.Join(storePath, "cache")
+	for _, dir := range []string{machineDir, cacheDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(cacheDir, "boot2docker.iso"), []byte("iso"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewB2dUtils("", "", machineDir)
+	if err := b.CopyIsoToMachineDir(""); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(machineDir, "boot2docker.iso"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "iso" {
+		t.Fatalf("expected the cached ISO to be copied; received %q", data)
+	}
+}
@@ -31,6 +31,55 @@ func getTLSConfig(caCert, cert, key []byte, allowInsecure bool) (*tls.Config, er
 	return &tlsConfig, nil
 }
 
+func readTLSFiles(caCertPath, certPath, keyPath string) ([]byte, []byte, []byte, error) {
+	caCert, err := ioutil.ReadFile(caCertPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cert, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	key, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return caCert, cert, key, nil
+}
+
+// NewClientTLSConfig returns a TLS config which authenticates with the
+// given certificate and verifies the server against the given CA.
+func NewClientTLSConfig(caCertPath, certPath, keyPath string) (*tls.Config, error) {
+	caCert, cert, key, err := readTLSFiles(caCertPath, certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTLSConfig(caCert, cert, key, false)
+}
+
+// NewServerTLSConfig returns a TLS config for a server which only accepts
+// clients presenting a certificate signed by the given CA.
+func NewServerTLSConfig(caCertPath, certPath, keyPath string) (*tls.Config, error) {
+	caCert, cert, key, err := readTLSFiles(caCertPath, certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := getTLSConfig(caCert, cert, key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig.ClientCAs = tlsConfig.RootCAs
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+
+	return tlsConfig, nil
+}
+
 func newCertificate(org string) (*x509.Certificate, error) {
 	now := time.Now()
 	// need to set notBefore slightly in the past to account for time
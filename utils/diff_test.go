@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := strings.Split("a\nb\nc\nd", "\n")
+	b := strings.Split("a\nc\nd\ne", "\n")
+
+	expected := []string{" a", "-b", " c", " d", "+e"}
+	if diff := DiffLines(a, b); !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected %q; received %q", expected, diff)
+	}
+}
+
+func TestDiffLinesEqual(t *testing.T) {
+	a := []string{"a", "b"}
+
+	expected := []string{" a", " b"}
+	if diff := DiffLines(a, a); !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected %q; received %q", expected, diff)
+	}
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/docker/machine/log"
+)
+
+// SecretTag marks a string field which holds a credential, e.g.
+//
+//	SecretKey string `secret:"true"`
+const SecretTag = "secret"
+
+// CopySecrets returns a copy of v, which must be a pointer to a struct, in
+// which every string field tagged as secret has been replaced by the value
+// returned by fn.  Nested structs, including embedded ones and the ones
+// behind pointers, are copied and searched too, so v itself is left
+// untouched.  Empty fields are not passed to fn.
+func CopySecrets(v interface{}, fn func(string) (string, error)) (interface{}, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a pointer to a struct; received %T", v)
+	}
+
+	c, err := copySecretsStruct(value.Elem(), fn)
+	if err != nil {
+		return nil, err
+	}
+
+	p := reflect.New(c.Type())
+	p.Elem().Set(c)
+	return p.Interface(), nil
+}
+
+func copySecretsStruct(v reflect.Value, fn func(string) (string, error)) (reflect.Value, error) {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+
+	for i := 0; i < c.NumField(); i++ {
+		field := c.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			if c.Type().Field(i).Tag.Get(SecretTag) != "true" || field.String() == "" {
+				continue
+			}
+			s, err := fn(field.String())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%s: %s", c.Type().Field(i).Name, err)
+			}
+			field.SetString(s)
+		case reflect.Struct:
+			fc, err := copySecretsStruct(field, fn)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			field.Set(fc)
+		case reflect.Ptr:
+			if field.IsNil() || field.Elem().Kind() != reflect.Struct {
+				continue
+			}
+			fc, err := copySecretsStruct(field.Elem(), fn)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			p := reflect.New(fc.Type())
+			p.Elem().Set(fc)
+			field.Set(p)
+		}
+	}
+
+	return c, nil
+}
+
+// RedactSecrets returns a copy of v with the secret fields replaced by
+// log.Redacted, unless the user asked to be shown secrets.  Values other
+// than pointers to structs are returned as is.
+func RedactSecrets(v interface{}) interface{} {
+	if log.ShowSecrets() {
+		return v
+	}
+
+	redacted, err := CopySecrets(v, func(string) (string, error) {
+		return log.Redacted, nil
+	})
+	if err != nil {
+		return v
+	}
+	return redacted
+}
+
+// SecretFields returns the names of the string fields of v, which must be a
+// pointer to a struct, which are tagged as secret.  The fields of nested
+// structs are searched too.
+func SecretFields(v interface{}) []string {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return secretFields(value.Elem().Type())
+}
+
+func secretFields(t reflect.Type) []string {
+	fields := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.String:
+			if field.Tag.Get(SecretTag) == "true" {
+				fields = append(fields, field.Name)
+			}
+		case reflect.Struct:
+			fields = append(fields, secretFields(field.Type)...)
+		case reflect.Ptr:
+			if field.Type.Elem().Kind() == reflect.Struct {
+				fields = append(fields, secretFields(field.Type.Elem())...)
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type secretsTestClient struct {
+	User   string
+	APIKey string `secret:"true"`
+}
+
+type SecretsTestEmbedded struct {
+	Password string `secret:"true"`
+}
+
+type secretsTestDriver struct {
+	*SecretsTestEmbedded
+	Name   string
+	Token  string `secret:"true"`
+	Empty  string `secret:"true"`
+	Client *secretsTestClient
+}
+
+func TestCopySecrets(t *testing.T) {
+	d := &secretsTestDriver{
+		SecretsTestEmbedded: &SecretsTestEmbedded{Password: "password"},
+		Name:                "name",
+		Token:               "token",
+		Client:              &secretsTestClient{User: "user", APIKey: "apikey"},
+	}
+
+	c, err := CopySecrets(d, func(s string) (string, error) {
+		return strings.ToUpper(s), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copied, ok := c.(*secretsTestDriver)
+	if !ok {
+		t.Fatalf("expected a *secretsTestDriver; received %T", c)
+	}
+
+	if copied.Token != "TOKEN" || copied.Password != "PASSWORD" || copied.Client.APIKey != "APIKEY" {
+		t.Fatalf("expected the secrets to be replaced; received %+v %+v %+v", copied, copied.SecretsTestEmbedded, copied.Client)
+	}
+
+	if copied.Name != "name" || copied.Client.User != "user" || copied.Empty != "" {
+		t.Fatalf("expected the other fields to be copied as is; received %+v %+v", copied, copied.Client)
+	}
+
+	if d.Token != "token" || d.Password != "password" || d.Client.APIKey != "apikey" {
+		t.Fatalf("expected the original to be left untouched; received %+v %+v %+v", d, d.SecretsTestEmbedded, d.Client)
+	}
+}
+
+func TestCopySecretsNotAStruct(t *testing.T) {
+	if _, err := CopySecrets("foo", nil); err == nil {
+		t.Fatal("expected an error for a value which is not a pointer to a struct")
+	}
+}
+
+func TestSecretFields(t *testing.T) {
+	expected := []string{"Password", "Token", "Empty", "APIKey"}
+	if fields := SecretFields(&secretsTestDriver{}); !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected %v; received %v", expected, fields)
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	d := &secretsTestDriver{
+		Name:  "name",
+		Token: "token",
+	}
+
+	redacted, ok := RedactSecrets(d).(*secretsTestDriver)
+	if !ok {
+		t.Fatalf("expected a *secretsTestDriver; received %T", redacted)
+	}
+	if redacted.Token != "<redacted>" || redacted.Name != "name" {
+		t.Fatalf("expected only the token to be redacted; received %+v", redacted)
+	}
+
+	if v := RedactSecrets("foo"); v != "foo" {
+		t.Fatalf("expected values other than structs to be returned as is; received %v", v)
+	}
+}
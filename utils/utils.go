@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"os"
 	"path/filepath"
@@ -14,6 +15,14 @@ import (
 	"time"
 
 	"github.com/docker/machine/log"
+	"golang.org/x/net/context"
+)
+
+const (
+	// WaitFor polls this often, and gives up after this many attempts
+	// unless it is given a context with a deadline
+	defaultWaitAttempts = 60
+	defaultWaitInterval = 3 * time.Second
 )
 
 func GetHomeDir() string {
@@ -94,8 +103,66 @@ func CopyFile(src, dst string) error {
 	return nil
 }
 
+// WriteFileAtomic writes data to a temporary file next to filename and
+// renames it into place, so that readers never see a partially written
+// file.
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Rename(f.Name(), filename); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
 func WaitForSpecificOrError(f func() (bool, error), maxAttempts int, waitInterval time.Duration) error {
-	for i := 0; i < maxAttempts; i++ {
+	return WaitForSpecificOrErrorContext(context.Background(), f, maxAttempts, waitInterval)
+}
+
+// WaitForSpecificOrErrorContext polls f every waitInterval until it
+// returns true or an error, maxAttempts attempts have been made, or ctx
+// is done.
+func WaitForSpecificOrErrorContext(ctx context.Context, f func() (bool, error), maxAttempts int, waitInterval time.Duration) error {
+	if maxAttempts < 0 {
+		maxAttempts = 0
+	}
+	return waitForContext(ctx, f, maxAttempts, waitInterval)
+}
+
+// waitForContext is WaitForSpecificOrErrorContext, except that a negative
+// maxAttempts polls until ctx is done.
+func waitForContext(ctx context.Context, f func() (bool, error), maxAttempts int, waitInterval time.Duration) error {
+	for i := 0; maxAttempts < 0 || i < maxAttempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		stop, err := f()
 		if err != nil {
 			return err
@@ -103,7 +170,11 @@ func WaitForSpecificOrError(f func() (bool, error), maxAttempts int, waitInterva
 		if stop {
 			return nil
 		}
-		time.Sleep(waitInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitInterval):
+		}
 	}
 	return fmt.Errorf("Maximum number of retries (%d) exceeded", maxAttempts)
 }
@@ -115,11 +186,28 @@ func WaitForSpecific(f func() bool, maxAttempts int, waitInterval time.Duration)
 }
 
 func WaitFor(f func() bool) error {
-	return WaitForSpecific(f, 60, 3*time.Second)
+	return WaitForContext(context.Background(), f)
+}
+
+// WaitForContext polls f until it returns true or ctx is done.  If ctx
+// has no deadline, it gives up after the same number of attempts as
+// WaitFor.
+func WaitForContext(ctx context.Context, f func() bool) error {
+	maxAttempts := defaultWaitAttempts
+	if _, ok := ctx.Deadline(); ok {
+		maxAttempts = -1
+	}
+	return waitForContext(ctx, func() (bool, error) {
+		return f(), nil
+	}, maxAttempts, defaultWaitInterval)
 }
 
 func WaitForDocker(ip string, daemonPort int) error {
-	return WaitFor(func() bool {
+	return WaitForDockerContext(context.Background(), ip, daemonPort)
+}
+
+func WaitForDockerContext(ctx context.Context, ip string, daemonPort int) error {
+	return WaitForContext(ctx, func() bool {
 		conn, err := net.Dial("tcp", fmt.Sprintf("%s:%d", ip, daemonPort))
 		if err != nil {
 			log.Debugf("Daemon not responding yet: %s", err)
@@ -130,11 +218,14 @@ func WaitForDocker(ip string, daemonPort int) error {
 	})
 }
 
+// DumpVal logs the values as JSON at the debug level, with their secret
+// fields redacted.
 func DumpVal(vals ...interface{}) {
 	for _, val := range vals {
-		prettyJSON, err := json.MarshalIndent(val, "", "    ")
+		prettyJSON, err := json.MarshalIndent(RedactSecrets(val), "", "    ")
 		if err != nil {
-			log.Fatal(err)
+			log.Debugf("Error dumping value: %s", err)
+			continue
 		}
 		log.Debug(string(prettyJSON))
 	}
@@ -8,6 +8,9 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/net/context"
 )
 
 func TestGetBaseDir(t *testing.T) {
@@ -123,6 +126,51 @@ func TestCopyFile(t *testing.T) {
 	}
 }
 
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "config.json")
+
+	if err := ioutil.WriteFile(filename, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(filename, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "new" {
+		t.Fatalf("expected data \"new\"; received \"%s\"", string(data))
+	}
+
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0600 {
+			t.Fatalf("expected mode 0600; received %o", fi.Mode().Perm())
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected temporary files to be cleaned up; found %d files", len(files))
+	}
+}
+
 func TestGetUsername(t *testing.T) {
 	currentUser := "unknown"
 	switch runtime.GOOS {
@@ -137,3 +185,48 @@ func TestGetUsername(t *testing.T) {
 		t.Fatalf("expected username %s; received %s", currentUser, username)
 	}
 }
+
+func TestWaitForSpecificOrErrorContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	attempts := 0
+	err := WaitForSpecificOrErrorContext(ctx, func() (bool, error) {
+		attempts++
+		cancel()
+		return false, nil
+	}, 10, time.Hour)
+
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled; received %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt; received %d", attempts)
+	}
+}
+
+func TestWaitForSpecificOrErrorContextNoAttempts(t *testing.T) {
+	attempts := 0
+	err := WaitForSpecificOrErrorContext(context.Background(), func() (bool, error) {
+		attempts++
+		return true, nil
+	}, 0, time.Hour)
+
+	if err == nil {
+		t.Fatal("expected an error without any attempts")
+	}
+	if attempts != 0 {
+		t.Fatalf("expected no attempts; received %d", attempts)
+	}
+}
+
+func TestWaitForContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := WaitForContext(ctx, func() bool {
+		return false
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded; received %v", err)
+	}
+}
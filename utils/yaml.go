@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DecodeYAML decodes a YAML document into v, the way encoding/json would
+// decode the equivalent JSON document, so that the json tags of v apply.
+// Duplicate keys are an error.
+// JSON documents, being YAML as well, are decoded as is.
+func DecodeYAML(data []byte, v interface{}) error {
+	var value interface{}
+	if err := yaml.UnmarshalStrict(data, &value); err != nil {
+		return err
+	}
+
+	value, err := toJSONValue(value)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, v)
+}
+
+// toJSONValue converts the mappings decoded by the YAML package, whose keys
+// can be of any type, into the string keyed maps of encoding/json.
+func toJSONValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("Unsupported YAML key %v: keys must be strings", key)
+			}
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			converted, err := toJSONValue(item)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = converted
+		}
+		return s, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	document := `
+# a comment
+name: dev
+count: 3
+ratio: 0.5
+enabled: true
+missing: ~
+quoted: "a: b # c"
+single: 'it''s'
+list:
+- one
+- two # a comment
+flow: [a, "b, c", 3]
+map: {a: 1, b: [x, z]}
+text: |
+  first line
+  second line
+nested:
+  key: value
+  items:
+    - name: first
+      size: 1
+    - name: second
+`
+
+	var decoded map[string]interface{}
+	if err := DecodeYAML([]byte(document), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"name":    "dev",
+		"count":   float64(3),
+		"ratio":   0.5,
+		"enabled": true,
+		"missing": nil,
+		"quoted":  "a: b # c",
+		"single":  "it's",
+		"list":    []interface{}{"one", "two"},
+		"flow":    []interface{}{"a", "b, c", float64(3)},
+		"map": map[string]interface{}{
+			"a": float64(1),
+			"b": []interface{}{"x", "z"},
+		},
+		"text": "first line\nsecond line\n",
+		"nested": map[string]interface{}{
+			"key": "value",
+			"items": []interface{}{
+				map[string]interface{}{"name": "first", "size": float64(1)},
+				map[string]interface{}{"name": "second"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("expected %v; received %v", expected, decoded)
+	}
+}
+
+func TestDecodeYAMLJSON(t *testing.T) {
+	var decoded struct {
+		Name string
+	}
+	if err := DecodeYAML([]byte(`{"Name": "dev"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Name != "dev" {
+		t.Fatalf("expected dev; received %s", decoded.Name)
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	documents := []string{
+		"a: 1\n  b: 2",
+		"a: 1\na: 2",
+		"a: [1, 2",
+		"just a string\nb: 1",
+		"1: a",
+	}
+
+	for _, document := range documents {
+		var decoded interface{}
+		if err := DecodeYAML([]byte(document), &decoded); err == nil {
+			t.Fatalf("expected an error decoding %q", document)
+		}
+	}
+}